@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/yourusername/oview/internal/config"
 	"github.com/yourusername/oview/internal/embeddings"
+	"github.com/yourusername/oview/internal/search"
 )
 
 var (
@@ -45,33 +46,37 @@ type BenchmarkResults struct {
 	EmbeddingProvider  string                 `json:"embedding_provider"`
 	EmbeddingModel     string                 `json:"embedding_model"`
 	EmbeddingDimension int                    `json:"embedding_dimension"`
+	SearchMode         string                 `json:"search_mode"`
+	RerankProvider     string                 `json:"rerank_provider,omitempty"`
 	Tests              []BenchmarkTest        `json:"tests"`
 	Summary            BenchmarkSummary       `json:"summary"`
 	SystemInfo         map[string]interface{} `json:"system_info"`
 }
 
 type BenchmarkTest struct {
-	Name             string        `json:"name"`
-	Query            string        `json:"query,omitempty"`
-	Duration         time.Duration `json:"duration_ms"`
-	Success          bool          `json:"success"`
-	Error            string        `json:"error,omitempty"`
-	ResultCount      int           `json:"result_count,omitempty"`
-	TopSimilarity    float64       `json:"top_similarity,omitempty"`
-	AverageSimilarity float64      `json:"average_similarity,omitempty"`
+	Name              string             `json:"name"`
+	Query             string             `json:"query,omitempty"`
+	Duration          time.Duration      `json:"duration_ms"`
+	Success           bool               `json:"success"`
+	Error             string             `json:"error,omitempty"`
+	ResultCount       int                `json:"result_count,omitempty"`
+	TopSimilarity     float64            `json:"top_similarity,omitempty"`
+	AverageSimilarity float64            `json:"average_similarity,omitempty"`
+	Stages            map[string]float64 `json:"stages_ms,omitempty"`
 }
 
 type BenchmarkSummary struct {
-	TotalTests              int           `json:"total_tests"`
-	SuccessfulTests         int           `json:"successful_tests"`
-	FailedTests             int           `json:"failed_tests"`
-	AverageEmbeddingTime    time.Duration `json:"avg_embedding_time_ms"`
-	AverageSearchTime       time.Duration `json:"avg_search_time_ms"`
-	AverageEndToEndTime     time.Duration `json:"avg_end_to_end_time_ms"`
-	MinSearchTime           time.Duration `json:"min_search_time_ms"`
-	MaxSearchTime           time.Duration `json:"max_search_time_ms"`
-	AverageResultRelevance  float64       `json:"avg_result_relevance"`
-	ThroughputQueriesPerSec float64       `json:"throughput_queries_per_sec"`
+	TotalTests              int                `json:"total_tests"`
+	SuccessfulTests         int                `json:"successful_tests"`
+	FailedTests             int                `json:"failed_tests"`
+	AverageEmbeddingTime    time.Duration      `json:"avg_embedding_time_ms"`
+	AverageSearchTime       time.Duration      `json:"avg_search_time_ms"`
+	AverageEndToEndTime     time.Duration      `json:"avg_end_to_end_time_ms"`
+	MinSearchTime           time.Duration      `json:"min_search_time_ms"`
+	MaxSearchTime           time.Duration      `json:"max_search_time_ms"`
+	AverageResultRelevance  float64            `json:"avg_result_relevance"`
+	ThroughputQueriesPerSec float64            `json:"throughput_queries_per_sec"`
+	AverageStageTimes       map[string]float64 `json:"avg_stages_ms,omitempty"`
 }
 
 func runBenchmark(cmd *cobra.Command, args []string) error {
@@ -103,6 +108,8 @@ func runBenchmark(cmd *cobra.Command, args []string) error {
 		EmbeddingProvider:  projectConfig.Embeddings.Provider,
 		EmbeddingModel:     projectConfig.Embeddings.Model,
 		EmbeddingDimension: projectConfig.Embeddings.Dim,
+		SearchMode:         projectConfig.Search.WithDefaults().Mode,
+		RerankProvider:     projectConfig.Search.Rerank.Provider,
 		Tests:              []BenchmarkTest{},
 		SystemInfo:         make(map[string]interface{}),
 	}
@@ -112,18 +119,12 @@ func runBenchmark(cmd *cobra.Command, args []string) error {
 	results.SystemInfo["embedding_base_url"] = projectConfig.Embeddings.BaseURL
 
 	// Connect to database
-	dbName := fmt.Sprintf("oview_%s", projectConfig.ProjectSlug)
-	dbUser := fmt.Sprintf("oview_%s", projectConfig.ProjectSlug)
-	dbPassword := projectConfig.Database.Password
-
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		dbUser, dbPassword, globalConfig.PostgresHost, globalConfig.PostgresPort, dbName)
-
-	db, err := sql.Open("postgres", dsn)
+	engine, err := search.Open(projectConfig, globalConfig)
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return err
 	}
-	defer db.Close()
+	defer engine.Close()
+	db := engine.DB()
 
 	// Get total chunks
 	err = db.QueryRow("SELECT COUNT(*) FROM chunks WHERE project_id = $1", projectConfig.ProjectID).Scan(&results.TotalChunks)
@@ -137,30 +138,16 @@ func runBenchmark(cmd *cobra.Command, args []string) error {
 		projectConfig.Embeddings.Provider,
 		projectConfig.Embeddings.Model,
 		projectConfig.Embeddings.Dim)
+	fmt.Printf("🎛️  Search mode: %s\n", results.SearchMode)
+	if results.RerankProvider != "" && results.RerankProvider != search.RerankNone {
+		fmt.Printf("🏅 Re-rank: %s\n", results.RerankProvider)
+	}
 	fmt.Println()
 
 	// Initialize embeddings generator
-	var generator embeddings.Generator
-	switch projectConfig.Embeddings.Provider {
-	case "openai":
-		apiKey := os.Getenv("OPENAI_API_KEY")
-		if apiKey == "" {
-			apiKey = projectConfig.Embeddings.APIKey
-		}
-		if apiKey == "" {
-			return fmt.Errorf("OPENAI_API_KEY not set")
-		}
-		generator = embeddings.NewOpenAIGenerator(apiKey, projectConfig.Embeddings.Model)
-
-	case "ollama":
-		baseURL := projectConfig.Embeddings.BaseURL
-		if baseURL == "" {
-			baseURL = "http://localhost:11434"
-		}
-		generator = embeddings.NewOllamaGenerator(baseURL, projectConfig.Embeddings.Model)
-
-	default:
-		return fmt.Errorf("unsupported embeddings provider: %s", projectConfig.Embeddings.Provider)
+	generator, err := embeddings.NewGenerator(projectConfig.Embeddings)
+	if err != nil {
+		return err
 	}
 
 	// Test queries (diverse to test different scenarios)
@@ -196,12 +183,12 @@ func runBenchmark(cmd *cobra.Command, args []string) error {
 
 	// Test 3: Search performance
 	fmt.Println("3️⃣  Testing search performance...")
-	searchTests := benchmarkSearchPerformance(db, generator, projectConfig.ProjectID, testQueries)
+	searchTests := benchmarkSearchPerformance(engine, generator, testQueries)
 	results.Tests = append(results.Tests, searchTests...)
 
 	// Test 4: Concurrent searches
 	fmt.Println("4️⃣  Testing concurrent searches...")
-	results.Tests = append(results.Tests, benchmarkConcurrentSearches(db, generator, projectConfig.ProjectID, testQueries[0]))
+	results.Tests = append(results.Tests, benchmarkConcurrentSearches(engine, generator, testQueries[0]))
 
 	// Calculate summary
 	fmt.Println()
@@ -255,7 +242,7 @@ func benchmarkEmbeddingGeneration(generator embeddings.Generator, queries []stri
 	return tests
 }
 
-func benchmarkSearchPerformance(db *sql.DB, generator embeddings.Generator, projectID string, queries []string) []BenchmarkTest {
+func benchmarkSearchPerformance(engine *search.Engine, generator embeddings.Generator, queries []string) []BenchmarkTest {
 	tests := []BenchmarkTest{}
 
 	for i, query := range queries {
@@ -266,6 +253,7 @@ func benchmarkSearchPerformance(db *sql.DB, generator embeddings.Generator, proj
 
 		// Generate embedding
 		embedding, err := generator.Embed(query)
+		embedTime := time.Since(start)
 		if err != nil {
 			tests = append(tests, BenchmarkTest{
 				Name:     fmt.Sprintf("Search #%d (E2E)", i+1),
@@ -278,7 +266,19 @@ func benchmarkSearchPerformance(db *sql.DB, generator embeddings.Generator, proj
 		}
 
 		// Search
-		results, err := searchSimilarChunks(db, projectID, embedding, 5)
+		opts := engine.DefaultOptions()
+		opts.Query = query
+		opts.Embedding = embedding
+
+		var results []search.Result
+		stages := map[string]float64{"embed": float64(embedTime.Microseconds()) / 1000}
+		resp, err := engine.Search(opts)
+		if err == nil {
+			results = resp.Results
+			for stage, ms := range resp.TimingsMS() {
+				stages[stage] = ms
+			}
+		}
 		duration := time.Since(start)
 
 		var topSim, avgSim float64
@@ -300,13 +300,14 @@ func benchmarkSearchPerformance(db *sql.DB, generator embeddings.Generator, proj
 			ResultCount:       len(results),
 			TopSimilarity:     topSim,
 			AverageSimilarity: avgSim,
+			Stages:            stages,
 		})
 	}
 
 	return tests
 }
 
-func benchmarkConcurrentSearches(db *sql.DB, generator embeddings.Generator, projectID string, query string) BenchmarkTest {
+func benchmarkConcurrentSearches(engine *search.Engine, generator embeddings.Generator, query string) BenchmarkTest {
 	concurrent := 5
 	start := time.Now()
 
@@ -326,7 +327,10 @@ func benchmarkConcurrentSearches(db *sql.DB, generator embeddings.Generator, pro
 	done := make(chan error, concurrent)
 	for i := 0; i < concurrent; i++ {
 		go func() {
-			_, err := searchSimilarChunks(db, projectID, embedding, 5)
+			opts := engine.DefaultOptions()
+			opts.Query = query
+			opts.Embedding = embedding
+			_, err := engine.Search(opts)
 			done <- err
 		}()
 	}
@@ -356,6 +360,8 @@ func calculateSummary(tests []BenchmarkTest) BenchmarkSummary {
 	var embeddingDurations []time.Duration
 	var searchDurations []time.Duration
 	var relevanceScores []float64
+	stageTotals := make(map[string]float64)
+	stageCounts := make(map[string]int)
 
 	for _, test := range tests {
 		summary.TotalTests++
@@ -374,6 +380,10 @@ func calculateSummary(tests []BenchmarkTest) BenchmarkSummary {
 			if test.TopSimilarity > 0 {
 				relevanceScores = append(relevanceScores, test.TopSimilarity)
 			}
+			for stage, ms := range test.Stages {
+				stageTotals[stage] += ms
+				stageCounts[stage]++
+			}
 		}
 	}
 
@@ -421,6 +431,13 @@ func calculateSummary(tests []BenchmarkTest) BenchmarkSummary {
 		summary.AverageResultRelevance = sum / float64(len(relevanceScores))
 	}
 
+	if len(stageTotals) > 0 {
+		summary.AverageStageTimes = make(map[string]float64, len(stageTotals))
+		for stage, total := range stageTotals {
+			summary.AverageStageTimes[stage] = total / float64(stageCounts[stage])
+		}
+	}
+
 	return summary
 }
 
@@ -455,6 +472,16 @@ func printSummary(results *BenchmarkResults) {
 	fmt.Printf("   Throughput:          %.2f queries/sec\n", s.ThroughputQueriesPerSec)
 	fmt.Println()
 
+	if len(s.AverageStageTimes) > 0 {
+		fmt.Println("⏱️  Avg Time per Stage:")
+		for _, stage := range []string{"embed", "retrieve", "rerank", "diversify", "expand"} {
+			if ms, ok := s.AverageStageTimes[stage]; ok {
+				fmt.Printf("   %-20s %.2fms\n", stage+":", ms)
+			}
+		}
+		fmt.Println()
+	}
+
 	fmt.Println("🎯 Relevance:")
 	fmt.Printf("   Avg Top Result:      %.1f%%\n", s.AverageResultRelevance*100)
 	fmt.Println()
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/oview/internal/search"
+)
+
+var (
+	depsDepth  int
+	depsFormat string
+)
+
+var depsCmd = &cobra.Command{
+	Use:   "deps <path>",
+	Short: "Show the files a file depends on and the files that depend on it",
+	Long: `Show the collaborators of a file from the dependency graph built by 'oview index':
+PHP use statements, JS/TS import and require, Twig extends and include,
+templates rendered by controllers and Symfony service references in YAML.
+
+Classes are resolved to files with the PSR-4 rules of composer.json, relative
+and "@/" imports against the file system, templates under templates/. Vendor
+classes and npm packages are listed as external.
+
+  oview deps src/Controller/UserController.php
+  oview deps --depth 2 templates/base.html.twig`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDeps,
+}
+
+func init() {
+	depsCmd.Flags().IntVarP(&depsDepth, "depth", "d", search.DefaultRelatedDepth, fmt.Sprintf("Hops to follow in each direction (max %d)", search.MaxRelatedDepth))
+	depsCmd.Flags().StringVarP(&depsFormat, "format", "f", formatText, "Output format: text or json")
+	rootCmd.AddCommand(depsCmd)
+}
+
+func runDeps(cmd *cobra.Command, args []string) error {
+	if depsFormat != formatText && depsFormat != formatJSON {
+		return fmt.Errorf("unknown output format: %s (use text or json)", depsFormat)
+	}
+
+	engine, err := openProjectEngine()
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	path, err := projectRelativePath(args[0])
+	if err != nil {
+		return err
+	}
+	deps, err := engine.RelatedFiles(path, depsDepth)
+	if err != nil {
+		return fmt.Errorf("failed to load dependencies: %w", err)
+	}
+
+	if depsFormat == formatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(dependenciesJSON(deps))
+	}
+
+	fmt.Printf("🕸️  %s\n\n", deps.Path)
+	for _, direction := range []string{search.DependsOn, search.UsedBy} {
+		title := "Depends on"
+		if direction == search.UsedBy {
+			title = "Used by"
+		}
+		var lines []string
+		for _, r := range deps.Related {
+			if r.Direction != direction {
+				continue
+			}
+			line := fmt.Sprintf("%s%s  (%s", strings.Repeat("  ", r.Depth), r.Path, r.Kind)
+			if r.Depth > 1 {
+				line += " via " + r.Via
+			}
+			lines = append(lines, line+")")
+		}
+		fmt.Printf("%s (%d):\n", title, len(lines))
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+		if len(lines) == 0 {
+			fmt.Println("  -")
+		}
+		fmt.Println()
+	}
+
+	if len(deps.External) > 0 {
+		fmt.Printf("External (%d):\n", len(deps.External))
+		for _, x := range deps.External {
+			fmt.Printf("  %s  (%s)\n", x.Target, x.Kind)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// dependenciesJSON formats the collaborators of a file for the json output
+func dependenciesJSON(deps *search.Dependencies) map[string]interface{} {
+	related := make([]map[string]interface{}, len(deps.Related))
+	for i, r := range deps.Related {
+		related[i] = map[string]interface{}{
+			"path":      r.Path,
+			"direction": r.Direction,
+			"depth":     r.Depth,
+			"kind":      r.Kind,
+			"via":       r.Via,
+			"line":      r.Line,
+		}
+	}
+	external := make([]map[string]interface{}, len(deps.External))
+	for i, x := range deps.External {
+		external[i] = map[string]interface{}{
+			"target": x.Target,
+			"kind":   x.Kind,
+			"line":   x.Line,
+		}
+	}
+	return map[string]interface{}{
+		"path":     deps.Path,
+		"related":  related,
+		"external": external,
+	}
+}
+
+// projectRelativePath turns a path given on the command line into the form
+// stored in the index: relative to the project root, with forward slashes
+func projectRelativePath(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		projectPath, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get current directory: %w", err)
+		}
+		if path, err = filepath.Rel(projectPath, path); err != nil {
+			return "", fmt.Errorf("path is outside the project: %w", err)
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path)), nil
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/oview/internal/agents"
+	"github.com/yourusername/oview/internal/config"
+	"github.com/yourusername/oview/internal/search"
+)
+
+var entitiesFormat string
+
+var entitiesCmd = &cobra.Command{
+	Use:   "entities [name]",
+	Short: "List Doctrine entities or describe one of them",
+	Long: `List the Doctrine entities of a Symfony project with their tables, or describe
+an entity: its fields with their column types, its associations and the
+associations of other entities targeting it.
+
+Entities are extracted by 'oview index' from #[ORM\...] attributes, @ORM\...
+annotations and XML/YAML mappings. An entity is looked up by class
+(App\Entity\User), short name (user) or table name. 'oview index' also writes
+the model to .oview/index/schema.md and grounds the DBA agent in it.
+
+  oview entities
+  oview entities User
+  oview entities -f json user_account`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runEntities,
+}
+
+func init() {
+	entitiesCmd.Flags().StringVarP(&entitiesFormat, "format", "f", formatText, "Output format: text or json")
+	rootCmd.AddCommand(entitiesCmd)
+}
+
+func runEntities(cmd *cobra.Command, args []string) error {
+	if entitiesFormat != formatText && entitiesFormat != formatJSON {
+		return fmt.Errorf("unknown output format: %s (use text or json)", entitiesFormat)
+	}
+
+	engine, err := openProjectEngine()
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	var entities []search.Entity
+	if len(args) == 0 {
+		entities, err = engine.Entities()
+	} else {
+		entities, err = engine.DescribeEntity(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load entities: %w", err)
+	}
+
+	if entitiesFormat == formatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{
+			"count":    len(entities),
+			"entities": entitiesJSON(entities),
+		})
+	}
+
+	if len(entities) == 0 {
+		if len(args) == 0 {
+			fmt.Println("❌ No entities indexed")
+			fmt.Println("Hint: entities are extracted by 'oview index' when the project is detected as Symfony (stack.symfony in .oview/project.yaml)")
+		} else {
+			fmt.Printf("❌ No entity matches %q\n", args[0])
+		}
+		return nil
+	}
+
+	if len(args) == 0 {
+		printEntityTable(entities)
+		return nil
+	}
+
+	for _, en := range entities {
+		fmt.Printf("🗃️  %s (table %s)\n", en.Class, en.Table)
+		fmt.Printf("   📁 %s:%d (%s)\n", en.File, en.Line, en.Source)
+		if en.Repository != "" {
+			fmt.Printf("   Repository: %s\n", en.Repository)
+		}
+		fmt.Println()
+		for _, f := range en.Fields {
+			fmt.Printf("   %5d  %s\n", f.Line, f.Describe())
+		}
+		if len(en.ReferencedBy) > 0 {
+			fmt.Println()
+			fmt.Println("   Referenced by:")
+			for _, ref := range en.ReferencedBy {
+				fmt.Printf("     - %s.%s (%s)\n", ref.Entity, ref.Field, ref.Relation)
+			}
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// printEntityTable prints entities one per line: name, table, field count and class
+func printEntityTable(entities []search.Entity) {
+	nameWidth, tableWidth := len("Entity"), len("Table")
+	for _, en := range entities {
+		nameWidth = max(nameWidth, len(en.Name))
+		tableWidth = max(tableWidth, len(en.Table))
+	}
+	fmt.Printf("🗃️  %d entities:\n\n", len(entities))
+	fmt.Printf("%-*s %-*s %6s  %s\n", nameWidth, "Entity", tableWidth, "Table", "Fields", "Class")
+	for _, en := range entities {
+		fmt.Printf("%-*s %-*s %6d  %s\n", nameWidth, en.Name, tableWidth, en.Table, len(en.Fields), en.Class)
+	}
+}
+
+// entitiesJSON formats entities for the json output
+func entitiesJSON(entities []search.Entity) []map[string]interface{} {
+	out := make([]map[string]interface{}, len(entities))
+	for i, en := range entities {
+		fields := make([]map[string]interface{}, len(en.Fields))
+		for j, f := range en.Fields {
+			fields[j] = map[string]interface{}{
+				"name":     f.Name,
+				"column":   f.Column,
+				"type":     f.Type,
+				"nullable": f.Nullable,
+				"id":       f.ID,
+				"line":     f.Line,
+			}
+			if f.Length > 0 {
+				fields[j]["length"] = f.Length
+			}
+			if f.Relation != "" {
+				fields[j]["relation"] = f.Relation
+				fields[j]["target_entity"] = f.Target
+				fields[j]["mapped_by"] = f.MappedBy
+				fields[j]["inversed_by"] = f.InversedBy
+			}
+		}
+		refs := make([]map[string]interface{}, len(en.ReferencedBy))
+		for j, ref := range en.ReferencedBy {
+			refs[j] = map[string]interface{}{
+				"entity":   ref.Entity,
+				"field":    ref.Field,
+				"relation": ref.Relation,
+			}
+		}
+		out[i] = map[string]interface{}{
+			"name":          en.Name,
+			"class":         en.Class,
+			"table":         en.Table,
+			"repository":    en.Repository,
+			"file":          en.File,
+			"line":          en.Line,
+			"source":        en.Source,
+			"fields":        fields,
+			"referenced_by": refs,
+		}
+	}
+	return out
+}
+
+// saveSchema writes the entity model to .oview/index/schema.md and refreshes the
+// schema section of the DBA agent from it. Returns the number of entities and
+// whether the agent was updated.
+func saveSchema(projectPath string, db *sql.DB, projectConfig *config.ProjectConfig) (int, bool, error) {
+	entities, err := search.NewEngine(db, projectConfig).Entities()
+	if err != nil {
+		return 0, false, err
+	}
+	schemaPath := filepath.Join(projectPath, ".oview", "index", "schema.md")
+	if err := os.WriteFile(schemaPath, []byte(search.SchemaMarkdown(entities)), 0644); err != nil {
+		return 0, false, fmt.Errorf("failed to write %s: %w", schemaPath, err)
+	}
+	updated, err := agents.New(projectPath, &projectConfig.Stack).UpdateDBA()
+	if err != nil {
+		return 0, false, err
+	}
+	return len(entities), updated, nil
+}
@@ -23,7 +23,10 @@ var indexCmd = &cobra.Command{
 - Applies chunking rules from .oview/rag.yaml
 - Generates embeddings (using stub for MVP)
 - Stores chunks in the project database
-- Updates .oview/index/stats.json and manifest.json`,
+- Extracts Symfony routes (controllers and config/routes*)
+- Extracts Doctrine entities into .oview/index/schema.md for the DBA agent
+- Updates .oview/index/stats.json and manifest.json
+- Regenerates the repository map in .oview/index/repomap.md`,
 	RunE: runIndex,
 }
 
@@ -69,15 +72,7 @@ func runIndex(cmd *cobra.Command, args []string) error {
 
 	// Connect to project database
 	fmt.Println("🔗 Connecting to project database...")
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		projectConfig.Database.User,
-		projectConfig.Database.Password,
-		globalConfig.PostgresHost,
-		globalConfig.PostgresPort,
-		projectConfig.Database.Name,
-	)
-
-	dbClient, err := database.NewClient(dsn)
+	dbClient, err := database.NewClient(globalConfig.GetProjectDSN(projectConfig))
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -93,39 +88,25 @@ func runIndex(cmd *cobra.Command, args []string) error {
 
 	// Create embeddings generator based on project config
 	embConfig := projectConfig.Embeddings
-	var embedder embeddings.Generator
 
 	fmt.Printf("📊 Embeddings config: provider=%s, model=%s, dim=%d\n",
 		embConfig.Provider, embConfig.Model, embConfig.Dim)
 
+	embedder, err := embeddings.NewGenerator(embConfig)
+	if err != nil {
+		return err
+	}
+
 	switch embConfig.Provider {
 	case "openai":
-		// Get API key from config or environment
-		apiKey := embConfig.APIKey
-		if apiKey == "" {
-			apiKey = os.Getenv("OPENAI_API_KEY")
-		}
-		if apiKey == "" {
-			return fmt.Errorf("OpenAI API key required. Set in .oview/project.yaml or OPENAI_API_KEY environment variable")
-		}
-		embedder = embeddings.NewOpenAIGenerator(apiKey, embConfig.Model)
 		fmt.Printf("🤖 Using OpenAI embeddings: %s\n", embedder.Name())
 
 	case "ollama":
-		baseURL := embConfig.BaseURL
-		if baseURL == "" {
-			baseURL = "http://localhost:11434"
-		}
-		embedder = embeddings.NewOllamaGenerator(baseURL, embConfig.Model)
 		fmt.Printf("🤖 Using Ollama embeddings: %s\n", embedder.Name())
 		fmt.Printf("   ⚠️  Make sure: ollama serve && ollama pull %s\n", embConfig.Model)
 
 	case "stub":
-		embedder = embeddings.NewStubGenerator(embConfig.Dim)
 		fmt.Println("⚠️  Using stub embeddings (no semantic meaning)")
-
-	default:
-		return fmt.Errorf("unknown embeddings provider: %s (edit .oview/project.yaml)", embConfig.Provider)
 	}
 
 	// Verify dimensions match
@@ -140,6 +121,7 @@ func runIndex(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 
 	idx := indexer.New(projectPath, projectConfig.ProjectID, db, ragConfig, embedder, embConfig.Model)
+	idx.SetStack(projectConfig.Stack)
 
 	// Run indexing
 	stats, err := idx.Index()
@@ -154,11 +136,42 @@ func runIndex(cmd *cobra.Command, args []string) error {
 	fmt.Println("Summary:")
 	fmt.Printf("  Files indexed:  %d\n", stats.FilesIndexed)
 	fmt.Printf("  Chunks stored:  %d\n", stats.ChunksStored)
+	fmt.Printf("  Dependencies:   %d\n", stats.EdgesStored)
+	if projectConfig.Stack.Symfony {
+		fmt.Printf("  Routes:         %d\n", stats.RoutesStored)
+		fmt.Printf("  Entities:       %d\n", stats.EntitiesStored)
+	}
+	fmt.Printf("  Files skipped:  %d\n", stats.FilesSkipped)
 	fmt.Printf("  Total size:     %d bytes\n", stats.TotalBytes)
 	fmt.Printf("  Duration:       %s\n", stats.Duration)
 	if stats.CommitSHA != "" {
 		fmt.Printf("  Git commit:     %s\n", stats.CommitSHA)
 	}
+	if len(stats.Skipped) > 0 {
+		fmt.Println()
+		fmt.Println("Skipped files:")
+		for _, s := range stats.Skipped {
+			fmt.Printf("  - %s (%s)\n", s.Path, s.Reason)
+		}
+	}
+
+	// Regenerate the repository map
+	if m, err := saveRepoMap(projectPath, db, projectConfig); err != nil {
+		fmt.Println()
+		fmt.Printf("⚠️  Warning: failed to generate repository map: %v\n", err)
+	} else {
+		fmt.Println()
+		fmt.Printf("🗺️  Repository map: .oview/index/repomap.md (%d of %d files, ~%d tokens)\n", len(m.Files), m.TotalFiles, m.UsedTokens)
+	}
+	if projectConfig.Stack.Symfony {
+		if n, updated, err := saveSchema(projectPath, db, projectConfig); err != nil {
+			fmt.Printf("⚠️  Warning: failed to generate entity schema: %v\n", err)
+		} else if updated {
+			fmt.Printf("🗃️  Entity schema: .oview/index/schema.md (%d entities, DBA agent updated)\n", n)
+		} else {
+			fmt.Printf("🗃️  Entity schema: .oview/index/schema.md (%d entities)\n", n)
+		}
+	}
 	fmt.Println()
 	fmt.Println("✅ Indexed data is now available for RAG queries!")
 	fmt.Println()
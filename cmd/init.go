@@ -177,6 +177,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 		},
 		Embeddings: embeddingsConfig,
 		LLM:        llmConfig,
+		Search:     config.DefaultSearchConfig(),
 	}
 
 	// Check if embeddings model changed
@@ -219,7 +220,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	// Create RAG config
 	fmt.Println("📋 Creating RAG configuration...")
-	ragConfig := config.DefaultRAGConfig()
+	ragConfig := config.DefaultRAGConfigForStack(stack)
 	if err := config.SaveRAGConfig(projectPath, ragConfig); err != nil {
 		return fmt.Errorf("failed to save RAG config: %w", err)
 	}
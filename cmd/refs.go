@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/oview/internal/search"
+)
+
+var (
+	refsOptions search.ReferenceOptions
+	refsFormat  string
+)
+
+var refsCmd = &cobra.Command{
+	Use:   "refs <symbol>",
+	Short: "Find where a function, class or service is used",
+	Long: `Find the lines that use an identifier, with surrounding lines.
+
+References come from the identifier index built by 'oview index': every
+identifier of code, test and config chunks is recorded with its line, so the
+lookup is exact and does not depend on embeddings. Dotted names such as
+Symfony service ids (app.mailer) are indexed whole in config files and quoted
+strings. For a qualified name (UserController::login) the member is looked up.
+
+  oview refs sendResetEmail
+  oview refs app.mailer
+  oview refs --path src/ -C 4 UserRepository
+  vim -q <(oview refs -f vimgrep findOneByEmail)`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRefs,
+}
+
+func init() {
+	refsCmd.Flags().IntVarP(&refsOptions.Limit, "limit", "n", search.DefaultReferenceLimit, "Number of references to return")
+	refsCmd.Flags().IntVarP(&refsOptions.Context, "context", "C", search.DefaultReferenceContext, "Lines shown on each side of a reference")
+	refsCmd.Flags().BoolVarP(&refsOptions.IgnoreCase, "ignore-case", "I", false, "Match the identifier case-insensitively")
+	refsCmd.Flags().BoolVar(&refsOptions.Definitions, "definitions", false, "Also show the lines declaring the identifier")
+	refsCmd.Flags().StringSliceVarP(&refsOptions.Filters.Types, "type", "t", nil, "Only chunks of these types: code, test, config")
+	refsCmd.Flags().StringSliceVar(&refsOptions.Filters.Languages, "lang", nil, "Only chunks in these languages (e.g. php, typescript)")
+	refsCmd.Flags().StringSliceVarP(&refsOptions.Filters.Paths, "path", "p", nil, "Only paths with this prefix or matching this glob")
+	refsCmd.Flags().StringSliceVarP(&refsOptions.Filters.Exclude, "exclude", "x", nil, "Exclude paths with this prefix or matching this glob")
+	refsCmd.Flags().StringVarP(&refsFormat, "format", "f", formatText, "Output format: text, json or vimgrep")
+	rootCmd.AddCommand(refsCmd)
+}
+
+func runRefs(cmd *cobra.Command, args []string) error {
+	if refsFormat != formatText && refsFormat != formatJSON && refsFormat != formatVimgrep {
+		return fmt.Errorf("unknown output format: %s (use text, json or vimgrep)", refsFormat)
+	}
+
+	engine, err := openProjectEngine()
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	name := search.ReferenceName(args[0])
+	refs, total, err := engine.FindReferences(args[0], refsOptions)
+	if err != nil {
+		return fmt.Errorf("failed to find references: %w", err)
+	}
+
+	switch refsFormat {
+	case formatJSON:
+		out := make([]map[string]interface{}, len(refs))
+		for i, r := range refs {
+			out[i] = map[string]interface{}{
+				"path":     r.Path,
+				"line":     r.Line,
+				"symbol":   r.Symbol,
+				"kind":     r.Kind,
+				"language": r.Language,
+				"text":     r.Text,
+				"context":  r.Context,
+			}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{
+			"identifier": name,
+			"total":      total,
+			"count":      len(refs),
+			"references": out,
+		})
+	case formatVimgrep:
+		for _, r := range refs {
+			line, col := r.Line, strings.Index(r.Text, name)+1
+			if line == 0 {
+				line = 1
+			}
+			if col == 0 {
+				col = 1
+			}
+			fmt.Printf("%s:%d:%d:%s\n", r.Path, line, col, r.Text)
+		}
+		return nil
+	}
+
+	if len(refs) == 0 {
+		fmt.Printf("❌ No references to %q\n", name)
+		fmt.Println("Hint: references are recorded by 'oview index'; re-run it after upgrading")
+		return nil
+	}
+
+	fmt.Printf("🔗 %d references to %q", total, name)
+	if total > len(refs) {
+		fmt.Printf(" (showing %d, use --limit for more)", len(refs))
+	}
+	fmt.Print(":\n\n")
+
+	for _, r := range refs {
+		location := r.Path
+		if r.Line > 0 {
+			location = fmt.Sprintf("%s:%d", r.Path, r.Line)
+		}
+		header := "📁 " + location
+		if r.Symbol != "" {
+			header += "  (in " + r.Symbol + ")"
+		}
+		if r.Kind == "definition" {
+			header += "  [definition]"
+		}
+		fmt.Println(header)
+		if r.Context != "" {
+			fmt.Println(r.Context)
+		} else {
+			fmt.Printf("      %s\n", r.Text)
+		}
+		fmt.Println()
+	}
+	return nil
+}
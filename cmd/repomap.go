@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/oview/internal/config"
+	"github.com/yourusername/oview/internal/search"
+)
+
+var (
+	repoMapOptions search.RepoMapOptions
+	repoMapFormat  string
+)
+
+var repomapCmd = &cobra.Command{
+	Use:   "repomap",
+	Short: "Show a ranked, token-bounded overview of the repository",
+	Long: `Show the most important files of the repository with their key symbols and
+signatures, within a token budget.
+
+Files are ranked by PageRank over the dependency graph (use, import, extends,
+include, render, service references) and the identifier index (a file using a
+class or function defined in another file), so the files everything else
+relies on come first. Documentation is left out unless --type is given.
+
+'oview index' regenerates the map with the default budget in
+.oview/index/repomap.md.
+
+  oview repomap
+  oview repomap --tokens 4000 --path src/
+  oview repomap -f json | jq '.files[].path'`,
+	Args: cobra.NoArgs,
+	RunE: runRepoMap,
+}
+
+func init() {
+	repomapCmd.Flags().IntVar(&repoMapOptions.MaxTokens, "tokens", search.DefaultRepoMapTokens, "Token budget of the map")
+	repomapCmd.Flags().IntVar(&repoMapOptions.MaxSymbols, "symbols", search.DefaultRepoMapSymbols, "Symbols shown per file")
+	repomapCmd.Flags().StringSliceVarP(&repoMapOptions.Filters.Types, "type", "t", nil, "Only files of these types: code, test, config, doc")
+	repomapCmd.Flags().StringSliceVar(&repoMapOptions.Filters.Languages, "lang", nil, "Only files in these languages (e.g. php, typescript)")
+	repomapCmd.Flags().StringSliceVarP(&repoMapOptions.Filters.Paths, "path", "p", nil, "Only paths with this prefix or matching this glob")
+	repomapCmd.Flags().StringSliceVarP(&repoMapOptions.Filters.Exclude, "exclude", "x", nil, "Exclude paths with this prefix or matching this glob")
+	repomapCmd.Flags().StringVarP(&repoMapFormat, "format", "f", formatText, "Output format: text or json")
+	rootCmd.AddCommand(repomapCmd)
+}
+
+func runRepoMap(cmd *cobra.Command, args []string) error {
+	if repoMapFormat != formatText && repoMapFormat != formatJSON {
+		return fmt.Errorf("unknown output format: %s (use text or json)", repoMapFormat)
+	}
+
+	engine, err := openProjectEngine()
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	m, err := engine.RepoMap(repoMapOptions)
+	if err != nil {
+		return fmt.Errorf("failed to build repository map: %w", err)
+	}
+
+	if repoMapFormat == formatJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(repoMapJSON(m))
+	}
+
+	if m.TotalFiles == 0 {
+		fmt.Println("❌ No indexed files in scope")
+		fmt.Println("Hint: Run 'oview index' first")
+		return nil
+	}
+	fmt.Print(m.Text)
+	return nil
+}
+
+// repoMapJSON formats a repository map for the json output
+func repoMapJSON(m *search.RepoMap) map[string]interface{} {
+	files := make([]map[string]interface{}, len(m.Files))
+	for i, f := range m.Files {
+		symbols := make([]map[string]interface{}, len(f.Symbols))
+		for j, s := range f.Symbols {
+			symbols[j] = map[string]interface{}{
+				"name":      s.Name,
+				"signature": s.Signature,
+				"line":      s.Line,
+			}
+		}
+		files[i] = map[string]interface{}{
+			"path":         f.Path,
+			"language":     f.Language,
+			"rank":         f.Rank,
+			"used_by":      f.UsedBy,
+			"depends_on":   f.DependsOn,
+			"symbols":      symbols,
+			"more_symbols": f.More,
+		}
+	}
+	return map[string]interface{}{
+		"total_files": m.TotalFiles,
+		"max_tokens":  m.MaxTokens,
+		"used_tokens": m.UsedTokens,
+		"files":       files,
+		"text":        m.Text,
+	}
+}
+
+// saveRepoMap regenerates .oview/index/repomap.md with the default budget
+func saveRepoMap(projectPath string, db *sql.DB, projectConfig *config.ProjectConfig) (*search.RepoMap, error) {
+	m, err := search.NewEngine(db, projectConfig).RepoMap(search.RepoMapOptions{})
+	if err != nil {
+		return nil, err
+	}
+	mapPath := filepath.Join(projectPath, ".oview", "index", "repomap.md")
+	if err := os.WriteFile(mapPath, []byte(m.Text), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", mapPath, err)
+	}
+	return m, nil
+}
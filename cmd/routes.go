@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/oview/internal/search"
+)
+
+var (
+	routesOptions search.RouteOptions
+	routesFormat  string
+	routesCode    bool
+)
+
+var routesCmd = &cobra.Command{
+	Use:   "routes [url-or-name]",
+	Short: "List Symfony routes or find the controller handling a URL",
+	Long: `List the routes of a Symfony project, or find the routes matching a URL,
+a route name or a controller, with the controller action handling them.
+
+Routes are extracted by 'oview index' from #[Route] attributes and @Route
+annotations of controllers (with their class prefix) and from
+config/routes*.yaml and XML files. URLs are matched against route paths with
+their placeholders and inline requirements, so /api/users/42 finds
+/api/users/{id<\d+>}.
+
+  oview routes
+  oview routes /api/users/42
+  oview routes "POST /api/users"
+  oview routes --code api_users_show`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRoutes,
+}
+
+func init() {
+	routesCmd.Flags().StringVarP(&routesOptions.Method, "method", "m", "", "Only routes accepting this HTTP method")
+	routesCmd.Flags().IntVarP(&routesOptions.Limit, "limit", "n", search.DefaultRouteLimit, "Number of routes to return when looking up")
+	routesCmd.Flags().BoolVar(&routesCode, "code", false, "Show the code of the controller action")
+	routesCmd.Flags().StringVarP(&routesFormat, "format", "f", formatText, "Output format: text, json or vimgrep")
+	rootCmd.AddCommand(routesCmd)
+}
+
+func runRoutes(cmd *cobra.Command, args []string) error {
+	if routesFormat != formatText && routesFormat != formatJSON && routesFormat != formatVimgrep {
+		return fmt.Errorf("unknown output format: %s (use text, json or vimgrep)", routesFormat)
+	}
+
+	engine, err := openProjectEngine()
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	var routes []search.Route
+	if len(args) == 0 {
+		routes, err = engine.Routes(routesOptions.Method)
+	} else {
+		routes, err = engine.FindRoute(args[0], routesOptions)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load routes: %w", err)
+	}
+
+	switch routesFormat {
+	case formatJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{
+			"count":  len(routes),
+			"routes": routesJSON(routes, routesCode),
+		})
+	case formatVimgrep:
+		for _, r := range routes {
+			path, line := r.File, r.Line
+			if r.Handler != nil && r.Handler.StartLine > 0 {
+				path, line = r.Handler.Path, r.Handler.StartLine
+			}
+			if line == 0 {
+				line = 1
+			}
+			fmt.Printf("%s:%d:1:%s %s %s\n", path, line, routeMethods(r), r.Path, r.Name)
+		}
+		return nil
+	}
+
+	if len(routes) == 0 {
+		if len(args) == 0 {
+			fmt.Println("❌ No routes indexed")
+			fmt.Println("Hint: routes are extracted by 'oview index' when the project is detected as Symfony (stack.symfony in .oview/project.yaml)")
+		} else {
+			fmt.Printf("❌ No route matches %q\n", args[0])
+		}
+		return nil
+	}
+
+	if len(args) == 0 {
+		printRouteTable(routes)
+		return nil
+	}
+
+	fmt.Printf("🧭 %d routes matching %q:\n\n", len(routes), args[0])
+	for _, r := range routes {
+		fmt.Printf("%-7s %s", routeMethods(r), r.Path)
+		if r.Name != "" {
+			fmt.Printf("  (%s)", r.Name)
+		}
+		fmt.Printf("  [%s match]\n", r.Match)
+		if len(r.Params) > 0 {
+			keys := make([]string, 0, len(r.Params))
+			for k := range r.Params {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			params := make([]string, len(keys))
+			for i, k := range keys {
+				params[i] = k + "=" + r.Params[k]
+			}
+			fmt.Printf("        params: %s\n", strings.Join(params, ", "))
+		}
+		fmt.Printf("        controller: %s\n", r.Controller)
+		fmt.Printf("        declared in: %s:%d (%s)\n", r.File, r.Line, r.Source)
+		if h := r.Handler; h != nil {
+			fmt.Printf("        📁 %s:%d-%d  %s\n", h.Path, h.StartLine, h.EndLine, h.Signature)
+			if routesCode {
+				fmt.Println()
+				for i, line := range strings.Split(h.Content, "\n") {
+					fmt.Printf("%5d | %s\n", h.StartLine+i, line)
+				}
+			}
+		} else {
+			fmt.Println("        (controller not indexed)")
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// printRouteTable prints routes one per line: methods, path, name and controller
+func printRouteTable(routes []search.Route) {
+	pathWidth, nameWidth := len("Path"), len("Name")
+	for _, r := range routes {
+		pathWidth = max(pathWidth, len(r.Path))
+		nameWidth = max(nameWidth, len(r.Name))
+	}
+	fmt.Printf("🧭 %d routes:\n\n", len(routes))
+	fmt.Printf("%-10s %-*s %-*s %s\n", "Method", pathWidth, "Path", nameWidth, "Name", "Controller")
+	for _, r := range routes {
+		fmt.Printf("%-10s %-*s %-*s %s\n", routeMethods(r), pathWidth, r.Path, nameWidth, r.Name, r.Controller)
+	}
+}
+
+// routeMethods formats the methods of a route, ANY when it accepts all of them
+func routeMethods(r search.Route) string {
+	if len(r.Methods) == 0 {
+		return "ANY"
+	}
+	return strings.Join(r.Methods, "|")
+}
+
+// routesJSON formats routes for the json output
+func routesJSON(routes []search.Route, withCode bool) []map[string]interface{} {
+	out := make([]map[string]interface{}, len(routes))
+	for i, r := range routes {
+		methods := r.Methods
+		if methods == nil {
+			methods = []string{}
+		}
+		out[i] = map[string]interface{}{
+			"name":            r.Name,
+			"path":            r.Path,
+			"methods":         methods,
+			"controller":      r.Controller,
+			"controller_path": r.ControllerPath,
+			"file":            r.File,
+			"line":            r.Line,
+			"source":          r.Source,
+		}
+		if r.Match != "" {
+			out[i]["match"] = r.Match
+		}
+		if len(r.Params) > 0 {
+			out[i]["params"] = r.Params
+		}
+		if h := r.Handler; h != nil {
+			handler := map[string]interface{}{
+				"symbol":     h.Symbol,
+				"path":       h.Path,
+				"start_line": h.StartLine,
+				"end_line":   h.EndLine,
+				"signature":  h.Signature,
+			}
+			if withCode {
+				handler["content"] = h.Content
+			}
+			out[i]["handler"] = handler
+		}
+	}
+	return out
+}
@@ -1,18 +1,35 @@
 package cmd
 
 import (
-	"database/sql"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/yourusername/oview/internal/config"
-	"github.com/yourusername/oview/internal/embeddings"
+	"github.com/yourusername/oview/internal/indexer"
+	"github.com/yourusername/oview/internal/search"
 )
 
 var (
-	searchLimit int
+	searchLimit         int
+	searchMode          string
+	searchFilters       search.Filters
+	searchMMR           bool
+	searchMMRLambda     float64
+	searchMaxPerFile    int
+	searchMinSimilarity float64
+	searchExpand        int
+	searchBudget        int
+	searchRerank        string
+	searchRerankTop     int
+	searchExplain       bool
+	searchFormat        string
+	searchInteractive   bool
+	searchAllProjects   bool
+	searchProjects      []string
+	searchIncompatible  string
 )
 
 var searchCmd = &cobra.Command{
@@ -20,13 +37,92 @@ var searchCmd = &cobra.Command{
 	Short: "Search the codebase using semantic similarity",
 	Long: `Search your indexed codebase using semantic similarity.
 The query is embedded using the same model configured in project.yaml,
-then similar code chunks are retrieved using cosine similarity.`,
-	Args: cobra.MinimumNArgs(1),
+then similar code chunks are retrieved using cosine similarity.
+
+Modes:
+  vector   cosine similarity of embeddings only
+  lexical  Postgres full-text and trigram matching (exact identifiers, error codes)
+  hybrid   both rankings fused with reciprocal rank fusion
+
+The default mode and fusion weights come from the search section of project.yaml.
+
+Filters are applied in SQL before ranking and can be repeated or comma-separated:
+  oview search --type code --lang php --path src/Controller "login"
+  oview search --path 'src/**/*Repository.php' --exclude src/Legacy "find by email"
+
+The same filters can be written inline as qualifiers. A leading "-" excludes,
+and quoted phrases must appear verbatim in the content:
+  oview search 'lang:php path:src/Controller type:-test symbol:login "password reset"'
+
+Qualifiers: type:, lang:, path:, component:, source:, symbol:, exclude:
+
+Diversity: --mmr re-ranks candidates with maximal marginal relevance so
+near-duplicate chunks don't crowd the results, --max-per-file caps hits from
+one file and --min-similarity drops weak vector matches. Defaults come from
+project.yaml.
+
+Expansion: --expand N shows each hit with N neighbouring chunks of the same
+file (or the whole symbol when the hit is a piece of a split one), merged into
+one excerpt with line numbers, within --budget tokens overall.
+
+Re-ranking: --rerank re-scores the top hits against the query, either with the
+built-in lexical scorer (offline), the LLM configured in project.yaml or an
+OpenAI-compatible endpoint (search.rerank.base_url). The time spent in each
+stage is shown after the results.
+
+Explain: --explain shows, for each result, its vector distance and rank, its
+lexical components, the score contributions, the filters it matched and when
+(and at which commit) it was indexed, then the candidates that were cut and why.
+
+Output: --format json|jsonl|vimgrep|markdown prints only the results, with full
+content, for scripts and editors:
+  oview search --format vimgrep "login" | fzf
+  oview search --format jsonl "login" | jq -r .path
+
+Interactive: -i starts a session that keeps the database connection and the
+embedder open between queries, with history, filter toggles, full previews,
+:open in $EDITOR and :copy as context. Type :help once inside.
+
+Cross-project: --all-projects searches every project set up with 'oview up'
+(or --projects for some of them) and merges the results, labelled with their
+project, on a score scale shared by all projects. Projects embedded with
+another model than the current one get the query embedded again with their
+model (--incompatible embed), are searched lexically (lexical) or left out
+(skip):
+  oview search --all-projects "retry failed webhooks"
+  oview search --projects billing,shop --incompatible lexical "invoice number"`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if searchInteractive {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	RunE: runSearch,
 }
 
 func init() {
-	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 5, "Number of results to return")
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", search.DefaultLimit, fmt.Sprintf("Number of results to return (max %d)", search.MaxLimit))
+	searchCmd.Flags().StringVarP(&searchMode, "mode", "m", "", "Search mode: vector, lexical or hybrid (default from project.yaml)")
+	searchCmd.Flags().StringSliceVarP(&searchFilters.Types, "type", "t", nil, "Only chunks of these types: code, test, config, doc")
+	searchCmd.Flags().StringSliceVar(&searchFilters.Languages, "lang", nil, "Only chunks in these languages (e.g. php, typescript)")
+	searchCmd.Flags().StringSliceVarP(&searchFilters.Paths, "path", "p", nil, "Only paths with this prefix or matching this glob")
+	searchCmd.Flags().StringSliceVar(&searchFilters.Components, "component", nil, "Only chunks of these components")
+	searchCmd.Flags().StringSliceVar(&searchFilters.Sources, "source", nil, "Only chunks from these sources: repo, docs, external")
+	searchCmd.Flags().StringSliceVarP(&searchFilters.Exclude, "exclude", "x", nil, "Exclude paths with this prefix or matching this glob")
+	searchCmd.Flags().BoolVar(&searchMMR, "mmr", false, "Re-rank with maximal marginal relevance (default from project.yaml)")
+	searchCmd.Flags().Float64Var(&searchMMRLambda, "mmr-lambda", 0, "MMR trade-off between relevance (1) and diversity (0)")
+	searchCmd.Flags().IntVar(&searchMaxPerFile, "max-per-file", 0, "Max results from the same file, 0 for unlimited (default from project.yaml)")
+	searchCmd.Flags().Float64Var(&searchMinSimilarity, "min-similarity", 0, "Drop vector hits below this cosine similarity, e.g. 0.3 (default from project.yaml)")
+	searchCmd.Flags().IntVarP(&searchExpand, "expand", "e", 0, "Neighbouring chunks to show on each side of a hit")
+	searchCmd.Flags().IntVar(&searchBudget, "budget", search.DefaultExpandBudget, "Token budget for all expanded excerpts")
+	searchCmd.Flags().StringVar(&searchRerank, "rerank", "", "Re-rank provider: none, lexical, llm or openai-compatible (default from project.yaml)")
+	searchCmd.Flags().IntVar(&searchRerankTop, "rerank-top", 0, "Number of hits to re-rank (default from project.yaml)")
+	searchCmd.Flags().BoolVar(&searchExplain, "explain", false, "Show the score breakdown of each result and why other candidates were cut")
+	searchCmd.Flags().StringVarP(&searchFormat, "format", "f", formatText, "Output format: text, json, jsonl, vimgrep or markdown")
+	searchCmd.Flags().BoolVarP(&searchInteractive, "interactive", "i", false, "Start an interactive search session")
+	searchCmd.Flags().BoolVar(&searchAllProjects, "all-projects", false, "Search every project registered by 'oview up'")
+	searchCmd.Flags().StringSliceVar(&searchProjects, "projects", nil, "Search these registered projects (slugs) along with the current one")
+	searchCmd.Flags().StringVar(&searchIncompatible, "incompatible", search.IncompatibleEmbed, "Projects with another embeddings model: embed, lexical or skip")
 	rootCmd.AddCommand(searchCmd)
 }
 
@@ -36,10 +132,24 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get current directory: %w", err)
 	}
 
-	query := strings.Join(args, " ")
+	rawQuery := strings.Join(args, " ")
 
-	fmt.Println("🔍 Searching codebase...")
-	fmt.Println()
+	if !validSearchFormat(searchFormat) {
+		return fmt.Errorf("unknown output format: %s (use text, json, jsonl, vimgrep or markdown)", searchFormat)
+	}
+	text := searchFormat == formatText && !searchInteractive
+	crossProject := searchAllProjects || len(searchProjects) > 0
+	if crossProject && searchInteractive {
+		return fmt.Errorf("--interactive cannot be combined with --all-projects or --projects")
+	}
+	if !search.ValidIncompatible(searchIncompatible) {
+		return fmt.Errorf("invalid --incompatible policy: %s (use embed, lexical or skip)", searchIncompatible)
+	}
+
+	if text {
+		fmt.Println("🔍 Searching codebase...")
+		fmt.Println()
+	}
 
 	// Load project config
 	projectConfig, err := config.LoadProjectConfig(projectPath)
@@ -53,71 +163,127 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load global config: %w", err)
 	}
 
-	// Print embeddings info
-	fmt.Printf("📊 Query: \"%s\"\n", query)
-	fmt.Printf("🤖 Using embeddings: %s / %s (%d dimensions)\n",
-		projectConfig.Embeddings.Provider,
-		projectConfig.Embeddings.Model,
-		projectConfig.Embeddings.Dim)
-	fmt.Println()
+	// Connect to database
+	engine, err := search.Open(projectConfig, globalConfig)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	if text {
+		// Print embeddings info
+		fmt.Printf("📊 Query: \"%s\"\n", rawQuery)
+		fmt.Printf("🤖 Using embeddings: %s / %s (%d dimensions)\n",
+			projectConfig.Embeddings.Provider,
+			projectConfig.Embeddings.Model,
+			projectConfig.Embeddings.Dim)
+		fmt.Println()
 
-	// Initialize embeddings generator
-	var generator embeddings.Generator
-	switch projectConfig.Embeddings.Provider {
-	case "openai":
-		apiKey := os.Getenv("OPENAI_API_KEY")
-		if apiKey == "" {
-			apiKey = projectConfig.Embeddings.APIKey
-		}
-		if apiKey == "" {
-			return fmt.Errorf("OPENAI_API_KEY not set")
-		}
-		generator = embeddings.NewOpenAIGenerator(apiKey, projectConfig.Embeddings.Model)
+		// Search for similar chunks
+		fmt.Println("🔎 Searching for similar chunks...")
+		fmt.Println()
+	}
 
-	case "ollama":
-		baseURL := projectConfig.Embeddings.BaseURL
-		if baseURL == "" {
-			baseURL = "http://localhost:11434"
+	opts := engine.DefaultOptions()
+	opts.Query = rawQuery
+	opts.Limit = searchLimit
+	opts.Filters = searchFilters
+	if searchMode != "" {
+		opts.Mode = searchMode
+	}
+	if cmd.Flags().Changed("mmr") {
+		opts.MMR = searchMMR
+	}
+	if cmd.Flags().Changed("mmr-lambda") {
+		if searchMMRLambda < 0 || searchMMRLambda > 1 {
+			return fmt.Errorf("--mmr-lambda must be between 0 and 1, got %g", searchMMRLambda)
 		}
-		generator = embeddings.NewOllamaGenerator(baseURL, projectConfig.Embeddings.Model)
-
-	default:
-		return fmt.Errorf("unsupported embeddings provider: %s", projectConfig.Embeddings.Provider)
+		opts.MMR = true
+		opts.MMRLambda = &searchMMRLambda
 	}
-
-	// Generate embedding for query
-	fmt.Println("🧮 Generating query embedding...")
-	queryEmbedding, err := generator.Embed(query)
-	if err != nil {
-		return fmt.Errorf("failed to generate query embedding: %w", err)
+	if cmd.Flags().Changed("max-per-file") {
+		opts.MaxPerFile = searchMaxPerFile
 	}
+	if cmd.Flags().Changed("min-similarity") {
+		opts.Threshold = searchMinSimilarity
+	}
+	opts.Expand = searchExpand
+	opts.ExpandBudget = searchBudget
+	if cmd.Flags().Changed("rerank") {
+		opts.Rerank = searchRerank
+	}
+	if cmd.Flags().Changed("rerank-top") {
+		opts.RerankTopN = searchRerankTop
+	}
+	opts.Explain = searchExplain
 
-	// Connect to database
-	dbName := fmt.Sprintf("oview_%s", projectConfig.ProjectSlug)
-	dbUser := fmt.Sprintf("oview_%s", projectConfig.ProjectSlug)
-	dbPassword := projectConfig.Database.Password
-
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		dbUser, dbPassword, globalConfig.PostgresHost, globalConfig.PostgresPort, dbName)
+	if searchInteractive {
+		return runInteractiveSearch(engine, opts, rawQuery)
+	}
 
-	db, err := sql.Open("postgres", dsn)
-	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+	var resp *search.Response
+	if crossProject {
+		targets, err := searchTargets(engine, projectConfig, globalConfig, projectPath, searchProjects)
+		if err != nil {
+			return err
+		}
+		defer closeTargets(targets)
+		resp, err = search.SearchProjects(targets, opts, searchIncompatible)
+		if err != nil {
+			return fmt.Errorf("failed to search: %w", err)
+		}
+	} else {
+		resp, err = engine.Search(opts)
+		if err != nil {
+			return fmt.Errorf("failed to search: %w", err)
+		}
+	}
+	var stale map[string]string
+	if !crossProject {
+		stale = staleResults(projectPath, resp.Results)
+		if len(stale) > 0 {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("%d files in the results changed since indexing, run 'oview index'", len(stale)))
+		}
+	}
+	if !text {
+		for _, warning := range resp.Warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+		}
+		return writeSearchResults(os.Stdout, searchFormat, resp, stale)
 	}
-	defer db.Close()
+	mode := resp.Mode
+	results := resp.Results
 
-	// Search for similar chunks
-	fmt.Println("🔎 Searching for similar chunks...")
+	if resp.Query.Text != rawQuery {
+		fmt.Printf("🧩 Interpreted as: text \"%s\"", resp.Query.Text)
+		if len(resp.Query.Phrases) > 0 {
+			fmt.Printf(", phrases \"%s\"", strings.Join(resp.Query.Phrases, "\", \""))
+		}
+		fmt.Println()
+	}
+	fmt.Printf("🎛️  Mode: %s\n", mode)
+	if resp.Reranker != "" {
+		fmt.Printf("🏅 Re-ranked by: %s\n", resp.Reranker)
+	}
+	for _, warning := range resp.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+	if f := resp.Filters.String(); f != "" {
+		fmt.Printf("🧷 Filters: %s\n", f)
+	}
 	fmt.Println()
-
-	results, err := searchSimilarChunks(db, projectConfig.ProjectID, queryEmbedding, searchLimit)
-	if err != nil {
-		return fmt.Errorf("failed to search: %w", err)
+	if resp.Projects != nil {
+		printProjects(resp.Projects)
 	}
 
 	// Display results
 	if len(results) == 0 {
 		fmt.Println("❌ No results found")
+		fmt.Println()
+		if opts.Explain {
+			printCuts(resp.Cut)
+		}
+		printTimings(resp)
 		return nil
 	}
 
@@ -126,9 +292,29 @@ func runSearch(cmd *cobra.Command, args []string) error {
 
 	for i, result := range results {
 		fmt.Printf("═══════════════════════════════════════════════════════════════\n")
-		fmt.Printf("Result #%d - Similarity: %.2f%%\n", i+1, result.Similarity*100)
+		switch {
+		case result.Project != "":
+			fmt.Printf("Result #%d - [%s] Score: %.4f (merged across projects)\n", i+1, result.Project, result.Score)
+		case mode == search.ModeLexical:
+			fmt.Printf("Result #%d - Lexical score: %.3f\n", i+1, result.LexicalScore)
+		case mode == search.ModeHybrid:
+			fmt.Printf("Result #%d - Score: %.4f (similarity %.2f%%, lexical %.3f)\n",
+				i+1, result.Score, result.Similarity*100, result.LexicalScore)
+		default:
+			fmt.Printf("Result #%d - Similarity: %.2f%%\n", i+1, result.Similarity*100)
+		}
+		if resp.Reranker != "" {
+			fmt.Printf("Re-rank score: %.3f\n", result.RerankScore)
+		}
 		fmt.Printf("───────────────────────────────────────────────────────────────\n")
-		fmt.Printf("📁 File:     %s\n", result.Path)
+		if result.StartLine > 0 {
+			fmt.Printf("📁 File:     %s:%d-%d\n", result.Path, result.StartLine, result.EndLine)
+		} else {
+			fmt.Printf("📁 File:     %s\n", result.Path)
+		}
+		if reason, ok := stale[result.Path]; ok {
+			fmt.Printf("⚠️  Stale:    %s since indexing\n", reason)
+		}
 		if result.Symbol != "" {
 			fmt.Printf("🔤 Symbol:   %s\n", result.Symbol)
 		}
@@ -137,6 +323,22 @@ func runSearch(cmd *cobra.Command, args []string) error {
 			fmt.Printf("💻 Language: %s\n", result.Language)
 		}
 		fmt.Println()
+		if result.Explain != nil {
+			printExplanation(result.Explain)
+		}
+
+		if result.Excerpt != "" {
+			if result.ExcerptStart > 0 {
+				fmt.Printf("📝 Excerpt (lines %d-%d):\n", result.ExcerptStart, result.ExcerptEnd)
+			} else {
+				fmt.Printf("📝 Excerpt:\n")
+			}
+			fmt.Println("───────────────────────────────────────────────────────────────")
+			fmt.Println(result.Excerpt)
+			fmt.Println()
+			continue
+		}
+
 		fmt.Printf("📝 Content preview:\n")
 		fmt.Println("───────────────────────────────────────────────────────────────")
 
@@ -149,59 +351,104 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 	}
 
+	if opts.Explain {
+		printCuts(resp.Cut)
+	}
+	printTimings(resp)
 	return nil
 }
 
-// SearchResult represents a search result
-type SearchResult struct {
-	ID         int
-	Path       string
-	Type       string
-	Language   string
-	Symbol     string
-	Content    string
-	Similarity float64
+// staleResults returns the result paths changed or deleted since indexing,
+// with the reason
+func staleResults(projectPath string, results []search.Result) map[string]string {
+	stale := make(map[string]string)
+	manifest, err := indexer.LoadManifest(projectPath)
+	if err != nil {
+		return stale
+	}
+	for _, r := range results {
+		if reason := manifest.Staleness(projectPath, r.Path); reason != "" {
+			stale[r.Path] = reason
+		}
+	}
+	return stale
 }
 
-// searchSimilarChunks searches for chunks similar to the query embedding
-func searchSimilarChunks(db *sql.DB, projectID string, queryEmbedding []float32, limit int) ([]SearchResult, error) {
-	// Convert embedding to PostgreSQL array format
-	embeddingStr := embeddingToString(queryEmbedding)
-
-	query := `
-		SELECT
-			id, path, type, COALESCE(language, ''), COALESCE(symbol, ''), content,
-			1 - (embedding <=> $1::vector) as similarity
-		FROM chunks
-		WHERE project_id = $2
-		ORDER BY embedding <=> $1::vector
-		LIMIT $3
-	`
-
-	rows, err := db.Query(query, embeddingStr, projectID, limit)
-	if err != nil {
-		return nil, fmt.Errorf("query failed: %w", err)
+// printExplanation shows how a result was retrieved and scored
+func printExplanation(x *search.Explanation) {
+	fmt.Println("🔬 Why this result:")
+	if x.VectorRank > 0 || x.VectorDistance != nil {
+		fmt.Printf("   Vector:   rank %s, cosine distance %s\n", rankString(x.VectorRank), distanceString(x.VectorDistance))
+	}
+	lexical := fmt.Sprintf("rank %s, full-text %.3f, symbol similarity %.2f", rankString(x.LexicalRank), x.TextRank, x.SymbolSimilarity)
+	if x.ExactMatch {
+		lexical += ", exact match"
 	}
-	defer rows.Close()
+	fmt.Printf("   Lexical:  %s\n", lexical)
 
-	var results []SearchResult
-	for rows.Next() {
-		var r SearchResult
-		err := rows.Scan(&r.ID, &r.Path, &r.Type, &r.Language, &r.Symbol, &r.Content, &r.Similarity)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+	boosts := make([]string, len(x.Boosts))
+	for i, b := range x.Boosts {
+		boosts[i] = fmt.Sprintf("%s %+.4f", b.Name, b.Value)
+	}
+	fmt.Printf("   Score:    %s\n", strings.Join(boosts, ", "))
+	if len(x.MatchedFilters) > 0 {
+		fmt.Printf("   Filters:  %s\n", strings.Join(x.MatchedFilters, ", "))
+	}
+
+	indexed := "unknown"
+	if x.IndexedAt != nil {
+		indexed = x.IndexedAt.Format("2006-01-02 15:04")
+	}
+	if x.CommitSHA != "" {
+		indexed += fmt.Sprintf(" (commit %.10s)", x.CommitSHA)
+	}
+	fmt.Printf("   Indexed:  %s\n", indexed)
+	fmt.Println()
+}
+
+// printCuts lists the candidates left out of the results, closest to the results first
+func printCuts(cuts []search.Cut) {
+	if len(cuts) == 0 {
+		return
+	}
+
+	const shown = 10
+	fmt.Printf("✂️  Cut candidates (%d):\n", len(cuts))
+	for i, c := range cuts {
+		if i == shown {
+			fmt.Printf("   ... and %d more\n", len(cuts)-shown)
+			break
 		}
-		results = append(results, r)
+		name := c.Result.Path
+		if c.Result.Symbol != "" {
+			name += " " + c.Result.Symbol
+		}
+		fmt.Printf("   [%s] %s: %s\n", c.Stage, name, c.Reason)
+	}
+	fmt.Println()
+}
+
+// rankString formats a ranking position, "-" when the result was not in that ranking
+func rankString(rank int) string {
+	if rank == 0 {
+		return "-"
 	}
+	return fmt.Sprintf("#%d", rank)
+}
 
-	return results, nil
+// distanceString formats a cosine distance, "-" when it was not computed
+func distanceString(distance *float64) string {
+	if distance == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.4f", *distance)
 }
 
-// embeddingToString converts a float32 slice to PostgreSQL vector string format
-func embeddingToString(embedding []float32) string {
-	parts := make([]string, len(embedding))
-	for i, v := range embedding {
-		parts[i] = fmt.Sprintf("%f", v)
+// printTimings shows the time spent in each search stage
+func printTimings(resp *search.Response) {
+	parts := make([]string, len(resp.Timings))
+	for i, t := range resp.Timings {
+		parts[i] = fmt.Sprintf("%s %v", t.Stage, t.Duration.Round(100*time.Microsecond))
 	}
-	return "[" + strings.Join(parts, ",") + "]"
+	fmt.Printf("⏱️  %s\n", strings.Join(parts, " · "))
 }
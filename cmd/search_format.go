@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/oview/internal/search"
+)
+
+// Output formats of `oview search`
+const (
+	formatText     = "text"
+	formatJSON     = "json"
+	formatJSONL    = "jsonl"
+	formatVimgrep  = "vimgrep"
+	formatMarkdown = "markdown"
+)
+
+// validSearchFormat reports whether format is a supported output format
+func validSearchFormat(format string) bool {
+	switch format {
+	case formatText, formatJSON, formatJSONL, formatVimgrep, formatMarkdown:
+		return true
+	}
+	return false
+}
+
+// searchResultJSON is a result in the json and jsonl formats
+type searchResultJSON struct {
+	Rank         int                 `json:"rank"`
+	Project      string              `json:"project,omitempty"`
+	Path         string              `json:"path"`
+	StartLine    int                 `json:"start_line,omitempty"`
+	EndLine      int                 `json:"end_line,omitempty"`
+	Type         string              `json:"type"`
+	Language     string              `json:"language,omitempty"`
+	Symbol       string              `json:"symbol,omitempty"`
+	Score        float64             `json:"score"`
+	Similarity   float64             `json:"similarity,omitempty"`
+	LexicalScore float64             `json:"lexical_score,omitempty"`
+	RerankScore  float64             `json:"rerank_score,omitempty"`
+	Content      string              `json:"content"`
+	Excerpt      string              `json:"excerpt,omitempty"`
+	ExcerptStart int                 `json:"excerpt_start,omitempty"`
+	ExcerptEnd   int                 `json:"excerpt_end,omitempty"`
+	Explain      *search.Explanation `json:"explain,omitempty"`
+	Stale        string              `json:"stale,omitempty"` // modified or deleted since indexing
+}
+
+// searchCutJSON is a candidate left out of the results
+type searchCutJSON struct {
+	Path   string `json:"path"`
+	Symbol string `json:"symbol,omitempty"`
+	Stage  string `json:"stage"`
+	Reason string `json:"reason"`
+}
+
+// searchOutputJSON is the document written by the json format
+type searchOutputJSON struct {
+	Query          string                 `json:"query"`
+	Interpretation map[string]interface{} `json:"interpretation"`
+	Mode           string                 `json:"mode"`
+	Reranker       string                 `json:"reranker,omitempty"`
+	Count          int                    `json:"count"`
+	Results        []searchResultJSON     `json:"results"`
+	Cut            []searchCutJSON        `json:"cut,omitempty"`
+	Warnings       []string               `json:"warnings,omitempty"`
+	TimingsMS      map[string]float64     `json:"timings_ms"`
+	Projects       []searchProjectJSON    `json:"projects,omitempty"`
+}
+
+// searchProjectJSON is a project taking part in a cross-project search
+type searchProjectJSON struct {
+	Name    string `json:"name"`
+	Root    string `json:"root"`
+	Model   string `json:"embedding_model,omitempty"`
+	Mode    string `json:"mode,omitempty"`
+	Results int    `json:"results"`
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// writeSearchResults writes a response in one of the machine-readable formats.
+// stale holds the result paths changed since indexing, with the reason
+func writeSearchResults(w io.Writer, format string, resp *search.Response, stale map[string]string) error {
+	switch format {
+	case formatJSON:
+		out := searchOutputJSON{
+			Query:          resp.Query.Raw,
+			Interpretation: resp.Interpretation(),
+			Mode:           resp.Mode,
+			Reranker:       resp.Reranker,
+			Count:          len(resp.Results),
+			Results:        make([]searchResultJSON, len(resp.Results)),
+			Warnings:       resp.Warnings,
+			TimingsMS:      resp.TimingsMS(),
+		}
+		for i, r := range resp.Results {
+			out.Results[i] = resultJSON(i+1, r, stale[r.Path])
+		}
+		for _, p := range resp.Projects {
+			out.Projects = append(out.Projects, searchProjectJSON{Name: p.Name, Root: p.Root, Model: p.Model, Mode: p.Mode, Results: p.Results, Skipped: p.Skipped})
+		}
+		for _, c := range resp.Cut {
+			out.Cut = append(out.Cut, searchCutJSON{Path: c.Result.Path, Symbol: c.Result.Symbol, Stage: c.Stage, Reason: c.Reason})
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+
+	case formatJSONL:
+		encoder := json.NewEncoder(w)
+		for i, r := range resp.Results {
+			if err := encoder.Encode(resultJSON(i+1, r, stale[r.Path])); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case formatVimgrep:
+		terms := queryTerms(resp.Query)
+		roots := make(map[string]string, len(resp.Projects))
+		for _, p := range resp.Projects {
+			roots[p.Name] = p.Root
+		}
+		for _, r := range resp.Results {
+			line, col, text := matchLine(r, terms)
+			path := r.Path
+			if root := roots[r.Project]; root != "" {
+				path = filepath.Join(root, r.Path) // results of other projects must open from here
+			}
+			if _, err := fmt.Fprintf(w, "%s:%d:%d:%s\n", path, line, col, text); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case formatMarkdown:
+		return writeMarkdown(w, resp)
+
+	default:
+		return fmt.Errorf("unknown output format: %s (use text, json, jsonl, vimgrep or markdown)", format)
+	}
+}
+
+// resultJSON converts a result with its rank and staleness
+func resultJSON(rank int, r search.Result, stale string) searchResultJSON {
+	return searchResultJSON{
+		Rank:         rank,
+		Project:      r.Project,
+		Path:         r.Path,
+		StartLine:    r.StartLine,
+		EndLine:      r.EndLine,
+		Type:         r.Type,
+		Language:     r.Language,
+		Symbol:       r.Symbol,
+		Score:        r.Score,
+		Similarity:   r.Similarity,
+		LexicalScore: r.LexicalScore,
+		RerankScore:  r.RerankScore,
+		Content:      r.Content,
+		Excerpt:      r.Excerpt,
+		ExcerptStart: r.ExcerptStart,
+		ExcerptEnd:   r.ExcerptEnd,
+		Explain:      r.Explain,
+		Stale:        stale,
+	}
+}
+
+// queryTerms returns the lowercased words and phrases of the query, longest first
+func queryTerms(q search.Query) []string {
+	var terms []string
+	for _, p := range q.Phrases {
+		terms = append(terms, strings.ToLower(p))
+	}
+	for _, word := range strings.Fields(q.Text) {
+		if len(word) > 1 {
+			terms = append(terms, strings.ToLower(word))
+		}
+	}
+	sort.SliceStable(terms, func(i, j int) bool {
+		return len(terms[i]) > len(terms[j])
+	})
+	return terms
+}
+
+// matchLine picks the line of a result to jump to: the first one containing the most
+// specific query term, otherwise the first non-blank line. Lines and columns are 1-based.
+func matchLine(r search.Result, terms []string) (int, int, string) {
+	start := r.StartLine
+	if start == 0 {
+		start = 1
+	}
+
+	lines := strings.Split(r.Content, "\n")
+	for _, term := range terms {
+		for i, line := range lines {
+			if col := strings.Index(strings.ToLower(line), term); col >= 0 {
+				return start + i, col + 1, strings.TrimRight(line, " \t\r")
+			}
+		}
+	}
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			return start + i, 1, strings.TrimRight(line, " \t\r")
+		}
+	}
+	return start, 1, ""
+}
+
+// writeMarkdown writes the results as a Markdown document, one section per hit
+// with its full content (or excerpt) in a code fence
+func writeMarkdown(w io.Writer, resp *search.Response) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Search: %s\n\n", resp.Query.Raw)
+	fmt.Fprintf(&b, "_Mode: %s · %d results", resp.Mode, len(resp.Results))
+	if f := resp.Filters.String(); f != "" {
+		fmt.Fprintf(&b, " · filters: %s", f)
+	}
+	b.WriteString("_\n\n")
+
+	for i, r := range resp.Results {
+		b.WriteString(markdownSection(i+1, r))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownSection formats one hit: a heading with its location and symbol, its
+// scores and its full content (or excerpt) in a code fence
+func markdownSection(rank int, r search.Result) string {
+	var b strings.Builder
+	location := r.Path
+	if r.StartLine > 0 {
+		location = fmt.Sprintf("%s:%d-%d", r.Path, r.StartLine, r.EndLine)
+	}
+	fmt.Fprintf(&b, "## %d. `%s`", rank, location)
+	if r.Project != "" {
+		fmt.Fprintf(&b, " [%s]", r.Project)
+	}
+	if r.Symbol != "" {
+		fmt.Fprintf(&b, " — `%s`", r.Symbol)
+	}
+	b.WriteString("\n\n")
+
+	meta := []string{fmt.Sprintf("score %.4f", r.Score)}
+	if r.Similarity > 0 {
+		meta = append(meta, fmt.Sprintf("similarity %.1f%%", r.Similarity*100))
+	}
+	meta = append(meta, r.Type)
+	if r.Language != "" {
+		meta = append(meta, r.Language)
+	}
+	fmt.Fprintf(&b, "%s\n\n", strings.Join(meta, " · "))
+
+	content := r.Content
+	if r.Excerpt != "" {
+		content = r.Excerpt
+	}
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(&b, "%s%s\n%s\n%s\n\n", fence, fenceLanguage(r), strings.TrimRight(content, "\n"), fence)
+	return b.String()
+}
+
+// fenceLanguage returns the code fence info string of a result
+func fenceLanguage(r search.Result) string {
+	if r.Language != "" {
+		return strings.ToLower(r.Language)
+	}
+	return strings.TrimPrefix(filepath.Ext(r.Path), ".")
+}
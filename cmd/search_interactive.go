@@ -0,0 +1,652 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yourusername/oview/internal/config"
+	"github.com/yourusername/oview/internal/search"
+)
+
+// historySize is the number of queries kept in ~/.oview/search_history
+const historySize = 500
+
+const interactiveHelp = `Type a query to search (inline qualifiers allowed). Commands:
+  <enter>, :n / :p       next / previous result
+  :N, :show N            show result N in full
+  :open [N]              open the result in $EDITOR at its line
+  :copy [N...]           copy results as Markdown context (clipboard, or printed)
+  :list                  list the current results again
+  :type|:lang|:path|:component|:source|:exclude <value>
+                         toggle a filter value
+  :mode vector|lexical|hybrid, :limit N, :expand N, :rerank <provider>
+  :explain               toggle the score breakdown
+  :filters, :clear       show / clear the filters
+  :history, !N, !!       list / re-run previous queries
+  :help, :quit`
+
+// interactiveSession is a long-lived search session sharing one engine
+type interactiveSession struct {
+	engine  *search.Engine
+	opts    search.Options
+	in      *bufio.Reader
+	out     io.Writer
+	color   bool
+	history []string
+
+	resp     *search.Response
+	selected int
+	shown    bool // the selected result has been previewed
+}
+
+// runInteractiveSearch reads queries and commands until :quit or end of input
+func runInteractiveSearch(engine *search.Engine, opts search.Options, firstQuery string) error {
+	// One embedder and connection for the session; repeated queries are not re-embedded
+	engine.SetCache(search.NewEmbeddingCache(0))
+
+	s := &interactiveSession{
+		engine:  engine,
+		opts:    opts,
+		in:      bufio.NewReader(os.Stdin),
+		out:     os.Stdout,
+		color:   useColor(),
+		history: loadHistory(),
+	}
+
+	fmt.Fprintln(s.out, "🔍 oview interactive search — :help for commands, :quit to exit")
+	fmt.Fprintln(s.out)
+
+	if firstQuery != "" {
+		s.search(firstQuery)
+	}
+
+	for {
+		fmt.Fprint(s.out, s.prompt())
+		line, err := s.in.ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintln(s.out)
+			return nil
+		}
+
+		if quit := s.handle(strings.TrimSpace(line)); quit {
+			return nil
+		}
+	}
+}
+
+// prompt shows the active mode and filters
+func (s *interactiveSession) prompt() string {
+	parts := []string{"oview"}
+	if s.opts.Mode != "" {
+		parts = append(parts, s.opts.Mode)
+	}
+	if f := s.opts.Filters.String(); f != "" {
+		parts = append(parts, f)
+	}
+	return "[" + strings.Join(parts, " ") + "]> "
+}
+
+// handle runs one input line and reports whether the session should end
+func (s *interactiveSession) handle(line string) bool {
+	switch {
+	case line == "":
+		s.move(1)
+	case line == "!!":
+		if len(s.history) == 0 {
+			fmt.Fprintln(s.out, "No previous query")
+			return false
+		}
+		s.search(s.history[len(s.history)-1])
+	case strings.HasPrefix(line, "!"):
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 1 || n > len(s.history) {
+			fmt.Fprintf(s.out, "No history entry %s\n", line[1:])
+			return false
+		}
+		s.search(s.history[n-1])
+	case strings.HasPrefix(line, ":"):
+		return s.command(line[1:])
+	default:
+		s.search(line)
+	}
+	return false
+}
+
+// command runs a ":" command and reports whether the session should end
+func (s *interactiveSession) command(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+	name, args := fields[0], fields[1:]
+	arg := strings.Join(args, " ")
+
+	switch name {
+	case "q", "quit", "exit":
+		return true
+	case "h", "help":
+		fmt.Fprintln(s.out, interactiveHelp)
+	case "s", "show":
+		if !isNumber(arg) {
+			fmt.Fprintln(s.out, "Use :show N")
+			return false
+		}
+		n, _ := strconv.Atoi(arg)
+		s.show(n - 1)
+	case "n", "next":
+		s.move(1)
+	case "p", "prev":
+		s.move(-1)
+	case "l", "list":
+		s.list()
+	case "o", "open":
+		if r, ok := s.pick(args); ok {
+			s.open(r)
+		}
+	case "c", "copy":
+		s.copy(args)
+	case "type":
+		s.toggle(&s.opts.Filters.Types, arg)
+	case "lang":
+		s.toggle(&s.opts.Filters.Languages, arg)
+	case "path":
+		s.toggle(&s.opts.Filters.Paths, arg)
+	case "component":
+		s.toggle(&s.opts.Filters.Components, arg)
+	case "source":
+		s.toggle(&s.opts.Filters.Sources, arg)
+	case "exclude":
+		s.toggle(&s.opts.Filters.Exclude, arg)
+	case "filters":
+		if f := s.opts.Filters.String(); f != "" {
+			fmt.Fprintf(s.out, "🧷 Filters: %s\n", f)
+		} else {
+			fmt.Fprintln(s.out, "No filters")
+		}
+	case "clear":
+		s.opts.Filters = search.Filters{}
+		s.rerun()
+	case "mode":
+		if !search.ValidMode(arg) {
+			fmt.Fprintln(s.out, "Use :mode vector, lexical or hybrid")
+			return false
+		}
+		s.opts.Mode = arg
+		s.rerun()
+	case "limit", "expand":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			fmt.Fprintf(s.out, "Use :%s N\n", name)
+			return false
+		}
+		if name == "limit" {
+			s.opts.Limit = n
+		} else {
+			s.opts.Expand = n
+		}
+		s.rerun()
+	case "rerank":
+		s.opts.Rerank = arg
+		s.rerun()
+	case "explain":
+		s.opts.Explain = !s.opts.Explain
+		fmt.Fprintf(s.out, "Explain: %v\n", s.opts.Explain)
+		s.rerun()
+	case "history":
+		for i, q := range s.history {
+			fmt.Fprintf(s.out, "%4d  %s\n", i+1, q)
+		}
+	default:
+		if isNumber(name) {
+			n, _ := strconv.Atoi(name)
+			s.show(n - 1)
+			return false
+		}
+		fmt.Fprintf(s.out, "Unknown command :%s (:help for the list)\n", name)
+	}
+	return false
+}
+
+// search runs a query, records it in the history and lists the results
+func (s *interactiveSession) search(query string) {
+	s.remember(query)
+
+	opts := s.opts
+	opts.Query = query
+	resp, err := s.engine.Search(opts)
+	if err != nil {
+		fmt.Fprintf(s.out, "❌ %v\n", err)
+		return
+	}
+
+	s.resp = resp
+	s.selected = 0
+	s.shown = false
+	for _, warning := range resp.Warnings {
+		fmt.Fprintf(s.out, "⚠️  %s\n", warning)
+	}
+	s.list()
+}
+
+// rerun repeats the last query with the current settings
+func (s *interactiveSession) rerun() {
+	if s.resp == nil {
+		return
+	}
+	opts := s.opts
+	opts.Query = s.resp.Query.Raw
+	resp, err := s.engine.Search(opts)
+	if err != nil {
+		fmt.Fprintf(s.out, "❌ %v\n", err)
+		return
+	}
+	s.resp = resp
+	s.selected = 0
+	s.shown = false
+	s.list()
+}
+
+// list prints one line per result, marking the selected one
+func (s *interactiveSession) list() {
+	if s.resp == nil {
+		return
+	}
+	if len(s.resp.Results) == 0 {
+		fmt.Fprintln(s.out, "❌ No results found")
+		if s.opts.Explain {
+			printCuts(s.resp.Cut)
+		}
+		return
+	}
+
+	fmt.Fprintln(s.out)
+	for i, r := range s.resp.Results {
+		marker := "  "
+		if i == s.selected {
+			marker = "▶ "
+		}
+		fmt.Fprintf(s.out, "%s%2d. %.4f  %s", marker, i+1, r.Score, s.paint(ansiBold, location(r)))
+		if r.Symbol != "" {
+			fmt.Fprintf(s.out, "  %s", r.Symbol)
+		}
+		fmt.Fprintf(s.out, "  %s\n", s.paint(ansiGray, "["+strings.TrimSpace(strings.ToLower(r.Language)+" "+r.Type)+"]"))
+	}
+	printTimings(s.resp)
+	fmt.Fprintln(s.out)
+}
+
+// move selects the next or previous result and shows it
+func (s *interactiveSession) move(delta int) {
+	if s.resp == nil || len(s.resp.Results) == 0 {
+		return
+	}
+	n := s.selected
+	if s.shown {
+		n += delta
+	}
+	if n < 0 || n >= len(s.resp.Results) {
+		fmt.Fprintln(s.out, "No more results")
+		return
+	}
+	s.show(n)
+}
+
+// show selects a result and prints it in full with line numbers and highlighting
+func (s *interactiveSession) show(i int) {
+	if s.resp == nil || i < 0 || i >= len(s.resp.Results) {
+		fmt.Fprintln(s.out, "No such result")
+		return
+	}
+	s.selected = i
+	s.shown = true
+	r := s.resp.Results[i]
+
+	fmt.Fprintln(s.out, "═══════════════════════════════════════════════════════════════")
+	fmt.Fprintf(s.out, "#%d  %s", i+1, s.paint(ansiBold, location(r)))
+	if r.Symbol != "" {
+		fmt.Fprintf(s.out, "  %s", r.Symbol)
+	}
+	fmt.Fprintf(s.out, "  (score %.4f)\n", r.Score)
+	fmt.Fprintln(s.out, "───────────────────────────────────────────────────────────────")
+	if r.Explain != nil {
+		printExplanation(r.Explain)
+	}
+
+	if r.Excerpt != "" {
+		fmt.Fprintln(s.out, r.Excerpt)
+	} else {
+		for j, line := range strings.Split(strings.TrimRight(r.Content, "\n"), "\n") {
+			number := ""
+			if r.StartLine > 0 {
+				number = s.paint(ansiGray, fmt.Sprintf("%5d | ", r.StartLine+j))
+			}
+			fmt.Fprintln(s.out, number+s.highlight(line, r.Language))
+		}
+	}
+	fmt.Fprintln(s.out)
+}
+
+// pick returns the result named by the first argument, or the selected one
+func (s *interactiveSession) pick(args []string) (search.Result, bool) {
+	if s.resp == nil || len(s.resp.Results) == 0 {
+		fmt.Fprintln(s.out, "No results")
+		return search.Result{}, false
+	}
+	i := s.selected
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > len(s.resp.Results) {
+			fmt.Fprintf(s.out, "No result %s\n", args[0])
+			return search.Result{}, false
+		}
+		i = n - 1
+	}
+	return s.resp.Results[i], true
+}
+
+// open runs $EDITOR on the result's file at its first line
+func (s *interactiveSession) open(r search.Result) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	line := r.StartLine
+	if line == 0 {
+		line = 1
+	}
+	parts := strings.Fields(editor)
+	args := append(parts[1:], editorArgs(filepath.Base(parts[0]), r.Path, line)...)
+
+	cmd := exec.Command(parts[0], args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(s.out, "❌ Failed to run %s: %v\n", editor, err)
+	}
+}
+
+// editorArgs returns the arguments that open path at line in a given editor
+func editorArgs(editor, path string, line int) []string {
+	switch editor {
+	case "code", "code-insiders", "codium", "cursor":
+		return []string{"-g", fmt.Sprintf("%s:%d", path, line)}
+	case "subl", "zed":
+		return []string{fmt.Sprintf("%s:%d", path, line)}
+	default:
+		// vi, vim, nvim, nano, emacs, micro, helix...
+		return []string{fmt.Sprintf("+%d", line), path}
+	}
+}
+
+// copy puts the given results (or the selected one) on the clipboard as Markdown
+func (s *interactiveSession) copy(args []string) {
+	if s.resp == nil || len(s.resp.Results) == 0 {
+		fmt.Fprintln(s.out, "No results")
+		return
+	}
+
+	var b strings.Builder
+	if len(args) == 0 {
+		b.WriteString(markdownSection(s.selected+1, s.resp.Results[s.selected]))
+	}
+	for _, a := range args {
+		n, err := strconv.Atoi(a)
+		if err != nil || n < 1 || n > len(s.resp.Results) {
+			fmt.Fprintf(s.out, "No result %s\n", a)
+			return
+		}
+		b.WriteString(markdownSection(n, s.resp.Results[n-1]))
+	}
+
+	text := b.String()
+	if tool, err := copyToClipboard(text); err == nil {
+		fmt.Fprintf(s.out, "📋 Copied %d tokens of context (%s)\n", search.EstimateTokens(text), tool)
+		return
+	}
+	fmt.Fprintln(s.out, "No clipboard tool found (pbcopy, wl-copy, xclip, xsel, clip.exe), printing instead:")
+	fmt.Fprintln(s.out, text)
+}
+
+// copyToClipboard pipes text into the first available clipboard tool
+func copyToClipboard(text string) (string, error) {
+	tools := [][]string{
+		{"pbcopy"},
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+		{"clip.exe"},
+	}
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(tool[0], tool[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return "", err
+		}
+		return tool[0], nil
+	}
+	return "", fmt.Errorf("no clipboard tool found")
+}
+
+// toggle adds a filter value, or removes it when already set, then re-runs the query
+func (s *interactiveSession) toggle(values *[]string, value string) {
+	if value == "" {
+		fmt.Fprintln(s.out, "Missing filter value")
+		return
+	}
+	for i, v := range *values {
+		if v == value {
+			*values = append((*values)[:i], (*values)[i+1:]...)
+			s.rerun()
+			return
+		}
+	}
+	*values = append(*values, value)
+	s.rerun()
+}
+
+// remember appends a query to the history, in memory and on disk
+func (s *interactiveSession) remember(query string) {
+	if n := len(s.history); n > 0 && s.history[n-1] == query {
+		return
+	}
+	s.history = append(s.history, query)
+	if len(s.history) > historySize {
+		s.history = s.history[len(s.history)-historySize:]
+	}
+	saveHistory(s.history)
+}
+
+// historyPath returns the path of the query history file
+func historyPath() (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "search_history"), nil
+}
+
+// loadHistory reads the query history, empty when there is none
+func loadHistory() []string {
+	path, err := historyPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var history []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			history = append(history, line)
+		}
+	}
+	return history
+}
+
+// saveHistory writes the query history; failures only lose the history
+func saveHistory(history []string) {
+	path, err := historyPath()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	os.WriteFile(path, []byte(strings.Join(history, "\n")+"\n"), 0600)
+}
+
+// location formats the path and line range of a result
+func location(r search.Result) string {
+	if r.StartLine > 0 {
+		return fmt.Sprintf("%s:%d-%d", r.Path, r.StartLine, r.EndLine)
+	}
+	return r.Path
+}
+
+// isNumber reports whether s is a positive decimal number
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ANSI styles used by the interactive session
+const (
+	ansiReset   = "\033[0m"
+	ansiBold    = "\033[1m"
+	ansiGray    = "\033[90m"
+	ansiKeyword = "\033[1;34m"
+	ansiString  = "\033[32m"
+	ansiNumber  = "\033[35m"
+	ansiVar     = "\033[36m"
+)
+
+// useColor reports whether stdout is a terminal and NO_COLOR is unset
+func useColor() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// paint wraps text in an ANSI style when colors are on
+func (s *interactiveSession) paint(style, text string) string {
+	if !s.color || text == "" {
+		return text
+	}
+	return style + text + ansiReset
+}
+
+// keywords highlighted in previews, shared by the supported languages
+var keywords = func() map[string]bool {
+	set := make(map[string]bool)
+	for _, k := range strings.Fields(`
+		abstract and as async await break case catch class const continue def default defer
+		do elif else enum export extends false final finally fn for foreach from func function
+		go if implements import in instanceof interface is let match namespace new nil not null
+		or package pass private protected public readonly return select self static struct
+		switch this throw trait true try type use var while with yield None True False`) {
+		set[k] = true
+	}
+	return set
+}()
+
+// highlight colors one line of code: comments, strings, numbers, keywords and
+// $variables. It works line by line, so multi-line strings and comments are only
+// colored on their first line.
+func (s *interactiveSession) highlight(line, language string) string {
+	if !s.color {
+		return line
+	}
+
+	lang := strings.ToLower(language)
+	hashComments := lang == "php" || lang == "python" || lang == "yaml" || lang == "shell" || lang == "ruby" || lang == "toml"
+	slashComments := lang != "python" && lang != "yaml" && lang != "shell" && lang != "sql"
+
+	// Continuation lines of block comments ("* ...")
+	if slashComments && strings.HasPrefix(strings.TrimSpace(line), "*") {
+		return ansiGray + line + ansiReset
+	}
+
+	var b strings.Builder
+	runes := []rune(line)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		rest := string(runes[i:])
+
+		switch {
+		case (slashComments && (strings.HasPrefix(rest, "//") || strings.HasPrefix(rest, "/*"))) ||
+			(hashComments && r == '#' && !strings.HasPrefix(rest, "#[")) ||
+			(lang == "sql" && strings.HasPrefix(rest, "--")):
+			b.WriteString(ansiGray + rest + ansiReset)
+			return b.String()
+
+		case r == '"' || r == '\'' || r == '`':
+			j := i + 1
+			for j < len(runes) && runes[j] != r {
+				if runes[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(runes) {
+				j = len(runes) - 1
+			}
+			b.WriteString(ansiString + string(runes[i:j+1]) + ansiReset)
+			i = j + 1
+
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' || runes[j] == '_') {
+				j++
+			}
+			b.WriteString(ansiNumber + string(runes[i:j]) + ansiReset)
+			i = j
+
+		case r == '$' || unicode.IsLetter(r) || r == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			word := string(runes[i:j])
+			switch {
+			case r == '$':
+				b.WriteString(ansiVar + word + ansiReset)
+			case keywords[word]:
+				b.WriteString(ansiKeyword + word + ansiReset)
+			default:
+				b.WriteString(word)
+			}
+			i = j
+
+		default:
+			b.WriteRune(r)
+			i++
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/oview/internal/config"
+	"github.com/yourusername/oview/internal/search"
+)
+
+// searchTargets opens the projects of a cross-project search: the current project
+// first (it sets the reference embeddings model), then every registered project or
+// only the slugs listed. Projects that cannot be opened are returned with their error.
+func searchTargets(current *search.Engine, project *config.ProjectConfig, global *config.GlobalConfig, projectPath string, slugs []string) ([]search.Target, error) {
+	// Make sure the current project is known to later searches from other projects
+	if global.RegisterProject(project.ProjectSlug, projectPath) {
+		if err := global.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to register project: %v\n", err)
+		}
+	}
+
+	if len(slugs) == 0 {
+		for slug := range global.Projects {
+			slugs = append(slugs, slug)
+		}
+		sort.Strings(slugs)
+	}
+	for _, slug := range slugs {
+		if _, ok := global.Projects[slug]; !ok {
+			known := make([]string, 0, len(global.Projects))
+			for s := range global.Projects {
+				known = append(known, s)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("unknown project: %s (registered: %s)\nHint: Run 'oview up' in the project to register it", slug, strings.Join(known, ", "))
+		}
+	}
+
+	targets := []search.Target{{Name: project.ProjectSlug, Root: projectPath, Engine: current}}
+	for _, slug := range slugs {
+		if slug == project.ProjectSlug {
+			continue
+		}
+		root := global.Projects[slug]
+		target := search.Target{Name: slug, Root: root}
+
+		cfg, err := config.LoadProjectConfig(root)
+		if err != nil {
+			target.Err = fmt.Errorf("failed to load project config: %w", err)
+		} else if engine, err := search.Open(cfg, global); err != nil {
+			target.Err = err
+		} else {
+			target.Engine = engine
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// closeTargets closes the engines opened by searchTargets, leaving the current one open
+func closeTargets(targets []search.Target) {
+	for _, t := range targets[1:] {
+		if t.Engine != nil {
+			t.Engine.Close()
+		}
+	}
+}
+
+// printProjects summarises how each project took part in a cross-project search
+func printProjects(projects []search.ProjectStatus) {
+	fmt.Println("📦 Projects:")
+	for _, p := range projects {
+		if p.Skipped != "" {
+			fmt.Printf("   %-20s skipped: %s\n", p.Name, p.Skipped)
+			continue
+		}
+		fmt.Printf("   %-20s %d results (%s, %s)\n", p.Name, p.Results, p.Mode, p.Model)
+	}
+	fmt.Println()
+}
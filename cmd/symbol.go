@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/yourusername/oview/internal/config"
+	"github.com/yourusername/oview/internal/search"
+)
+
+var (
+	symbolLimit   int
+	symbolFilters search.Filters
+	symbolFormat  string
+)
+
+var symbolCmd = &cobra.Command{
+	Use:   "symbol <name>",
+	Short: "Find where a symbol is defined",
+	Long: `Find the definitions of a class, function, method or key by name or partial name.
+
+Definitions are ranked by exactness: exact name, same name ignoring case,
+member of a class (login matches UserController::login), prefix, substring,
+then trigram similarity for typos. Qualified names may be written
+UserController::login, UserController->login or with their namespace.
+
+  oview symbol UserController::login
+  oview symbol useAuth
+  oview symbol --lang php login
+  vim -q <(oview symbol -f vimgrep login)`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSymbol,
+}
+
+func init() {
+	symbolCmd.Flags().IntVarP(&symbolLimit, "limit", "n", search.DefaultSymbolLimit, "Number of definitions to return")
+	symbolCmd.Flags().StringSliceVarP(&symbolFilters.Types, "type", "t", nil, "Only chunks of these types: code, test, config, doc")
+	symbolCmd.Flags().StringSliceVar(&symbolFilters.Languages, "lang", nil, "Only chunks in these languages (e.g. php, typescript)")
+	symbolCmd.Flags().StringSliceVarP(&symbolFilters.Paths, "path", "p", nil, "Only paths with this prefix or matching this glob")
+	symbolCmd.Flags().StringSliceVarP(&symbolFilters.Exclude, "exclude", "x", nil, "Exclude paths with this prefix or matching this glob")
+	symbolCmd.Flags().StringVarP(&symbolFormat, "format", "f", formatText, "Output format: text, json or vimgrep")
+	rootCmd.AddCommand(symbolCmd)
+}
+
+func runSymbol(cmd *cobra.Command, args []string) error {
+	if symbolFormat != formatText && symbolFormat != formatJSON && symbolFormat != formatVimgrep {
+		return fmt.Errorf("unknown output format: %s (use text, json or vimgrep)", symbolFormat)
+	}
+
+	engine, err := openProjectEngine()
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	name := strings.Join(args, " ")
+	defs, err := engine.FindSymbol(name, symbolLimit, symbolFilters)
+	if err != nil {
+		return fmt.Errorf("failed to find symbol: %w", err)
+	}
+
+	switch symbolFormat {
+	case formatJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{
+			"name":        search.NormalizeSymbol(name),
+			"count":       len(defs),
+			"definitions": definitionsJSON(defs),
+		})
+	case formatVimgrep:
+		for _, d := range defs {
+			line := d.StartLine
+			if line == 0 {
+				line = 1
+			}
+			fmt.Printf("%s:%d:1:%s\n", d.Path, line, d.Signature)
+		}
+		return nil
+	}
+
+	if len(defs) == 0 {
+		fmt.Printf("❌ No definition found for %q\n", search.NormalizeSymbol(name))
+		return nil
+	}
+
+	fmt.Printf("🔤 %d definitions of %q:\n\n", len(defs), search.NormalizeSymbol(name))
+	for i, d := range defs {
+		location := d.Path
+		if d.StartLine > 0 {
+			location = fmt.Sprintf("%s:%d-%d", d.Path, d.StartLine, d.EndLine)
+		}
+		fmt.Printf("%2d. %-40s %s\n", i+1, d.Symbol, location)
+		detail := fmt.Sprintf("%s match", d.Match)
+		if d.Match == search.MatchFuzzy {
+			detail += fmt.Sprintf(" (%.2f)", d.Similarity)
+		}
+		if d.Language != "" {
+			detail += " · " + d.Language
+		}
+		if d.Parts > 1 {
+			detail += fmt.Sprintf(" · %d chunks", d.Parts)
+		}
+		fmt.Printf("    %s\n", detail)
+		if d.Signature != "" {
+			fmt.Printf("    %s\n", d.Signature)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// definitionsJSON formats definitions for the json output
+func definitionsJSON(defs []search.Definition) []map[string]interface{} {
+	out := make([]map[string]interface{}, len(defs))
+	for i, d := range defs {
+		out[i] = map[string]interface{}{
+			"symbol":     d.Symbol,
+			"path":       d.Path,
+			"type":       d.Type,
+			"language":   d.Language,
+			"match":      d.Match,
+			"similarity": d.Similarity,
+			"signature":  d.Signature,
+		}
+		if d.StartLine > 0 {
+			out[i]["start_line"] = d.StartLine
+			out[i]["end_line"] = d.EndLine
+		}
+	}
+	return out
+}
+
+// openProjectEngine opens the search engine of the project in the current directory
+func openProjectEngine() (*search.Engine, error) {
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectConfig, err := config.LoadProjectConfig(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project config: %w\nHint: Run 'oview init' first", err)
+	}
+
+	globalConfig, err := config.LoadGlobalConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load global config: %w", err)
+	}
+
+	return search.Open(projectConfig, globalConfig)
+}
@@ -139,6 +139,13 @@ func runUp(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to save project config: %w", err)
 	}
 
+	// Register the project for cross-project search
+	if globalConfig.RegisterProject(projectConfig.ProjectSlug, projectPath) {
+		if err := globalConfig.Save(); err != nil {
+			return fmt.Errorf("failed to save global config: %w", err)
+		}
+	}
+
 	// Print summary
 	projectDSN := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
 		dbUser, dbPassword, globalConfig.PostgresHost, globalConfig.PostgresPort, dbName)
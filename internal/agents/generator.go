@@ -75,6 +75,95 @@ func (g *Generator) GenerateAll() error {
 	return nil
 }
 
+// Markers around the generated schema section of the DBA agent, the only part
+// of the file UpdateDBA rewrites
+const (
+	schemaStart = "<!-- oview:schema:start (generated by 'oview index', edits inside are replaced) -->"
+	schemaEnd   = "<!-- oview:schema:end -->"
+)
+
+// maxInlineSchema bounds the part of schema.md inlined in the DBA agent, in bytes
+const maxInlineSchema = 8000
+
+// UpdateDBA refreshes the schema section of the DBA agent from the entity model
+// written by 'oview index' to .oview/index/schema.md. The rest of the file is
+// left as the user edited it; a file without the section gets it inserted once,
+// before its last section. Reports whether the file was written.
+func (g *Generator) UpdateDBA() (bool, error) {
+	if !g.stack.Symfony {
+		return false, nil
+	}
+	agentsDir := filepath.Join(g.projectPath, ".oview", "agents")
+	dbaPath := filepath.Join(agentsDir, "dba.md")
+
+	content, err := os.ReadFile(dbaPath)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(agentsDir, 0755); err != nil {
+			return false, fmt.Errorf("failed to create agents directory: %w", err)
+		}
+		content = []byte(g.generateDBA())
+	} else if err != nil {
+		return false, fmt.Errorf("failed to read dba.md: %w", err)
+	} else {
+		text := string(content)
+		start, end := strings.Index(text, schemaStart), strings.Index(text, schemaEnd)
+		if start >= 0 && end > start {
+			content = []byte(text[:start] + g.schemaSection() + text[end+len(schemaEnd):])
+		} else {
+			content = []byte(insertSection(text, g.schemaSection()))
+		}
+	}
+
+	if err := os.WriteFile(dbaPath, content, 0644); err != nil {
+		return false, fmt.Errorf("failed to write dba.md: %w", err)
+	}
+	return true, nil
+}
+
+// insertSection adds a section before the last "## " heading of a Markdown
+// file, or at its end when it has none
+func insertSection(text, section string) string {
+	if i := strings.LastIndex(text, "\n## "); i >= 0 {
+		return text[:i+1] + section + "\n\n" + text[i+1:]
+	}
+	return strings.TrimRight(text, "\n") + "\n\n" + section + "\n"
+}
+
+// schemaSection grounds the DBA agent in the entity model extracted by 'oview
+// index', between the schema markers. Large models are cut at maxInlineSchema,
+// the agent is pointed to describe_entity for the rest.
+func (g *Generator) schemaSection() string {
+	schema, _ := os.ReadFile(filepath.Join(g.projectPath, ".oview", "index", "schema.md"))
+	entities := strings.Split(strings.TrimSpace(string(schema)), "\n\n")
+	if len(entities) == 1 && entities[0] == "" {
+		return schemaStart + `
+## Project Schema
+No entity model yet: run 'oview index' to extract the Doctrine entities, then
+use the describe_entity MCP tool instead of guessing table or column names.
+` + schemaEnd
+	}
+
+	var b strings.Builder
+	shown := 0
+	for _, entity := range entities {
+		if shown > 0 && b.Len()+len(entity) > maxInlineSchema {
+			break
+		}
+		b.WriteString(entity + "\n\n")
+		shown++
+	}
+
+	intro := `Doctrine entities of this project as of the last 'oview index'. Rely on it
+instead of guessing table or column names, and use the describe_entity MCP
+tool for the details of an entity and the associations targeting it.`
+	if shown < len(entities) {
+		intro += fmt.Sprintf(`
+Only %d of the %d entities are listed here: use describe_entity for the others
+(the full model is in .oview/index/schema.md).`, shown, len(entities))
+	}
+	return schemaStart + "\n## Project Schema\n" + intro + "\n\n" + strings.TrimSpace(b.String()) + "\n" + schemaEnd
+}
+
 // Common output schema
 const outputSchema = `
 ## Output Format
@@ -383,6 +472,9 @@ func (g *Generator) generateDBA() string {
 `
 	}
 
+	// Ground the agent in the entity model extracted by 'oview index'
+	skills += "\n" + g.schemaSection() + "\n"
+
 	return fmt.Sprintf(`# Database Administrator Agent
 
 ## Role Mission
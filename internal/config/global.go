@@ -28,6 +28,9 @@ type GlobalConfig struct {
 	// Docker network
 	DockerNetworkName string `yaml:"docker_network_name"`
 
+	// Projects set up with 'oview up', by slug (cross-project search)
+	Projects map[string]string `yaml:"projects,omitempty"` // slug -> project path
+
 	mu sync.RWMutex `yaml:"-"`
 }
 
@@ -173,6 +176,44 @@ func (c *GlobalConfig) GetDSN(database string) string {
 	)
 }
 
+// GetProjectDSN returns the Postgres DSN for a project database, using the project's own credentials
+func (c *GlobalConfig) GetProjectDSN(project *ProjectConfig) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	dbName := project.Database.Name
+	if dbName == "" {
+		dbName = fmt.Sprintf("oview_%s", project.ProjectSlug)
+	}
+	dbUser := project.Database.User
+	if dbUser == "" {
+		dbUser = fmt.Sprintf("oview_%s", project.ProjectSlug)
+	}
+
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
+		dbUser,
+		project.Database.Password,
+		c.PostgresHost,
+		c.PostgresPort,
+		dbName,
+	)
+}
+
+// RegisterProject records the path of a project and reports whether it changed
+func (c *GlobalConfig) RegisterProject(slug, path string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Projects[slug] == path {
+		return false
+	}
+	if c.Projects == nil {
+		c.Projects = make(map[string]string)
+	}
+	c.Projects[slug] = path
+	return true
+}
+
 // generatePassword generates a random password for initial setup
 func generatePassword() string {
 	// For MVP, use a fixed password. In production, should use crypto/rand
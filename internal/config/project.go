@@ -18,6 +18,86 @@ type ProjectConfig struct {
 	Database    DatabaseConfig    `yaml:"database,omitempty"`
 	Embeddings  EmbeddingsConfig  `yaml:"embeddings"`
 	LLM         LLMConfig         `yaml:"llm"`
+	Search      SearchConfig      `yaml:"search"`
+}
+
+// SearchConfig contains retrieval settings used by `oview search` and the MCP server
+type SearchConfig struct {
+	Mode          string       `yaml:"mode"`           // vector, lexical, hybrid
+	VectorWeight  float64      `yaml:"vector_weight"`  // weight of the vector ranking in rank fusion
+	LexicalWeight float64      `yaml:"lexical_weight"` // weight of the lexical ranking in rank fusion
+	RRFK          int          `yaml:"rrf_k"`          // reciprocal rank fusion constant
+	Candidates    int          `yaml:"candidates"`     // candidates fetched per ranking before fusion
+	MMR           bool         `yaml:"mmr"`            // re-rank candidates with maximal marginal relevance
+	MMRLambda     *float64     `yaml:"mmr_lambda"`     // MMR trade-off: 1 = relevance only, 0 = diversity only (default 0.7)
+	MaxPerFile    int          `yaml:"max_per_file"`   // max results from the same file (0 = unlimited)
+	MinSimilarity float64      `yaml:"min_similarity"` // drop vector hits below this cosine similarity (0 = off)
+	RefreshStale  bool         `yaml:"refresh_stale"`  // MCP server: re-index files changed since indexing before answering
+	Rerank        RerankConfig `yaml:"rerank"`
+	QueryCache    CacheConfig  `yaml:"query_cache"`
+}
+
+// RerankConfig configures the optional re-ranking stage applied to the top hits
+type RerankConfig struct {
+	Provider string `yaml:"provider"`           // none, lexical, llm, openai-compatible
+	TopN     int    `yaml:"top_n"`              // hits re-scored (default 20)
+	Model    string `yaml:"model,omitempty"`    // openai-compatible: model name
+	BaseURL  string `yaml:"base_url,omitempty"` // openai-compatible: endpoint, e.g. http://localhost:8000/v1
+	APIKey   string `yaml:"api_key,omitempty"`  // openai-compatible: API key (prefer RERANK_API_KEY env var)
+}
+
+// CacheConfig configures the query embedding cache of the MCP server
+type CacheConfig struct {
+	Size    int  `yaml:"size"`    // embeddings kept in memory (default 256)
+	Persist bool `yaml:"persist"` // also store them in the project database
+}
+
+// DefaultSearchConfig returns the search settings written by `oview init`
+func DefaultSearchConfig() SearchConfig {
+	mmrLambda := 0.7
+	return SearchConfig{
+		Mode:          "hybrid",
+		VectorWeight:  1.0,
+		LexicalWeight: 1.0,
+		RRFK:          60,
+		Candidates:    50,
+		MMR:           true,
+		MMRLambda:     &mmrLambda,
+		MaxPerFile:    2,
+		Rerank:        RerankConfig{Provider: "none", TopN: 20},
+		QueryCache:    CacheConfig{Size: 256, Persist: true},
+	}
+}
+
+// WithDefaults fills unset fields. Projects created before hybrid search
+// existed have no search section and keep the vector-only behaviour.
+func (c SearchConfig) WithDefaults() SearchConfig {
+	defaults := DefaultSearchConfig()
+	if c.Mode == "" {
+		c.Mode = "vector"
+	}
+	if c.VectorWeight <= 0 {
+		c.VectorWeight = defaults.VectorWeight
+	}
+	if c.LexicalWeight <= 0 {
+		c.LexicalWeight = defaults.LexicalWeight
+	}
+	if c.RRFK <= 0 {
+		c.RRFK = defaults.RRFK
+	}
+	if c.Candidates <= 0 {
+		c.Candidates = defaults.Candidates
+	}
+	if c.MMRLambda == nil || *c.MMRLambda < 0 || *c.MMRLambda > 1 {
+		c.MMRLambda = defaults.MMRLambda
+	}
+	if c.Rerank.TopN <= 0 {
+		c.Rerank.TopN = defaults.Rerank.TopN
+	}
+	if c.QueryCache.Size <= 0 {
+		c.QueryCache.Size = defaults.QueryCache.Size
+	}
+	return c
 }
 
 // EmbeddingsConfig contains embeddings configuration
@@ -140,6 +220,9 @@ type ChunkingRules struct {
 	YAML        ChunkRule `yaml:"yaml"`
 	Makefile    ChunkRule `yaml:"makefile"`
 	Docker      ChunkRule `yaml:"docker"`
+	JSON        ChunkRule `yaml:"json"`
+	XML         ChunkRule `yaml:"xml"`
+	SQL         ChunkRule `yaml:"sql"`
 	Generic     ChunkRule `yaml:"generic"`
 }
 
@@ -153,9 +236,39 @@ type ChunkRule struct {
 
 // IndexingRules defines what to index
 type IndexingRules struct {
-	IncludePaths []string `yaml:"include_paths"`
-	ExcludePaths []string `yaml:"exclude_paths"`
-	Extensions   []string `yaml:"extensions"`
+	IncludePaths []string    `yaml:"include_paths"`
+	ExcludePaths []string    `yaml:"exclude_paths"`
+	Extensions   []string    `yaml:"extensions"`
+	Filters      FileFilters `yaml:"filters"`
+}
+
+// FileFilters defines which files are skipped even if their extension is allowed
+type FileFilters struct {
+	MaxFileSize      int64    `yaml:"max_file_size"`       // max file size in bytes (0 = default)
+	MaxAvgLineLength int      `yaml:"max_avg_line_length"` // above this, a file is considered minified (0 = default)
+	IndexLockfiles   bool     `yaml:"index_lockfiles"`     // index composer.lock, package-lock.json, etc.
+	Lockfiles        []string `yaml:"lockfiles,omitempty"` // lockfile basenames (empty = default list)
+}
+
+// Default file filter values
+const (
+	DefaultMaxFileSize      int64 = 512 * 1024
+	DefaultMaxAvgLineLength       = 300
+)
+
+// DefaultLockfiles returns the lockfile basenames skipped by default
+func DefaultLockfiles() []string {
+	return []string{
+		"composer.lock",
+		"package-lock.json",
+		"yarn.lock",
+		"pnpm-lock.yaml",
+		"symfony.lock",
+		"Gemfile.lock",
+		"poetry.lock",
+		"Cargo.lock",
+		"go.sum",
+	}
 }
 
 // DefaultRAGConfig returns a RAG config with sensible defaults
@@ -198,6 +311,24 @@ func DefaultRAGConfig() *RAGConfig {
 				MaxTokens: 300,
 				Overlap:   50,
 			},
+			JSON: ChunkRule{
+				Strategy:  "section",
+				MaxSize:   1500,
+				MaxTokens: 400,
+				Overlap:   0,
+			},
+			XML: ChunkRule{
+				Strategy:  "element",
+				MaxSize:   1500,
+				MaxTokens: 400,
+				Overlap:   0,
+			},
+			SQL: ChunkRule{
+				Strategy:  "statement",
+				MaxSize:   2000,
+				MaxTokens: 500,
+				Overlap:   0,
+			},
 			Generic: ChunkRule{
 				Strategy:  "size",
 				MaxSize:   1500,
@@ -212,6 +343,7 @@ func DefaultRAGConfig() *RAGConfig {
 				"templates/",
 				"assets/",
 				"tests/",
+				"migrations/",
 				"Makefile",
 				"docker-compose.yml",
 				"compose.yaml",
@@ -227,12 +359,63 @@ func DefaultRAGConfig() *RAGConfig {
 			},
 			Extensions: []string{
 				".php", ".twig", ".yaml", ".yml", ".js", ".ts",
-				".jsx", ".tsx", ".json", ".md", ".txt",
+				".jsx", ".tsx", ".json", ".md", ".txt", ".sql",
+			},
+			Filters: FileFilters{
+				MaxFileSize:      DefaultMaxFileSize,
+				MaxAvgLineLength: DefaultMaxAvgLineLength,
+				IndexLockfiles:   false,
+				Lockfiles:        DefaultLockfiles(),
 			},
 		},
 	}
 }
 
+// DefaultRAGConfigForStack returns the default RAG config adjusted to the detected stack
+func DefaultRAGConfigForStack(stack *StackInfo) *RAGConfig {
+	cfg := DefaultRAGConfig()
+	if stack == nil {
+		return cfg
+	}
+
+	if stack.Symfony {
+		// services.xml, routing.xml, Doctrine *.orm.xml mappings and PHPUnit config
+		cfg.Indexing.Extensions = appendMissing(cfg.Indexing.Extensions, ".xml")
+		cfg.Indexing.IncludePaths = appendMissing(cfg.Indexing.IncludePaths, "phpunit.xml.dist", "phpunit.xml")
+	}
+
+	// Single-file components
+	sfcExtensions := map[string]string{
+		"Vue":    ".vue",
+		"Svelte": ".svelte",
+		"Astro":  ".astro",
+	}
+	for _, framework := range stack.Frontend.Frameworks {
+		if ext, ok := sfcExtensions[framework]; ok {
+			cfg.Indexing.Extensions = appendMissing(cfg.Indexing.Extensions, ext)
+		}
+	}
+
+	return cfg
+}
+
+// appendMissing appends the values that are not already present in the slice
+func appendMissing(slice []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range slice {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			slice = append(slice, v)
+		}
+	}
+	return slice
+}
+
 // SaveRAGConfig saves the RAG config to .oview/rag.yaml
 func SaveRAGConfig(projectPath string, config *RAGConfig) error {
 	configPath := filepath.Join(projectPath, ".oview", "rag.yaml")
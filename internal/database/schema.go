@@ -9,8 +9,9 @@ func GetSchemaSQL(embeddingDim int) string {
 	}
 
 	return fmt.Sprintf(`
--- Create extension if not exists
+-- Create extensions if not exists
 CREATE EXTENSION IF NOT EXISTS vector;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
 
 -- Chunks table for storing code/doc chunks with embeddings
 CREATE TABLE IF NOT EXISTS chunks (
@@ -51,12 +52,117 @@ CREATE INDEX IF NOT EXISTS idx_chunks_type ON chunks(type);
 CREATE INDEX IF NOT EXISTS idx_chunks_path ON chunks(path);
 CREATE INDEX IF NOT EXISTS idx_chunks_source ON chunks(source);
 CREATE INDEX IF NOT EXISTS idx_chunks_symbol ON chunks(symbol);
+CREATE INDEX IF NOT EXISTS idx_chunks_language ON chunks(language);
+CREATE INDEX IF NOT EXISTS idx_chunks_component ON chunks(component);
 CREATE INDEX IF NOT EXISTS idx_chunks_commit ON chunks(commit_sha);
 CREATE INDEX IF NOT EXISTS idx_chunks_metadata ON chunks USING gin(metadata);
 
 -- Vector similarity index (using HNSW for better performance)
 CREATE INDEX IF NOT EXISTS idx_chunks_embedding ON chunks USING hnsw (embedding vector_cosine_ops);
 
+-- Lexical search: full-text vector over symbol + content, trigram indexes for identifiers
+-- (added with ALTER so that existing databases are upgraded by 'oview up')
+ALTER TABLE chunks ADD COLUMN IF NOT EXISTS content_tsv tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', COALESCE(symbol, '') || ' ' || content)) STORED;
+CREATE INDEX IF NOT EXISTS idx_chunks_content_tsv ON chunks USING gin(content_tsv);
+CREATE INDEX IF NOT EXISTS idx_chunks_content_trgm ON chunks USING gin(content gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_chunks_symbol_trgm ON chunks USING gin(symbol gin_trgm_ops);
+
+-- Language filters compare case-insensitively
+CREATE INDEX IF NOT EXISTS idx_chunks_language_lower ON chunks(LOWER(language));
+
+-- Line range of each chunk in its source file (neighbour expansion)
+ALTER TABLE chunks ADD COLUMN IF NOT EXISTS start_line INTEGER;
+ALTER TABLE chunks ADD COLUMN IF NOT EXISTS end_line INTEGER;
+CREATE INDEX IF NOT EXISTS idx_chunks_path_lines ON chunks(project_id, path, start_line);
+
+-- Query embeddings cached by the MCP server, keyed by model and normalised query
+CREATE TABLE IF NOT EXISTS query_embeddings (
+    project_id VARCHAR(255) NOT NULL,
+    model VARCHAR(255) NOT NULL,
+    query TEXT NOT NULL,
+    embedding vector(%d) NOT NULL,
+    hits INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, model, query)
+);
+CREATE INDEX IF NOT EXISTS idx_query_embeddings_last_used ON query_embeddings(project_id, model, last_used_at);
+
+-- Identifier inverted index: the identifiers each chunk references, by line (find_references)
+CREATE TABLE IF NOT EXISTS identifiers (
+    chunk_id INTEGER NOT NULL REFERENCES chunks(id) ON DELETE CASCADE,
+    project_id VARCHAR(255) NOT NULL,
+    identifier VARCHAR(255) NOT NULL,
+    line INTEGER NOT NULL,             -- line within the chunk content, 0-based
+    kind VARCHAR(20) NOT NULL,         -- 'definition' or 'reference'
+    PRIMARY KEY (chunk_id, identifier, line)
+);
+CREATE INDEX IF NOT EXISTS idx_identifiers_lookup ON identifiers(project_id, identifier);
+CREATE INDEX IF NOT EXISTS idx_identifiers_lookup_lower ON identifiers(project_id, LOWER(identifier));
+
+-- Dependency graph between files: PHP use, JS/TS import/require, Twig extends/include,
+-- controller renders and Symfony service references (related_files, oview deps)
+CREATE TABLE IF NOT EXISTS edges (
+    id SERIAL PRIMARY KEY,
+    project_id VARCHAR(255) NOT NULL,
+    from_path TEXT NOT NULL,
+    to_path TEXT,                      -- NULL when the target is outside the project (vendor, npm package)
+    target TEXT NOT NULL,              -- as written: class, module, template or @service id
+    kind VARCHAR(20) NOT NULL,         -- 'use', 'import', 'require', 'extends', 'include', 'render', 'service'
+    line INTEGER
+);
+CREATE INDEX IF NOT EXISTS idx_edges_from ON edges(project_id, from_path);
+CREATE INDEX IF NOT EXISTS idx_edges_to ON edges(project_id, to_path);
+
+-- Symfony routes from controller attributes and annotations and config/routes*.yaml|xml
+-- (find_route, oview routes)
+CREATE TABLE IF NOT EXISTS routes (
+    id SERIAL PRIMARY KEY,
+    project_id VARCHAR(255) NOT NULL,
+    name TEXT NOT NULL,                -- empty when the route is not named explicitly
+    path TEXT NOT NULL,                -- as declared: /api/users/{id}
+    methods TEXT NOT NULL,             -- 'GET|POST', empty for any method
+    controller TEXT NOT NULL,          -- Class::action
+    controller_path TEXT,              -- file of the controller, NULL when unresolved
+    file TEXT NOT NULL,                -- file declaring the route
+    line INTEGER,
+    source VARCHAR(20) NOT NULL        -- 'attribute', 'annotation', 'yaml', 'xml'
+);
+CREATE INDEX IF NOT EXISTS idx_routes_project ON routes(project_id);
+
+-- Doctrine entities from attributes, annotations and XML/YAML mappings
+-- (describe_entity, oview entities, DBA agent schema)
+CREATE TABLE IF NOT EXISTS entities (
+    id SERIAL PRIMARY KEY,
+    project_id VARCHAR(255) NOT NULL,
+    class TEXT NOT NULL,               -- App\Entity\User
+    name VARCHAR(255) NOT NULL,        -- short class name
+    table_name TEXT NOT NULL,
+    repository TEXT NOT NULL,          -- repository class, empty when none
+    file TEXT NOT NULL,
+    line INTEGER,
+    source VARCHAR(20) NOT NULL        -- 'attribute', 'annotation', 'xml', 'yaml'
+);
+CREATE INDEX IF NOT EXISTS idx_entities_project ON entities(project_id, name);
+
+CREATE TABLE IF NOT EXISTS entity_fields (
+    entity_id INTEGER NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,         -- declaration order
+    name VARCHAR(255) NOT NULL,        -- property
+    column_name TEXT NOT NULL,         -- join column for owning associations, empty for inverse sides
+    type TEXT NOT NULL,                -- Doctrine type, empty for associations
+    length INTEGER,
+    nullable BOOLEAN NOT NULL,
+    is_id BOOLEAN NOT NULL,
+    relation VARCHAR(20) NOT NULL,     -- 'ManyToOne', 'OneToMany', 'ManyToMany', 'OneToOne', 'Embedded', empty for columns
+    target_entity TEXT NOT NULL,
+    mapped_by TEXT NOT NULL,
+    inversed_by TEXT NOT NULL,
+    line INTEGER,
+    PRIMARY KEY (entity_id, position)
+);
+
 -- Trigger to update updated_at
 CREATE OR REPLACE FUNCTION update_updated_at_column()
 RETURNS TRIGGER AS $$
@@ -69,5 +175,5 @@ $$ language 'plpgsql';
 DROP TRIGGER IF EXISTS update_chunks_updated_at ON chunks;
 CREATE TRIGGER update_chunks_updated_at BEFORE UPDATE ON chunks
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
-`, embeddingDim)
+`, embeddingDim, embeddingDim)
 }
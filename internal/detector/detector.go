@@ -169,15 +169,18 @@ func (d *Detector) detectFrontend() config.FrontendInfo {
 	if deps["react"] || deps["react-dom"] {
 		info.Frameworks = append(info.Frameworks, "React")
 	}
-	if deps["vue"] {
+	if deps["vue"] || deps["nuxt"] {
 		info.Frameworks = append(info.Frameworks, "Vue")
 	}
 	if deps["@angular/core"] {
 		info.Frameworks = append(info.Frameworks, "Angular")
 	}
-	if deps["svelte"] {
+	if deps["svelte"] || deps["@sveltejs/kit"] {
 		info.Frameworks = append(info.Frameworks, "Svelte")
 	}
+	if deps["astro"] {
+		info.Frameworks = append(info.Frameworks, "Astro")
+	}
 
 	// Detect build tools
 	if deps["webpack"] || deps["@symfony/webpack-encore"] {
@@ -1,5 +1,12 @@
 package embeddings
 
+import (
+	"fmt"
+	"os"
+
+	"github.com/yourusername/oview/internal/config"
+)
+
 // Generator generates embeddings for text
 type Generator interface {
 	// Embed generates an embedding vector for the given text
@@ -11,3 +18,32 @@ type Generator interface {
 	// Name returns the name of the embedding model
 	Name() string
 }
+
+// NewGenerator creates the generator configured in project.yaml.
+// For OpenAI, the api_key of the project takes precedence over OPENAI_API_KEY.
+func NewGenerator(cfg config.EmbeddingsConfig) (Generator, error) {
+	switch cfg.Provider {
+	case "openai":
+		apiKey := cfg.APIKey
+		if apiKey == "" {
+			apiKey = os.Getenv("OPENAI_API_KEY")
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("OpenAI API key required. Set in .oview/project.yaml or OPENAI_API_KEY environment variable")
+		}
+		return NewOpenAIGenerator(apiKey, cfg.Model), nil
+
+	case "ollama":
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = "http://localhost:11434"
+		}
+		return NewOllamaGenerator(baseURL, cfg.Model), nil
+
+	case "stub":
+		return NewStubGenerator(cfg.Dim), nil
+
+	default:
+		return nil, fmt.Errorf("unknown embeddings provider: %s (edit .oview/project.yaml)", cfg.Provider)
+	}
+}
@@ -17,7 +17,10 @@ type Chunk struct {
 	Symbol    string // function/class name if applicable
 	Component string // component/module name
 	Content   string
-	Type      string // code, doc, config, test
+	Type      string            // code, doc, config, test
+	Metadata  map[string]string // extra chunker-specific metadata (e.g. migration version)
+	StartLine int               // first line in the source file (0 if unknown)
+	EndLine   int               // last line in the source file (0 if unknown)
 }
 
 // Chunker chunks files based on rules
@@ -37,6 +40,8 @@ func (c *Chunker) ChunkFile(path string, content []byte) ([]Chunk, error) {
 
 	// Determine file type
 	switch {
+	case ext == ".php" && isDoctrineMigration(path, content):
+		return c.chunkMigration(path, string(content))
 	case ext == ".php":
 		return c.chunkPHP(path, string(content))
 	case ext == ".twig":
@@ -49,6 +54,14 @@ func (c *Chunker) ChunkFile(path string, content []byte) ([]Chunk, error) {
 		return c.chunkDockerCompose(path, string(content))
 	case ext == ".js" || ext == ".ts" || ext == ".jsx" || ext == ".tsx":
 		return c.chunkJavaScript(path, string(content))
+	case ext == ".vue" || ext == ".svelte" || ext == ".astro":
+		return c.chunkSFC(path, string(content))
+	case ext == ".json":
+		return c.chunkJSON(path, string(content))
+	case ext == ".xml" || strings.HasSuffix(basename, ".xml.dist"):
+		return c.chunkXML(path, string(content))
+	case ext == ".sql":
+		return c.chunkSQL(path, string(content))
 	case ext == ".md" || ext == ".txt":
 		return c.chunkDocument(path, string(content))
 	default:
@@ -454,6 +467,18 @@ func (c *Chunker) chunkBySize(path, content string, maxSize int, language, fileT
 	return chunks, nil
 }
 
+// maxSize returns the max size of a rule, falling back to the generic rule
+// for rag.yaml files written before the rule existed
+func (c *Chunker) maxSize(rule config.ChunkRule) int {
+	if rule.MaxSize > 0 {
+		return rule.MaxSize
+	}
+	if c.rules.Chunking.Generic.MaxSize > 0 {
+		return c.rules.Chunking.Generic.MaxSize
+	}
+	return 1500
+}
+
 // Helper functions
 
 func getComponent(path string) string {
@@ -507,12 +532,20 @@ func detectLanguage(path string) string {
 		return "JSON"
 	case ".xml":
 		return "XML"
+	case ".sql":
+		return "SQL"
 	case ".md":
 		return "Markdown"
 	case ".html", ".twig":
 		return "HTML"
 	case ".css", ".scss", ".sass":
 		return "CSS"
+	case ".vue":
+		return "Vue"
+	case ".svelte":
+		return "Svelte"
+	case ".astro":
+		return "Astro"
 	default:
 		return "Unknown"
 	}
@@ -0,0 +1,408 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// jsonMember is a key/value pair of a JSON object, kept in document order
+type jsonMember struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// openAPIMethods lists the HTTP methods that can appear in an OpenAPI path item
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// manifestDependencyKeys lists the dependency sections of known package manifests
+var manifestDependencyKeys = map[string][]string{
+	"composer.json": {"require", "require-dev", "conflict", "replace", "provide"},
+	"package.json":  {"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"},
+}
+
+// chunkJSON chunks JSON files by top-level keys, with dedicated handling
+// for OpenAPI/Swagger specs and package manifests
+func (c *Chunker) chunkJSON(path string, content string) ([]Chunk, error) {
+	maxSize := c.maxSize(c.rules.Chunking.JSON)
+
+	members, err := parseJSONObject([]byte(content))
+	if err != nil {
+		// Not a JSON object (array, invalid JSON, JSON lines...): fall back to size-based chunking
+		return c.chunkBySize(path, content, maxSize, "JSON", getFileType(path))
+	}
+
+	if isOpenAPI(members) {
+		return c.chunkOpenAPI(path, members, maxSize)
+	}
+
+	if depKeys, ok := manifestDependencyKeys[filepath.Base(path)]; ok {
+		return c.chunkManifest(path, members, depKeys, maxSize)
+	}
+
+	if len(content) <= maxSize {
+		return []Chunk{c.jsonChunk(path, "", content)}, nil
+	}
+
+	return c.chunkJSONMembers(path, "", members, maxSize)
+}
+
+// chunkJSONMembers emits one chunk per member, descending into objects that are too large.
+// Scalar members are grouped into a single chunk named after the parent key path.
+func (c *Chunker) chunkJSONMembers(path, prefix string, members []jsonMember, maxSize int) ([]Chunk, error) {
+	chunks := []Chunk{}
+	var scalars []string
+
+	for _, m := range members {
+		keyPath := joinKeyPath(prefix, m.Key)
+		body := formatJSONMember(m.Key, m.Value)
+
+		if isJSONScalar(m.Value) {
+			scalars = append(scalars, body)
+			continue
+		}
+
+		if len(body) <= maxSize {
+			chunks = append(chunks, c.jsonChunk(path, keyPath, body))
+			continue
+		}
+
+		// Too large: split nested objects by key, everything else by size
+		if nested, err := parseJSONObject(m.Value); err == nil && len(nested) > 0 {
+			sub, err := c.chunkJSONMembers(path, keyPath, nested, maxSize)
+			if err != nil {
+				return nil, err
+			}
+			chunks = append(chunks, sub...)
+			continue
+		}
+
+		sub, err := c.chunkBySize(path, body, maxSize, "JSON", getFileType(path))
+		if err != nil {
+			return nil, err
+		}
+		for k, sc := range sub {
+			sc.Symbol = fmt.Sprintf("%s#%d", keyPath, k)
+			chunks = append(chunks, sc)
+		}
+	}
+
+	if len(scalars) > 0 {
+		sub, err := c.sizedJSONChunks(path, prefix, strings.Join(scalars, "\n"), maxSize)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(sub, chunks...)
+	}
+
+	return chunks, nil
+}
+
+// chunkOpenAPI emits one chunk per operation and per schema component
+func (c *Chunker) chunkOpenAPI(path string, members []jsonMember, maxSize int) ([]Chunk, error) {
+	chunks := []Chunk{}
+	var rest []jsonMember
+
+	for _, m := range members {
+		switch m.Key {
+		case "paths":
+			ops, err := c.chunkOpenAPIPaths(path, m.Value, maxSize)
+			if err != nil {
+				return nil, err
+			}
+			chunks = append(chunks, ops...)
+
+		case "components":
+			// OpenAPI 3: components.schemas, components.securitySchemes, ...
+			sections, err := parseJSONObject(m.Value)
+			if err != nil {
+				rest = append(rest, m)
+				continue
+			}
+			for _, section := range sections {
+				if section.Key == "schemas" {
+					schemas, err := c.chunkOpenAPISchemas(path, "components.schemas", section.Value, maxSize)
+					if err != nil {
+						return nil, err
+					}
+					chunks = append(chunks, schemas...)
+					continue
+				}
+				sub, err := c.chunkJSONMembers(path, "components", []jsonMember{section}, maxSize)
+				if err != nil {
+					return nil, err
+				}
+				chunks = append(chunks, sub...)
+			}
+
+		case "definitions":
+			// Swagger 2: top-level definitions
+			schemas, err := c.chunkOpenAPISchemas(path, "definitions", m.Value, maxSize)
+			if err != nil {
+				return nil, err
+			}
+			chunks = append(chunks, schemas...)
+
+		default:
+			rest = append(rest, m)
+		}
+	}
+
+	// info, servers, tags, security... are small: keep them together when possible
+	if len(rest) > 0 {
+		sub, err := c.chunkJSONMembers(path, "", rest, maxSize)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, sub...)
+	}
+
+	return chunks, nil
+}
+
+// chunkOpenAPIPaths emits one chunk per operation, e.g. "GET /users/{id}"
+func (c *Chunker) chunkOpenAPIPaths(path string, raw json.RawMessage, maxSize int) ([]Chunk, error) {
+	pathItems, err := parseJSONObject(raw)
+	if err != nil {
+		return c.chunkJSONMembers(path, "", []jsonMember{{Key: "paths", Value: raw}}, maxSize)
+	}
+
+	chunks := []Chunk{}
+	for _, item := range pathItems {
+		fields, err := parseJSONObject(item.Value)
+		if err != nil {
+			continue
+		}
+
+		// Path-level parameters apply to every operation of the path
+		var shared []string
+		for _, f := range fields {
+			if f.Key == "parameters" || f.Key == "summary" || f.Key == "description" {
+				shared = append(shared, formatJSONMember(f.Key, f.Value))
+			}
+		}
+
+		for _, f := range fields {
+			if !isOpenAPIMethod(f.Key) {
+				continue
+			}
+			symbol := fmt.Sprintf("%s %s", strings.ToUpper(f.Key), item.Key)
+
+			var body strings.Builder
+			body.WriteString(symbol + "\n")
+			for _, s := range shared {
+				body.WriteString(s + "\n")
+			}
+			body.WriteString(formatJSON(f.Value))
+
+			sub, err := c.sizedJSONChunks(path, symbol, body.String(), maxSize)
+			if err != nil {
+				return nil, err
+			}
+			chunks = append(chunks, sub...)
+		}
+	}
+
+	return chunks, nil
+}
+
+// chunkOpenAPISchemas emits one chunk per schema component
+func (c *Chunker) chunkOpenAPISchemas(path, prefix string, raw json.RawMessage, maxSize int) ([]Chunk, error) {
+	schemas, err := parseJSONObject(raw)
+	if err != nil {
+		return c.chunkJSONMembers(path, "", []jsonMember{{Key: prefix, Value: raw}}, maxSize)
+	}
+
+	chunks := []Chunk{}
+	for _, schema := range schemas {
+		symbol := joinKeyPath(prefix, schema.Key)
+		sub, err := c.sizedJSONChunks(path, symbol, formatJSONMember(schema.Key, schema.Value), maxSize)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, sub...)
+	}
+
+	return chunks, nil
+}
+
+// chunkManifest summarises dependencies of composer.json/package.json into one chunk,
+// then chunks the remaining keys normally
+func (c *Chunker) chunkManifest(path string, members []jsonMember, depKeys []string, maxSize int) ([]Chunk, error) {
+	isDep := make(map[string]bool)
+	for _, k := range depKeys {
+		isDep[k] = true
+	}
+
+	var summary strings.Builder
+	var rest []jsonMember
+
+	summary.WriteString(fmt.Sprintf("Dependencies of %s\n", path))
+	for _, m := range members {
+		switch {
+		case m.Key == "name" || m.Key == "description" || m.Key == "version" || m.Key == "type":
+			var value string
+			if err := json.Unmarshal(m.Value, &value); err == nil && value != "" {
+				summary.WriteString(fmt.Sprintf("%s: %s\n", m.Key, value))
+			}
+			rest = append(rest, m)
+
+		case isDep[m.Key]:
+			var deps map[string]string
+			if err := json.Unmarshal(m.Value, &deps); err != nil {
+				rest = append(rest, m)
+				continue
+			}
+			names := make([]string, 0, len(deps))
+			for name := range deps {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			summary.WriteString(fmt.Sprintf("\n%s (%d):\n", m.Key, len(names)))
+			for _, name := range names {
+				summary.WriteString(fmt.Sprintf("  %s %s\n", name, deps[name]))
+			}
+
+		default:
+			rest = append(rest, m)
+		}
+	}
+
+	chunks, err := c.sizedJSONChunks(path, "dependencies", strings.TrimSpace(summary.String()), maxSize)
+	if err != nil {
+		return nil, err
+	}
+	for i := range chunks {
+		chunks[i].Type = "config"
+	}
+
+	if len(rest) > 0 {
+		sub, err := c.chunkJSONMembers(path, "", rest, maxSize)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, sub...)
+	}
+
+	return chunks, nil
+}
+
+// sizedJSONChunks returns a single chunk with the given symbol, split by size if needed
+func (c *Chunker) sizedJSONChunks(path, symbol, body string, maxSize int) ([]Chunk, error) {
+	if len(body) <= maxSize {
+		return []Chunk{c.jsonChunk(path, symbol, body)}, nil
+	}
+
+	sub, err := c.chunkBySize(path, body, maxSize, "JSON", getFileType(path))
+	if err != nil {
+		return nil, err
+	}
+	for k := range sub {
+		sub[k].Symbol = fmt.Sprintf("%s#%d", symbol, k)
+	}
+	return sub, nil
+}
+
+// jsonChunk builds a JSON chunk
+func (c *Chunker) jsonChunk(path, symbol, content string) Chunk {
+	return Chunk{
+		Path:      path,
+		Language:  "JSON",
+		Symbol:    symbol,
+		Component: getComponent(path),
+		Content:   strings.TrimSpace(content),
+		Type:      getFileType(path),
+	}
+}
+
+// parseJSONObject decodes the members of a JSON object, preserving key order
+func parseJSONObject(data []byte) ([]jsonMember, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("not a JSON object")
+	}
+
+	members := []jsonMember{}
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := keyTok.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid object key")
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		members = append(members, jsonMember{Key: key, Value: value})
+	}
+
+	// Consume closing brace
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// isOpenAPI reports whether the document is an OpenAPI 3 or Swagger 2 spec
+func isOpenAPI(members []jsonMember) bool {
+	hasVersion, hasPaths := false, false
+	for _, m := range members {
+		switch m.Key {
+		case "openapi", "swagger":
+			hasVersion = true
+		case "paths":
+			hasPaths = true
+		}
+	}
+	return hasVersion && hasPaths
+}
+
+// isJSONScalar reports whether a raw value is a string, number, boolean or null
+func isJSONScalar(value json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(value)
+	return len(trimmed) > 0 && trimmed[0] != '{' && trimmed[0] != '['
+}
+
+func isOpenAPIMethod(key string) bool {
+	for _, method := range openAPIMethods {
+		if key == method {
+			return true
+		}
+	}
+	return false
+}
+
+// formatJSONMember renders `"key": value` with normalised indentation
+func formatJSONMember(key string, value json.RawMessage) string {
+	return fmt.Sprintf("%q: %s", key, formatJSON(value))
+}
+
+// formatJSON re-indents a raw JSON value, falling back to the raw bytes
+func formatJSON(value json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, value, "", "  "); err != nil {
+		return string(value)
+	}
+	return buf.String()
+}
+
+func joinKeyPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,200 @@
+package indexer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/oview/internal/config"
+)
+
+// testChunker returns a chunker splitting every file type at maxSize
+func testChunker(maxSize int) *Chunker {
+	return NewChunker(&config.RAGConfig{
+		Chunking: config.ChunkingRules{Generic: config.ChunkRule{MaxSize: maxSize}},
+	})
+}
+
+// chunkSymbols lists the symbol of each chunk
+func chunkSymbols(chunks []Chunk) []string {
+	symbols := make([]string, len(chunks))
+	for i, c := range chunks {
+		symbols[i] = c.Symbol
+	}
+	return symbols
+}
+
+func TestChunkJSONOpenAPI(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.3",
+  "info": {"title": "Shop API", "version": "1.2.0"},
+  "paths": {
+    "/users": {
+      "get": {"summary": "List users"},
+      "post": {"summary": "Create a user"}
+    },
+    "/users/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true}],
+      "get": {"summary": "Show a user"},
+      "x-internal": true
+    }
+  },
+  "components": {
+    "schemas": {
+      "User": {"type": "object", "properties": {"email": {"type": "string"}}},
+      "Error": {"type": "object"}
+    },
+    "securitySchemes": {"bearer": {"type": "http", "scheme": "bearer"}}
+  }
+}`
+	chunks, err := testChunker(1500).ChunkFile("docs/openapi.json", []byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"GET /users",
+		"POST /users",
+		"GET /users/{id}",
+		"components.schemas.User",
+		"components.schemas.Error",
+		"components.securitySchemes",
+		"",
+		"info",
+	}
+	if got := chunkSymbols(chunks); !reflect.DeepEqual(got, want) {
+		t.Fatalf("symbols = %q, want %q", got, want)
+	}
+
+	// Path-level parameters are repeated in each operation of the path
+	show := chunks[2].Content
+	if !strings.HasPrefix(show, "GET /users/{id}\n") || !strings.Contains(show, `"parameters"`) || !strings.Contains(show, "Show a user") {
+		t.Errorf("GET /users/{id} chunk misses its header or path parameters:\n%s", show)
+	}
+	if strings.Contains(show, "x-internal") {
+		t.Errorf("GET /users/{id} chunk includes path extensions:\n%s", show)
+	}
+}
+
+func TestChunkJSONSwaggerDefinitions(t *testing.T) {
+	spec := `{"swagger": "2.0", "paths": {"/ping": {"head": {}}}, "definitions": {"Pong": {"type": "string"}}}`
+	chunks, err := testChunker(1500).ChunkFile("api/swagger.json", []byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"HEAD /ping", "definitions.Pong", ""}
+	if got := chunkSymbols(chunks); !reflect.DeepEqual(got, want) {
+		t.Errorf("symbols = %q, want %q", got, want)
+	}
+}
+
+func TestChunkJSONManifest(t *testing.T) {
+	manifest := `{
+  "name": "acme/shop",
+  "type": "project",
+  "require": {"symfony/framework-bundle": "^7.1", "php": ">=8.2", "doctrine/orm": "^3.0"},
+  "require-dev": {"phpunit/phpunit": "^11.0"},
+  "autoload": {"psr-4": {"App\\": "src/"}}
+}`
+	chunks, err := testChunker(1500).ChunkFile("composer.json", []byte(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) == 0 || chunks[0].Symbol != "dependencies" {
+		t.Fatalf("first chunk = %q, want the dependencies summary", chunkSymbols(chunks))
+	}
+
+	summary := chunks[0]
+	if summary.Type != "config" {
+		t.Errorf("summary type = %q, want config", summary.Type)
+	}
+	wantSummary := `Dependencies of composer.json
+name: acme/shop
+type: project
+
+require (3):
+  doctrine/orm ^3.0
+  php >=8.2
+  symfony/framework-bundle ^7.1
+
+require-dev (1):
+  phpunit/phpunit ^11.0`
+	if summary.Content != wantSummary {
+		t.Errorf("summary =\n%s\nwant\n%s", summary.Content, wantSummary)
+	}
+
+	// name and type stay searchable with the other keys, dependency sections don't
+	rest := strings.Join(chunkSymbols(chunks[1:]), ",")
+	if rest != ",autoload" {
+		t.Errorf("other chunks = %q, want the scalars and autoload", rest)
+	}
+}
+
+func TestChunkJSONMembers(t *testing.T) {
+	doc := `{
+  "debug": false,
+  "locale": "en",
+  "mailer": {"dsn": "smtp://localhost", "from": "noreply@example.com"},
+  "cache": {
+    "pools": {"app": {"adapter": "cache.adapter.redis", "default_lifetime": 3600}},
+    "prefix": "shop"
+  }
+}`
+
+	t.Run("small file is one chunk", func(t *testing.T) {
+		chunks, err := testChunker(1500).ChunkFile("config/app.json", []byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(chunks) != 1 || chunks[0].Symbol != "" || chunks[0].Content != strings.TrimSpace(doc) {
+			t.Errorf("chunks = %+v, want the whole file", chunks)
+		}
+	})
+
+	t.Run("large objects are split by key", func(t *testing.T) {
+		chunks, err := testChunker(100).ChunkFile("config/app.json", []byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"", "mailer", "cache", "cache.pools"}
+		if got := chunkSymbols(chunks); !reflect.DeepEqual(got, want) {
+			t.Fatalf("symbols = %q, want %q", got, want)
+		}
+		if chunks[0].Content != "\"debug\": false\n\"locale\": \"en\"" {
+			t.Errorf("scalars chunk = %q", chunks[0].Content)
+		}
+		if chunks[2].Content != `"prefix": "shop"` {
+			t.Errorf("cache scalars chunk = %q", chunks[2].Content)
+		}
+	})
+
+	t.Run("arrays fall back to size-based chunking", func(t *testing.T) {
+		chunks, err := testChunker(1500).ChunkFile("fixtures/users.json", []byte(`[{"id": 1}, {"id": 2}]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(chunks) != 1 || chunks[0].Language != "JSON" || chunks[0].Symbol != "" {
+			t.Errorf("chunks = %+v, want one unnamed JSON chunk", chunks)
+		}
+	})
+}
+
+func TestParseJSONObjectKeepsKeyOrder(t *testing.T) {
+	members, err := parseJSONObject([]byte(`{"b": 1, "a": {"nested": true}, "c": [1, 2]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, m := range members {
+		keys = append(keys, m.Key)
+	}
+	if want := []string{"b", "a", "c"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %q, want %q", keys, want)
+	}
+
+	for _, invalid := range []string{`[1, 2]`, `"text"`, `{"a": }`, ``} {
+		if _, err := parseJSONObject([]byte(invalid)); err == nil {
+			t.Errorf("parseJSONObject(%q) succeeded, want an error", invalid)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package indexer
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	sfcScriptRegex      = regexp.MustCompile(`(?is)<script(\s[^>]*)?>(.*?)</script>`)
+	sfcStyleRegex       = regexp.MustCompile(`(?is)<style(\s[^>]*)?>(.*?)</style>`)
+	sfcTemplateTagRegex = regexp.MustCompile(`(?i)<(/?)template(?:\s[^>]*)?>`)
+	sfcLangRegex        = regexp.MustCompile(`(?i)\blang\s*=\s*["']?(\w+)`)
+	sfcSetupRegex       = regexp.MustCompile(`(?i)\bsetup\b`)
+	astroFrontmatter    = regexp.MustCompile(`(?s)\A\s*---\r?\n(.*?)\r?\n---`)
+
+	// sfcDeclarationRegex matches top-level JS/TS declarations of a component script
+	// (functions, classes, arrow functions, interfaces/types, default exports).
+	// Each alternative captures the name.
+	sfcDeclarationRegex = regexp.MustCompile(`(?m)^(?:` +
+		`(?:export\s+(?:default\s+)?)?(?:async\s+)?function\s*\*?\s*(\w+)` +
+		`|(?:export\s+(?:default\s+)?)?(?:abstract\s+)?class\s+(\w+)` +
+		`|(?:export\s+)?(?:const|let|var)\s+(\w+)\s*(?::[^=\n]+)?=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*(?::[^=\n]+)?=>|\w+\s*=>)` +
+		`|(?:export\s+)?(?:declare\s+)?(?:interface|type|enum)\s+(\w+)` +
+		`|export\s+(default)\b` +
+		`)`)
+)
+
+// sfcBlock is a template, script or style section of a single-file component
+type sfcBlock struct {
+	Kind    string // template, script, style
+	Lang    string // ts, js, scss...
+	Setup   bool   // <script setup>
+	Content string
+}
+
+// chunkSFC chunks Vue, Svelte and Astro single-file components into template,
+// script and style blocks named after the component
+func (c *Chunker) chunkSFC(path string, content string) ([]Chunk, error) {
+	maxSize := c.maxSize(c.rules.Chunking.JavaScript)
+	framework := detectLanguage(path)
+	component := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	blocks := parseSFCBlocks(filepath.Ext(path), content)
+	if len(blocks) == 0 {
+		return c.chunkBySize(path, content, maxSize, framework, getFileType(path))
+	}
+
+	chunks := []Chunk{}
+	for _, block := range blocks {
+		switch block.Kind {
+		case "script":
+			language := "JavaScript"
+			if block.Lang == "ts" || block.Lang == "typescript" {
+				language = "TypeScript"
+			}
+			symbol := component + "::script"
+			if block.Setup {
+				symbol += "-setup"
+			}
+
+			scriptChunks, err := c.chunkSFCScript(path, block.Content, maxSize, language, component, symbol)
+			if err != nil {
+				return nil, err
+			}
+			chunks = append(chunks, scriptChunks...)
+
+		default:
+			language := framework
+			if block.Kind == "style" {
+				language = "CSS"
+				if block.Lang != "" {
+					language = strings.ToUpper(block.Lang)
+				}
+			}
+			symbol := fmt.Sprintf("%s::%s", component, block.Kind)
+
+			if len(block.Content) <= maxSize {
+				chunks = append(chunks, c.sfcChunk(path, symbol, language, block.Content))
+				continue
+			}
+
+			subChunks, err := c.chunkBySize(path, block.Content, maxSize, language, getFileType(path))
+			if err != nil {
+				return nil, err
+			}
+			for k, sc := range subChunks {
+				sc.Symbol = fmt.Sprintf("%s#%d", symbol, k)
+				chunks = append(chunks, sc)
+			}
+		}
+	}
+
+	return chunks, nil
+}
+
+// chunkSFCScript splits a component script on its top-level declarations
+// (simplified, regex-based), named "Component::fetchUser". Code before the first
+// declaration (imports) becomes "Component::imports". A script without
+// declarations is kept as one block named symbol
+func (c *Chunker) chunkSFCScript(path, content string, maxSize int, language, component, symbol string) ([]Chunk, error) {
+	matches := sfcDeclarationRegex.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		matches = [][]int{nil}
+	}
+
+	chunks := []Chunk{}
+	if matches[0] != nil {
+		if preamble := strings.TrimSpace(content[:matches[0][0]]); preamble != "" {
+			chunks = append(chunks, c.sfcChunk(path, component+"::imports", language, preamble))
+		}
+	}
+
+	for i, match := range matches {
+		declSymbol := symbol
+		declContent := content
+		if match != nil {
+			endIdx := len(content)
+			if i < len(matches)-1 {
+				endIdx = matches[i+1][0]
+			}
+			declContent = content[match[0]:endIdx]
+			for g := 2; g+1 < len(match); g += 2 {
+				if match[g] != -1 {
+					declSymbol = component + "::" + content[match[g]:match[g+1]]
+					break
+				}
+			}
+		}
+
+		if len(declContent) <= maxSize {
+			chunks = append(chunks, c.sfcChunk(path, declSymbol, language, declContent))
+			continue
+		}
+
+		// Declaration too large, split by size
+		subChunks, err := c.chunkBySize(path, declContent, maxSize, language, getFileType(path))
+		if err != nil {
+			return nil, err
+		}
+		for k, sc := range subChunks {
+			sc.Symbol = fmt.Sprintf("%s#%d", declSymbol, k)
+			chunks = append(chunks, sc)
+		}
+	}
+
+	return chunks, nil
+}
+
+// sfcChunk builds a chunk for a component block
+func (c *Chunker) sfcChunk(path, symbol, language, content string) Chunk {
+	return Chunk{
+		Path:      path,
+		Language:  language,
+		Symbol:    symbol,
+		Component: getComponent(path),
+		Content:   strings.TrimSpace(content),
+		Type:      getFileType(path),
+	}
+}
+
+// parseSFCBlocks extracts the blocks of a component. Vue templates are wrapped in
+// <template>, while Svelte and Astro markup is whatever remains outside script/style.
+func parseSFCBlocks(ext string, content string) []sfcBlock {
+	var blocks []sfcBlock
+	markup := content
+
+	// Astro: the frontmatter fence is the component script (TypeScript)
+	if ext == ".astro" {
+		if m := astroFrontmatter.FindStringSubmatchIndex(content); m != nil {
+			if script := strings.TrimSpace(content[m[2]:m[3]]); script != "" {
+				blocks = append(blocks, sfcBlock{Kind: "script", Lang: "ts", Content: script})
+			}
+			markup = content[m[1]:]
+		}
+	}
+
+	for _, m := range sfcScriptRegex.FindAllStringSubmatch(markup, -1) {
+		attrs := m[1]
+		if strings.TrimSpace(m[2]) == "" {
+			continue
+		}
+		blocks = append(blocks, sfcBlock{
+			Kind:    "script",
+			Lang:    sfcLang(attrs),
+			Setup:   sfcSetupRegex.MatchString(attrs),
+			Content: m[2],
+		})
+	}
+
+	var styles []sfcBlock
+	for _, m := range sfcStyleRegex.FindAllStringSubmatch(markup, -1) {
+		if strings.TrimSpace(m[2]) == "" {
+			continue
+		}
+		styles = append(styles, sfcBlock{Kind: "style", Lang: sfcLang(m[1]), Content: m[2]})
+	}
+
+	var template string
+	if ext == ".vue" {
+		template = extractVueTemplate(markup)
+	} else {
+		template = sfcScriptRegex.ReplaceAllString(markup, "")
+		template = sfcStyleRegex.ReplaceAllString(template, "")
+	}
+	if strings.TrimSpace(template) != "" {
+		blocks = append(blocks, sfcBlock{Kind: "template", Content: template})
+	}
+
+	return append(blocks, styles...)
+}
+
+// extractVueTemplate returns the outermost <template> block, honouring nested templates
+func extractVueTemplate(content string) string {
+	tags := sfcTemplateTagRegex.FindAllStringSubmatchIndex(content, -1)
+	depth := 0
+	start := -1
+
+	for _, tag := range tags {
+		closing := tag[3] > tag[2]
+		if !closing {
+			if depth == 0 {
+				start = tag[0]
+			}
+			depth++
+			continue
+		}
+		depth--
+		if depth == 0 && start != -1 {
+			return content[start:tag[1]]
+		}
+	}
+
+	return ""
+}
+
+// sfcLang returns the value of the lang attribute, if any
+func sfcLang(attrs string) string {
+	if m := sfcLangRegex.FindStringSubmatch(attrs); m != nil {
+		return strings.ToLower(m[1])
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package indexer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/oview/internal/config"
+)
+
+func TestParseSFCBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		content string
+		want    []sfcBlock
+	}{
+		{
+			name: "vue with nested templates",
+			ext:  ".vue",
+			content: `<template>
+  <ul><template v-for="u in users"><li>{{ u.name }}</li></template></ul>
+</template>
+<script setup lang="ts">
+const users = defineProps<User[]>()
+</script>
+<style scoped lang="SCSS">
+ul { margin: 0 }
+</style>
+<style></style>`,
+			want: []sfcBlock{
+				{Kind: "script", Lang: "ts", Setup: true, Content: "\nconst users = defineProps<User[]>()\n"},
+				{Kind: "template", Content: "<template>\n  <ul><template v-for=\"u in users\"><li>{{ u.name }}</li></template></ul>\n</template>"},
+				{Kind: "style", Lang: "scss", Content: "\nul { margin: 0 }\n"},
+			},
+		},
+		{
+			name:    "svelte markup outside script and style",
+			ext:     ".svelte",
+			content: "<script>\n  let count = 0\n</script>\n\n<button on:click={() => count++}>{count}</button>\n",
+			want: []sfcBlock{
+				{Kind: "script", Content: "\n  let count = 0\n"},
+				{Kind: "template", Content: "\n\n<button on:click={() => count++}>{count}</button>\n"},
+			},
+		},
+		{
+			name:    "astro frontmatter",
+			ext:     ".astro",
+			content: "---\nconst { title } = Astro.props\n---\n<h1>{title}</h1>\n",
+			want: []sfcBlock{
+				{Kind: "script", Lang: "ts", Content: "const { title } = Astro.props"},
+				{Kind: "template", Content: "\n<h1>{title}</h1>\n"},
+			},
+		},
+		{
+			name:    "empty blocks are dropped",
+			ext:     ".vue",
+			content: "<script>\n</script>\n<template></template>",
+			want:    []sfcBlock{{Kind: "template", Content: "<template></template>"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSFCBlocks(tt.ext, tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSFCBlocks() =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkSFC(t *testing.T) {
+	component := `<template>
+  <article>{{ user.name }}</article>
+</template>
+
+<script setup lang="ts">
+import { ref } from 'vue'
+
+const user = ref<User | null>(null)
+
+async function fetchUser(id: number) {
+  user.value = await api.get(id)
+}
+
+export const reset = () => { user.value = null }
+</script>
+
+<style scoped>
+article { padding: 1rem }
+</style>
+`
+	chunks, err := testChunker(1500).ChunkFile("assets/components/UserCard.vue", []byte(component))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Chunk{
+		{Symbol: "UserCard::imports", Language: "TypeScript", Content: "import { ref } from 'vue'\n\nconst user = ref<User | null>(null)"},
+		{Symbol: "UserCard::fetchUser", Language: "TypeScript", Content: "async function fetchUser(id: number) {\n  user.value = await api.get(id)\n}"},
+		{Symbol: "UserCard::reset", Language: "TypeScript", Content: "export const reset = () => { user.value = null }"},
+		{Symbol: "UserCard::template", Language: "Vue", Content: "<template>\n  <article>{{ user.name }}</article>\n</template>"},
+		{Symbol: "UserCard::style", Language: "CSS", Content: "article { padding: 1rem }"},
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("symbols = %q, want %d chunks", chunkSymbols(chunks), len(want))
+	}
+	for i, w := range want {
+		c := chunks[i]
+		if c.Symbol != w.Symbol || c.Language != w.Language || c.Content != w.Content {
+			t.Errorf("chunk %d = %s (%s) %q, want %s (%s) %q", i, c.Symbol, c.Language, c.Content, w.Symbol, w.Language, w.Content)
+		}
+	}
+}
+
+func TestChunkSFCScriptWithoutDeclarations(t *testing.T) {
+	component := "<script>\n  let count = 0\n</script>\n<button>{count}</button>\n"
+
+	chunks, err := testChunker(1500).ChunkFile("src/lib/Counter.svelte", []byte(component))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Counter::script", "Counter::template"}
+	if got := chunkSymbols(chunks); !reflect.DeepEqual(got, want) {
+		t.Errorf("symbols = %q, want %q", got, want)
+	}
+}
+
+func TestChunkSFCLargeDeclaration(t *testing.T) {
+	body := strings.Repeat("  console.log('step')\n", 10)
+	component := "<script>\nexport default {\n" + body + "}\n</script>\n"
+
+	chunks, err := testChunker(100).ChunkFile("src/Big.vue", []byte(component))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks, want the declaration split by size", len(chunks))
+	}
+	for i, c := range chunks {
+		if want := "Big::default#"; !strings.HasPrefix(c.Symbol, want) {
+			t.Errorf("chunk %d symbol = %q, want %sN", i, c.Symbol, want)
+		}
+	}
+}
+
+func TestChunkJavaScriptIsSizeBased(t *testing.T) {
+	source := "export function a() {}\n\nexport function b() {}\n"
+
+	chunks, err := NewChunker(config.DefaultRAGConfig()).ChunkFile("assets/app.js", []byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 1 || chunks[0].Symbol != "" || chunks[0].Language != "JavaScript" {
+		t.Errorf("chunks = %+v, want one JavaScript chunk", chunks)
+	}
+}
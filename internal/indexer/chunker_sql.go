@@ -0,0 +1,459 @@
+package indexer
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// sqlStatementPatterns detect the kind of a statement and the table it targets.
+// The last capture group is always the table name.
+var sqlStatementPatterns = []struct {
+	kind  string
+	regex *regexp.Regexp
+}{
+	{"CREATE TABLE", regexp.MustCompile(`(?is)^CREATE\s+(?:OR\s+REPLACE\s+)?(?:TEMP(?:ORARY)?\s+|UNLOGGED\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w."` + "`" + `\[\]]+)`)},
+	{"CREATE VIEW", regexp.MustCompile(`(?is)^CREATE\s+(?:OR\s+REPLACE\s+)?(?:MATERIALIZED\s+)?VIEW\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w."` + "`" + `\[\]]+)`)},
+	{"CREATE INDEX", regexp.MustCompile(`(?is)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?(?:[\w."` + "`" + `\[\]]+\s+)?ON\s+(?:ONLY\s+)?([\w."` + "`" + `\[\]]+)`)},
+	{"ALTER TABLE", regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?([\w."` + "`" + `\[\]]+)`)},
+	{"DROP TABLE", regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?([\w."` + "`" + `\[\]]+)`)},
+	{"CREATE SEQUENCE", regexp.MustCompile(`(?is)^CREATE\s+SEQUENCE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w."` + "`" + `\[\]]+)`)},
+	{"COMMENT ON", regexp.MustCompile(`(?is)^COMMENT\s+ON\s+(?:TABLE|COLUMN)\s+([\w."` + "`" + `\[\]]+?)(?:\.\w+)?\s+IS`)},
+	{"INSERT", regexp.MustCompile(`(?is)^INSERT\s+INTO\s+([\w."` + "`" + `\[\]]+)`)},
+	{"UPDATE", regexp.MustCompile(`(?is)^UPDATE\s+([\w."` + "`" + `\[\]]+)`)},
+	{"DELETE", regexp.MustCompile(`(?is)^DELETE\s+FROM\s+([\w."` + "`" + `\[\]]+)`)},
+}
+
+var (
+	migrationVersionRegex     = regexp.MustCompile(`Version(\d{14})`)
+	migrationDescriptionRegex = regexp.MustCompile(`(?s)function\s+getDescription\s*\(\s*\)[^{]*\{\s*return\s+(['"])(.*?)['"]\s*;`)
+	migrationMethodRegex      = regexp.MustCompile(`function\s+(up|down)\s*\(`)
+)
+
+// sqlStatement is a single SQL statement with the table it targets
+type sqlStatement struct {
+	Kind  string
+	Table string
+	SQL   string
+}
+
+// chunkSQL chunks SQL files per statement, named after the table they target
+func (c *Chunker) chunkSQL(path string, content string) ([]Chunk, error) {
+	maxSize := c.maxSize(c.rules.Chunking.SQL)
+	statements := parseSQLStatements(splitSQLStatements(content))
+
+	if len(statements) == 0 {
+		return c.chunkBySize(path, content, maxSize, "SQL", getFileType(path))
+	}
+
+	chunks := []Chunk{}
+	for _, group := range groupSQLStatements(statements, maxSize) {
+		symbol := group[0].Table
+		if symbol == "" {
+			symbol = group[0].Kind
+		}
+
+		parts := make([]string, len(group))
+		for i, st := range group {
+			parts[i] = st.SQL
+		}
+
+		sub, err := c.sqlChunks(path, symbol, strings.Join(parts, "\n\n"), maxSize, map[string]string{
+			"sql_statement": group[0].Kind,
+		})
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, sub...)
+	}
+
+	return chunks, nil
+}
+
+// chunkMigration indexes a Doctrine migration by the SQL of its up() and down() methods,
+// tagged with the migration version
+func (c *Chunker) chunkMigration(path string, content string) ([]Chunk, error) {
+	maxSize := c.maxSize(c.rules.Chunking.SQL)
+
+	className := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	version := ""
+	if m := migrationVersionRegex.FindStringSubmatch(className); m != nil {
+		version = m[1]
+	}
+
+	description := ""
+	if m := migrationDescriptionRegex.FindStringSubmatch(content); m != nil {
+		description = m[2]
+	}
+
+	chunks := []Chunk{}
+	for _, match := range migrationMethodRegex.FindAllStringSubmatchIndex(content, -1) {
+		method := content[match[2]:match[3]]
+		body := extractBraceBlock(content, match[1])
+		queries := extractAddSQL(body)
+		if len(queries) == 0 {
+			continue
+		}
+
+		statements := parseSQLStatements(queries)
+		tables := []string{}
+		for _, st := range statements {
+			if st.Table != "" {
+				tables = appendUnique(tables, st.Table)
+			}
+		}
+
+		var text strings.Builder
+		text.WriteString(fmt.Sprintf("Doctrine migration %s::%s()", className, method))
+		if date := formatMigrationVersion(version); date != "" {
+			text.WriteString(fmt.Sprintf(" - %s", date))
+		}
+		text.WriteString("\n")
+		if description != "" {
+			text.WriteString(fmt.Sprintf("Description: %s\n", description))
+		}
+		if len(tables) > 0 {
+			text.WriteString(fmt.Sprintf("Tables: %s\n", strings.Join(tables, ", ")))
+		}
+		text.WriteString("\n")
+		for _, st := range statements {
+			text.WriteString(st.SQL + ";\n")
+		}
+
+		sub, err := c.sqlChunks(path, fmt.Sprintf("%s::%s", className, method), text.String(), maxSize, map[string]string{
+			"migration_version": version,
+			"migration_method":  method,
+			"tables":            strings.Join(tables, ","),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i := range sub {
+			sub[i].Component = "migrations"
+		}
+		chunks = append(chunks, sub...)
+	}
+
+	// No addSql() calls found (e.g. data migration in PHP): index as regular PHP
+	if len(chunks) == 0 {
+		return c.chunkPHP(path, content)
+	}
+
+	return chunks, nil
+}
+
+// sqlChunks returns a single SQL chunk, split by size if needed
+func (c *Chunker) sqlChunks(path, symbol, content string, maxSize int, metadata map[string]string) ([]Chunk, error) {
+	if len(content) <= maxSize {
+		return []Chunk{{
+			Path:      path,
+			Language:  "SQL",
+			Symbol:    symbol,
+			Component: getComponent(path),
+			Content:   strings.TrimSpace(content),
+			Type:      getFileType(path),
+			Metadata:  metadata,
+		}}, nil
+	}
+
+	sub, err := c.chunkBySize(path, content, maxSize, "SQL", getFileType(path))
+	if err != nil {
+		return nil, err
+	}
+	for k := range sub {
+		sub[k].Symbol = fmt.Sprintf("%s#%d", symbol, k)
+		sub[k].Metadata = metadata
+	}
+	return sub, nil
+}
+
+// isDoctrineMigration reports whether a PHP file is a Doctrine migration class
+func isDoctrineMigration(path string, content []byte) bool {
+	if !strings.HasPrefix(filepath.Base(path), "Version") {
+		return false
+	}
+	lower := strings.ToLower(filepath.ToSlash(path))
+	return strings.Contains(lower, "migrations/") || strings.Contains(string(content), "AbstractMigration")
+}
+
+// splitSQLStatements splits SQL on semicolons, ignoring those inside quotes,
+// comments and dollar-quoted bodies
+func splitSQLStatements(content string) []string {
+	var statements []string
+	var current strings.Builder
+
+	inSingle, inDouble, inBacktick := false, false, false
+	inLineComment, inBlockComment := false, false
+	dollarTag := ""
+
+	for i := 0; i < len(content); i++ {
+		ch := content[i]
+		next := byte(0)
+		if i+1 < len(content) {
+			next = content[i+1]
+		}
+
+		switch {
+		case inLineComment:
+			if ch == '\n' {
+				inLineComment = false
+			}
+		case inBlockComment:
+			if ch == '*' && next == '/' {
+				inBlockComment = false
+				current.WriteByte(ch)
+				i++
+				ch = next
+			}
+		case dollarTag != "":
+			if strings.HasPrefix(content[i:], dollarTag) {
+				current.WriteString(dollarTag)
+				i += len(dollarTag) - 1
+				dollarTag = ""
+				continue
+			}
+		case inSingle:
+			if ch == '\'' {
+				inSingle = false
+			}
+		case inDouble:
+			if ch == '"' {
+				inDouble = false
+			}
+		case inBacktick:
+			if ch == '`' {
+				inBacktick = false
+			}
+		default:
+			switch {
+			case ch == '-' && next == '-':
+				inLineComment = true
+			case ch == '/' && next == '*':
+				inBlockComment = true
+			case ch == '\'':
+				inSingle = true
+			case ch == '"':
+				inDouble = true
+			case ch == '`':
+				inBacktick = true
+			case ch == '$':
+				if end := strings.IndexByte(content[i+1:], '$'); end != -1 && isDollarTag(content[i+1:i+1+end]) {
+					dollarTag = content[i : i+end+2]
+					current.WriteString(dollarTag)
+					i += end + 1
+					continue
+				}
+			case ch == ';':
+				if stmt := strings.TrimSpace(current.String()); stmt != "" {
+					statements = append(statements, stmt)
+				}
+				current.Reset()
+				continue
+			}
+		}
+
+		current.WriteByte(ch)
+	}
+
+	if stmt := strings.TrimSpace(current.String()); stmt != "" {
+		statements = append(statements, stmt)
+	}
+
+	return statements
+}
+
+// parseSQLStatements detects kind and table of each statement, dropping comment-only ones
+func parseSQLStatements(raw []string) []sqlStatement {
+	statements := []sqlStatement{}
+	for _, sql := range raw {
+		body := stripSQLComments(sql)
+		if body == "" {
+			continue
+		}
+
+		st := sqlStatement{SQL: strings.TrimSpace(sql)}
+		for _, p := range sqlStatementPatterns {
+			if m := p.regex.FindStringSubmatch(body); m != nil {
+				st.Kind = p.kind
+				st.Table = unquoteSQLIdentifier(m[len(m)-1])
+				break
+			}
+		}
+		if st.Kind == "" {
+			// Unknown statement: use its leading keywords (e.g. "CREATE FUNCTION", "DROP INDEX")
+			fields := strings.Fields(strings.ToUpper(body))
+			st.Kind = fields[0]
+			if len(fields) > 1 && (fields[0] == "CREATE" || fields[0] == "ALTER" || fields[0] == "DROP") {
+				st.Kind += " " + fields[1]
+			}
+		}
+		statements = append(statements, st)
+	}
+	return statements
+}
+
+// groupSQLStatements merges consecutive statements of the same kind on the same table
+// (typically data dumps) as long as they fit in one chunk
+func groupSQLStatements(statements []sqlStatement, maxSize int) [][]sqlStatement {
+	var groups [][]sqlStatement
+	size := 0
+
+	for _, st := range statements {
+		if n := len(groups); n > 0 {
+			last := groups[n-1][0]
+			if last.Kind == st.Kind && last.Table == st.Table && size+len(st.SQL) <= maxSize {
+				groups[n-1] = append(groups[n-1], st)
+				size += len(st.SQL)
+				continue
+			}
+		}
+		groups = append(groups, []sqlStatement{st})
+		size = len(st.SQL)
+	}
+
+	return groups
+}
+
+// extractBraceBlock returns the content of the first {...} block after offset
+func extractBraceBlock(content string, offset int) string {
+	start := strings.IndexByte(content[offset:], '{')
+	if start == -1 {
+		return ""
+	}
+	start += offset
+
+	depth := 0
+	for i := start; i < len(content); i++ {
+		switch content[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return content[start+1 : i]
+			}
+		}
+	}
+	return content[start+1:]
+}
+
+// extractAddSQL returns the string arguments of $this->addSql(...) calls,
+// supporting quoted strings and heredoc/nowdoc
+func extractAddSQL(body string) []string {
+	var queries []string
+	rest := body
+
+	for {
+		idx := strings.Index(rest, "addSql(")
+		if idx == -1 {
+			break
+		}
+		rest = strings.TrimLeft(rest[idx+len("addSql("):], " \t\r\n")
+
+		switch {
+		case strings.HasPrefix(rest, "'") || strings.HasPrefix(rest, "\""):
+			quote := rest[0]
+			var sb strings.Builder
+			i := 1
+			for ; i < len(rest); i++ {
+				if rest[i] == '\\' && i+1 < len(rest) && (rest[i+1] == quote || rest[i+1] == '\\') {
+					sb.WriteByte(rest[i+1])
+					i++
+					continue
+				}
+				if rest[i] == quote {
+					break
+				}
+				sb.WriteByte(rest[i])
+			}
+			queries = append(queries, sb.String())
+			if i < len(rest) {
+				rest = rest[i+1:]
+			} else {
+				rest = ""
+			}
+
+		case strings.HasPrefix(rest, "<<<"):
+			lineEnd := strings.IndexByte(rest, '\n')
+			if lineEnd == -1 {
+				return queries
+			}
+			tag := strings.Trim(strings.TrimSpace(rest[3:lineEnd]), `'"`)
+			lines := strings.Split(rest[lineEnd+1:], "\n")
+			var sb strings.Builder
+			consumed := lineEnd + 1
+			for _, line := range lines {
+				consumed += len(line) + 1
+				if strings.HasPrefix(strings.TrimSpace(line), tag) {
+					break
+				}
+				sb.WriteString(line + "\n")
+			}
+			queries = append(queries, strings.TrimSpace(sb.String()))
+			if consumed < len(rest) {
+				rest = rest[consumed:]
+			} else {
+				rest = ""
+			}
+		}
+	}
+
+	return queries
+}
+
+// formatMigrationVersion turns 20240131120000 into "2024-01-31 12:00:00"
+func formatMigrationVersion(version string) string {
+	if len(version) != 14 {
+		return ""
+	}
+	return fmt.Sprintf("%s-%s-%s %s:%s:%s",
+		version[0:4], version[4:6], version[6:8], version[8:10], version[10:12], version[12:14])
+}
+
+// stripSQLComments removes leading line and block comments from a statement
+func stripSQLComments(sql string) string {
+	s := strings.TrimSpace(sql)
+	for {
+		switch {
+		case strings.HasPrefix(s, "--"):
+			end := strings.IndexByte(s, '\n')
+			if end == -1 {
+				return ""
+			}
+			s = strings.TrimSpace(s[end+1:])
+		case strings.HasPrefix(s, "/*"):
+			end := strings.Index(s, "*/")
+			if end == -1 {
+				return ""
+			}
+			s = strings.TrimSpace(s[end+2:])
+		default:
+			return s
+		}
+	}
+}
+
+// unquoteSQLIdentifier removes quoting from "schema"."table", `table` or [table]
+func unquoteSQLIdentifier(name string) string {
+	return strings.NewReplacer(`"`, "", "`", "", "[", "", "]", "").Replace(name)
+}
+
+// isDollarTag reports whether s is a valid PostgreSQL dollar-quote tag (possibly empty)
+func isDollarTag(s string) bool {
+	for _, r := range s {
+		if !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+func appendUnique(slice []string, value string) []string {
+	for _, v := range slice {
+		if v == value {
+			return slice
+		}
+	}
+	return append(slice, value)
+}
@@ -0,0 +1,194 @@
+package indexer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitSQLStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "semicolons",
+			sql:  "CREATE TABLE a (id int);\nDROP TABLE b;",
+			want: []string{"CREATE TABLE a (id int)", "DROP TABLE b"},
+		},
+		{
+			name: "quoted semicolons",
+			sql:  `INSERT INTO t VALUES ('a;b', "c;d", ` + "`e;f`" + `); SELECT 1`,
+			want: []string{`INSERT INTO t VALUES ('a;b', "c;d", ` + "`e;f`" + `)`, "SELECT 1"},
+		},
+		{
+			name: "comments",
+			sql:  "-- drop; everything\nDROP TABLE a; /* keep; this */ DROP TABLE b;",
+			want: []string{"-- drop; everything\nDROP TABLE a", "/* keep; this */ DROP TABLE b"},
+		},
+		{
+			name: "dollar-quoted function body",
+			sql: `CREATE FUNCTION touch() RETURNS trigger AS $body$
+BEGIN NEW.updated_at = now(); RETURN NEW; END;
+$body$ LANGUAGE plpgsql;
+CREATE TRIGGER t BEFORE UPDATE ON users FOR EACH ROW EXECUTE FUNCTION touch();`,
+			want: []string{
+				"CREATE FUNCTION touch() RETURNS trigger AS $body$\nBEGIN NEW.updated_at = now(); RETURN NEW; END;\n$body$ LANGUAGE plpgsql",
+				"CREATE TRIGGER t BEFORE UPDATE ON users FOR EACH ROW EXECUTE FUNCTION touch()",
+			},
+		},
+		{
+			name: "empty statements and missing final semicolon",
+			sql:  ";;\n  SELECT 1  ",
+			want: []string{"SELECT 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitSQLStatements(tt.sql); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitSQLStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSQLStatements(t *testing.T) {
+	tests := []struct {
+		sql   string
+		kind  string
+		table string
+	}{
+		{`CREATE TABLE IF NOT EXISTS "public"."users" (id int)`, "CREATE TABLE", "public.users"},
+		{"create temporary table `tmp_import` (x int)", "CREATE TABLE", "tmp_import"},
+		{"CREATE OR REPLACE MATERIALIZED VIEW stats AS SELECT 1", "CREATE VIEW", "stats"},
+		{"CREATE UNIQUE INDEX CONCURRENTLY idx_email ON users (email)", "CREATE INDEX", "users"},
+		{"CREATE INDEX ON ONLY orders (created_at)", "CREATE INDEX", "orders"},
+		{"ALTER TABLE ONLY [orders] ADD COLUMN total int", "ALTER TABLE", "orders"},
+		{"COMMENT ON COLUMN users.email IS 'login'", "COMMENT ON", "users"},
+		{"INSERT INTO users (id) VALUES (1)", "INSERT", "users"},
+		{"-- seed\nDELETE FROM sessions WHERE expired", "DELETE", "sessions"},
+		{"CREATE FUNCTION touch() RETURNS trigger", "CREATE FUNCTION", ""},
+		{"DROP INDEX idx_email", "DROP INDEX", ""},
+		{"VACUUM ANALYZE", "VACUUM", ""},
+	}
+	for _, tt := range tests {
+		got := parseSQLStatements([]string{tt.sql})
+		if len(got) != 1 || got[0].Kind != tt.kind || got[0].Table != tt.table {
+			t.Errorf("parseSQLStatements(%q) = %+v, want kind %q table %q", tt.sql, got, tt.kind, tt.table)
+		}
+	}
+
+	if got := parseSQLStatements([]string{"-- only a comment", "/* and another */"}); len(got) != 0 {
+		t.Errorf("comment-only statements = %+v, want none", got)
+	}
+}
+
+func TestChunkSQLGroupsStatementsByTable(t *testing.T) {
+	dump := `CREATE TABLE users (id int);
+INSERT INTO users VALUES (1);
+INSERT INTO users VALUES (2);
+INSERT INTO orders VALUES (1);
+CREATE INDEX idx_users ON users (id);`
+
+	chunks, err := testChunker(1500).ChunkFile("db/schema.sql", []byte(dump))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"users", "users", "orders", "users"}
+	if got := chunkSymbols(chunks); !reflect.DeepEqual(got, want) {
+		t.Fatalf("symbols = %q, want %q", got, want)
+	}
+	if chunks[1].Content != "INSERT INTO users VALUES (1)\n\nINSERT INTO users VALUES (2)" {
+		t.Errorf("inserts chunk = %q, want both inserts", chunks[1].Content)
+	}
+	if kind := chunks[3].Metadata["sql_statement"]; kind != "CREATE INDEX" {
+		t.Errorf("sql_statement = %q, want CREATE INDEX", kind)
+	}
+}
+
+func TestChunkMigration(t *testing.T) {
+	migration := `<?php
+
+declare(strict_types=1);
+
+namespace DoctrineMigrations;
+
+use Doctrine\DBAL\Schema\Schema;
+use Doctrine\Migrations\AbstractMigration;
+
+final class Version20240131120000 extends AbstractMigration
+{
+    public function getDescription(): string
+    {
+        return 'Add user accounts';
+    }
+
+    public function up(Schema $schema): void
+    {
+        $this->addSql('CREATE TABLE user_account (id INT NOT NULL, email VARCHAR(180) NOT NULL, PRIMARY KEY(id))');
+        $this->addSql("CREATE UNIQUE INDEX UNIQ_EMAIL ON user_account (email)");
+        $this->addSql(<<<'SQL'
+            COMMENT ON COLUMN user_account.email IS 'it\'s the login'
+            SQL);
+    }
+
+    public function down(Schema $schema): void
+    {
+        $this->addSql('DROP TABLE user_account');
+    }
+}
+`
+	path := "migrations/Version20240131120000.php"
+	if !isDoctrineMigration(path, []byte(migration)) {
+		t.Fatal("isDoctrineMigration() = false")
+	}
+
+	chunks, err := testChunker(1500).ChunkFile(path, []byte(migration))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Version20240131120000::up", "Version20240131120000::down"}
+	if got := chunkSymbols(chunks); !reflect.DeepEqual(got, want) {
+		t.Fatalf("symbols = %q, want %q", got, want)
+	}
+
+	up := chunks[0]
+	for _, line := range []string{
+		"Doctrine migration Version20240131120000::up() - 2024-01-31 12:00:00",
+		"Description: Add user accounts",
+		"Tables: user_account",
+		"CREATE UNIQUE INDEX UNIQ_EMAIL ON user_account (email);",
+		`COMMENT ON COLUMN user_account.email IS 'it\'s the login';`,
+	} {
+		if !strings.Contains(up.Content, line) {
+			t.Errorf("up chunk misses %q:\n%s", line, up.Content)
+		}
+	}
+	wantMeta := map[string]string{"migration_version": "20240131120000", "migration_method": "up", "tables": "user_account"}
+	if !reflect.DeepEqual(up.Metadata, wantMeta) {
+		t.Errorf("metadata = %v, want %v", up.Metadata, wantMeta)
+	}
+	if up.Component != "migrations" || up.Language != "SQL" {
+		t.Errorf("up chunk is %s/%s, want SQL in migrations", up.Language, up.Component)
+	}
+}
+
+func TestIsDoctrineMigration(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		want    bool
+	}{
+		{"migrations/Version20240101000000.php", "", true},
+		{"src/Migrations/Version1.php", "", true},
+		{"src/Db/Version2.php", "class Version2 extends AbstractMigration", true},
+		{"src/Api/VersionController.php", "class VersionController", false},
+		{"migrations/Seed.php", "extends AbstractMigration", false},
+	}
+	for _, tt := range tests {
+		if got := isDoctrineMigration(tt.path, []byte(tt.content)); got != tt.want {
+			t.Errorf("isDoctrineMigration(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
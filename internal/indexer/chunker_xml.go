@@ -0,0 +1,228 @@
+package indexer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xmlChunkElements lists, per root element, the elements that become their own chunk:
+// Symfony services and routes, Doctrine mappings and PHPUnit config sections.
+// Other XML files (pom.xml, translations...) are kept whole or split by size.
+var xmlChunkElements = map[string]map[string]bool{
+	"container": {
+		"parameters": true,
+		"defaults":   true,
+		"instanceof": true,
+		"prototype":  true,
+		"service":    true,
+		"stack":      true,
+	},
+	"routes": {
+		"route":  true,
+		"import": true,
+	},
+	"doctrine-mapping": {
+		"entity":            true,
+		"mapped-superclass": true,
+		"embeddable":        true,
+	},
+	"doctrine-mongo-mapping": {
+		"document":          true,
+		"embedded-document": true,
+		"mapped-superclass": true,
+	},
+	"phpunit": {
+		"testsuite":  true,
+		"php":        true,
+		"coverage":   true,
+		"source":     true,
+		"extensions": true,
+		"groups":     true,
+		"logging":    true,
+	},
+}
+
+// xmlSymbolAttributes lists the attributes used to name a chunk, in order of preference
+var xmlSymbolAttributes = []string{"id", "name", "namespace", "resource"}
+
+// xmlElement is the byte range of a chunkable element
+type xmlElement struct {
+	Name   string
+	Symbol string
+	Start  int64
+	End    int64
+}
+
+// chunkXML chunks XML files by meaningful elements (services, routes, entity mappings...)
+func (c *Chunker) chunkXML(path string, content string) ([]Chunk, error) {
+	maxSize := c.maxSize(c.rules.Chunking.XML)
+	fileType := getFileType(path)
+	if fileType == "code" {
+		fileType = "config"
+	}
+
+	root, elements, err := findXMLElements(content)
+	if err != nil || len(elements) == 0 {
+		// Unparseable or unknown XML: keep small files whole, split others by size
+		if len(content) <= maxSize {
+			return []Chunk{{
+				Path:      path,
+				Language:  "XML",
+				Component: getComponent(path),
+				Content:   content,
+				Type:      fileType,
+			}}, nil
+		}
+		return c.chunkBySize(path, content, maxSize, "XML", fileType)
+	}
+
+	chunks := []Chunk{}
+	add := func(symbol, text string) error {
+		if len(text) <= maxSize {
+			chunks = append(chunks, Chunk{
+				Path:      path,
+				Language:  "XML",
+				Symbol:    symbol,
+				Component: getComponent(path),
+				Content:   text,
+				Type:      fileType,
+			})
+			return nil
+		}
+
+		// Too large (e.g. big entity mapping), split by size
+		subChunks, err := c.chunkBySize(path, text, maxSize, "XML", fileType)
+		if err != nil {
+			return err
+		}
+		for k, sc := range subChunks {
+			sc.Symbol = fmt.Sprintf("%s#%d", symbol, k)
+			chunks = append(chunks, sc)
+		}
+		return nil
+	}
+
+	// What the elements leave out (root attributes, unlisted sections) is kept
+	// as one chunk named after the root, unless it is only the wrapping tags
+	if rest := xmlRemainder(content, elements); xmlHasContent(rest) {
+		if err := add(root, rest); err != nil {
+			return nil, err
+		}
+	}
+	for _, el := range elements {
+		if err := add(el.Symbol, strings.TrimSpace(content[el.Start:el.End])); err != nil {
+			return nil, err
+		}
+	}
+
+	return chunks, nil
+}
+
+// findXMLElements returns the root element name and the outermost chunkable
+// elements under it, with their byte ranges
+func findXMLElements(content string) (string, []xmlElement, error) {
+	dec := xml.NewDecoder(strings.NewReader(content))
+	dec.Strict = false
+
+	var root string
+	var chunkable map[string]bool
+	var elements []xmlElement
+	var current *xmlElement
+	depth := 0
+	currentDepth := 0
+
+	for {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				root = t.Name.Local
+				if chunkable = xmlChunkElements[root]; chunkable == nil {
+					return root, nil, nil
+				}
+				continue
+			}
+			if current == nil && chunkable[t.Name.Local] {
+				current = &xmlElement{
+					Name:   t.Name.Local,
+					Symbol: xmlSymbol(t),
+					Start:  offset,
+				}
+				currentDepth = depth
+			}
+
+		case xml.EndElement:
+			if current != nil && depth == currentDepth {
+				current.End = dec.InputOffset()
+				elements = append(elements, *current)
+				current = nil
+			}
+			depth--
+		}
+	}
+
+	return root, elements, nil
+}
+
+// xmlRemainder returns the content outside the elements, without blank lines
+func xmlRemainder(content string, elements []xmlElement) string {
+	var b strings.Builder
+	prev := int64(0)
+	for _, el := range append(elements, xmlElement{Start: int64(len(content)), End: int64(len(content))}) {
+		for _, line := range strings.Split(content[prev:el.Start], "\n") {
+			if strings.TrimSpace(line) != "" {
+				b.WriteString(strings.TrimRight(line, " \t\r") + "\n")
+			}
+		}
+		prev = el.End
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// xmlHasContent reports whether XML holds text, or attributes other than
+// namespace declarations
+func xmlHasContent(content string) bool {
+	dec := xml.NewDecoder(strings.NewReader(content))
+	dec.Strict = false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			if strings.TrimSpace(string(t)) != "" {
+				return true
+			}
+		case xml.StartElement:
+			for _, attr := range t.Attr {
+				if attr.Name.Space != "xmlns" && attr.Name.Local != "xmlns" && attr.Name.Local != "schemaLocation" && attr.Name.Local != "noNamespaceSchemaLocation" {
+					return true
+				}
+			}
+		}
+	}
+}
+
+// xmlSymbol names an element after its id/name attribute, or its tag name
+func xmlSymbol(el xml.StartElement) string {
+	for _, attrName := range xmlSymbolAttributes {
+		for _, attr := range el.Attr {
+			if attr.Name.Local == attrName && attr.Value != "" {
+				return attr.Value
+			}
+		}
+	}
+	return el.Name.Local
+}
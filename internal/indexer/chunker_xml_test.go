@@ -0,0 +1,182 @@
+package indexer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChunkXMLServices(t *testing.T) {
+	services := `<?xml version="1.0" encoding="UTF-8" ?>
+<container xmlns="http://symfony.com/schema/dic/services">
+    <parameters>
+        <parameter key="app.sender">noreply@example.com</parameter>
+    </parameters>
+    <services>
+        <defaults autowire="true" autoconfigure="true"/>
+        <instanceof id="App\Handler\HandlerInterface"><tag name="app.handler"/></instanceof>
+        <prototype namespace="App\" resource="../src/" exclude="../src/{Entity,Kernel.php}"/>
+        <service id="app.mailer" class="App\Service\Mailer">
+            <argument type="service" id="mailer.transport"/>
+        </service>
+    </services>
+</container>
+`
+	chunks, err := testChunker(1500).ChunkFile("config/services.xml", []byte(services))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"parameters", "defaults", `App\Handler\HandlerInterface`, `App\`, "app.mailer"}
+	if got := chunkSymbols(chunks); !reflect.DeepEqual(got, want) {
+		t.Fatalf("symbols = %q, want %q", got, want)
+	}
+	mailer := chunks[4]
+	if !strings.HasPrefix(mailer.Content, `<service id="app.mailer"`) || !strings.HasSuffix(mailer.Content, "</service>") {
+		t.Errorf("service chunk = %q, want the whole element", mailer.Content)
+	}
+	if mailer.Language != "XML" || mailer.Type != "config" {
+		t.Errorf("service chunk is %s/%s, want XML/config", mailer.Language, mailer.Type)
+	}
+}
+
+func TestChunkXMLDoctrineMapping(t *testing.T) {
+	mapping := `<doctrine-mapping xmlns="http://doctrine-project.org/schemas/orm/doctrine-mapping">
+    <entity name="App\Entity\User" table="user_account">
+        <id name="id" type="integer"><generator strategy="AUTO"/></id>
+        <field name="email" type="string" length="180"/>
+        <field name="createdAt" type="datetime_immutable"/>
+        <many-to-one field="team" target-entity="App\Entity\Team"/>
+    </entity>
+</doctrine-mapping>`
+
+	chunks, err := testChunker(1500).ChunkFile("config/doctrine/User.orm.xml", []byte(mapping))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := chunkSymbols(chunks); !reflect.DeepEqual(got, []string{`App\Entity\User`}) {
+		t.Fatalf("symbols = %q, want the entity", got)
+	}
+
+	// An element larger than the chunk size is split, keeping its name
+	chunks, err = testChunker(120).ChunkFile("config/doctrine/User.orm.xml", []byte(mapping))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks, want the entity split by size", len(chunks))
+	}
+	for i, c := range chunks {
+		if !strings.HasPrefix(c.Symbol, `App\Entity\User#`) {
+			t.Errorf("chunk %d symbol = %q, want App\\Entity\\User#N", i, c.Symbol)
+		}
+	}
+}
+
+func TestChunkXMLWithoutKnownElements(t *testing.T) {
+	content := `<?xml version="1.0"?>
+<translations><message key="hello">Bonjour</message></translations>`
+
+	chunks, err := testChunker(1500).ChunkFile("translations/messages.fr.xml", []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 1 || chunks[0].Content != content || chunks[0].Symbol != "" {
+		t.Errorf("chunks = %+v, want the whole file", chunks)
+	}
+}
+
+func TestChunkXMLRemainder(t *testing.T) {
+	phpunit := `<?xml version="1.0" encoding="UTF-8"?>
+<phpunit xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+         xsi:noNamespaceSchemaLocation="vendor/phpunit/phpunit/phpunit.xsd"
+         bootstrap="tests/bootstrap.php" colors="true">
+    <php>
+        <env name="APP_ENV" value="test" force="true"/>
+    </php>
+    <testsuites>
+        <testsuite name="unit"><directory>tests/Unit</directory></testsuite>
+    </testsuites>
+    <listeners>
+        <listener class="Symfony\Bridge\PhpUnit\SymfonyTestsListener"/>
+    </listeners>
+</phpunit>`
+
+	chunks, err := testChunker(1500).ChunkFile("phpunit.xml.dist", []byte(phpunit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"phpunit", "php", "unit"}
+	if got := chunkSymbols(chunks); !reflect.DeepEqual(got, want) {
+		t.Fatalf("symbols = %q, want %q", got, want)
+	}
+	rest := chunks[0].Content
+	for _, kept := range []string{`bootstrap="tests/bootstrap.php"`, "<listeners>", "SymfonyTestsListener"} {
+		if !strings.Contains(rest, kept) {
+			t.Errorf("remainder chunk misses %s:\n%s", kept, rest)
+		}
+	}
+	if strings.Contains(rest, "APP_ENV") || strings.Contains(rest, "tests/Unit") {
+		t.Errorf("remainder chunk repeats chunked elements:\n%s", rest)
+	}
+}
+
+func TestChunkXMLOtherRoots(t *testing.T) {
+	pom := `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+    <groupId>com.example</groupId>
+    <artifactId>shop</artifactId>
+    <properties>
+        <maven.compiler.source>17</maven.compiler.source>
+    </properties>
+    <build>
+        <plugins><plugin><artifactId>maven-surefire-plugin</artifactId></plugin></plugins>
+    </build>
+    <distributionManagement><site><id>docs</id></site></distributionManagement>
+</project>`
+
+	chunks, err := testChunker(1500).ChunkFile("pom.xml", []byte(pom))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 1 || chunks[0].Content != pom || chunks[0].Symbol != "" {
+		t.Errorf("chunks = %+v, want the whole file", chunks)
+	}
+
+	chunks, err = testChunker(200).ChunkFile("pom.xml", []byte(pom))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var joined strings.Builder
+	for _, c := range chunks {
+		joined.WriteString(c.Content)
+	}
+	if len(chunks) < 2 || !strings.Contains(joined.String(), "com.example") || !strings.Contains(joined.String(), "maven-surefire-plugin") {
+		t.Errorf("chunks = %q, want the file split by size", chunkSymbols(chunks))
+	}
+}
+
+func TestXMLSymbol(t *testing.T) {
+	tests := []struct {
+		root    string
+		element string
+		want    string
+	}{
+		{"container", `<service id="app.mailer" class="App\Mailer"/>`, "app.mailer"},
+		{"container", `<service id="" class="App\Mailer"/>`, "service"},
+		{"doctrine-mapping", `<entity name="App\Entity\User" table="user"/>`, `App\Entity\User`},
+		{"phpunit", `<testsuite name="unit"/>`, "unit"},
+		{"phpunit", `<coverage/>`, "coverage"},
+		{"routes", `<import resource="routes/api.xml"/>`, "routes/api.xml"},
+	}
+	for _, tt := range tests {
+		root, elements, err := findXMLElements("<" + tt.root + ">" + tt.element + "</" + tt.root + ">")
+		if err != nil || root != tt.root || len(elements) != 1 {
+			t.Errorf("findXMLElements(%q) = %q, %v, %v", tt.element, root, elements, err)
+			continue
+		}
+		if elements[0].Symbol != tt.want {
+			t.Errorf("symbol of %s = %q, want %q", tt.element, elements[0].Symbol, tt.want)
+		}
+	}
+}
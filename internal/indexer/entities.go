@@ -0,0 +1,596 @@
+package indexer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/lib/pq"
+	"gopkg.in/yaml.v3"
+)
+
+// Doctrine mapping sources stored in the entities table
+const (
+	MappingAttribute  = "attribute"
+	MappingAnnotation = "annotation"
+	MappingXML        = "xml"
+	MappingYAML       = "yaml"
+)
+
+// Doctrine association kinds
+const (
+	RelationManyToOne  = "ManyToOne"
+	RelationOneToMany  = "OneToMany"
+	RelationManyToMany = "ManyToMany"
+	RelationOneToOne   = "OneToOne"
+	RelationEmbedded   = "Embedded"
+)
+
+// Entity is a Doctrine entity and its mapping
+type Entity struct {
+	Class      string // App\Entity\User
+	Table      string
+	Repository string // repository class, empty when none
+	File       string
+	Line       int
+	Source     string
+	Fields     []EntityField
+}
+
+// EntityField is a mapped property: a column or an association
+type EntityField struct {
+	Name       string // property
+	Column     string // column, or join column of an owning association; empty for inverse sides
+	Type       string // Doctrine type (string, integer, datetime_immutable...), empty for associations
+	Length     int
+	Nullable   bool
+	ID         bool
+	Relation   string // ManyToOne, OneToMany, ManyToMany, OneToOne, Embedded; empty for columns
+	Target     string // target entity or embeddable class
+	MappedBy   string
+	InversedBy string
+	Line       int
+}
+
+// entityMappings are the attributes and annotations read on entity classes and properties
+const entityMappings = `Entity|Table|Column|Id|ManyToOne|OneToMany|ManyToMany|OneToOne|JoinColumn|Embedded`
+
+var (
+	mappingAttrPattern  = regexp.MustCompile(`(?:^|[^\w\\@$'"])(?:\w+\\)*(` + entityMappings + `)\b(\s*\()?`)
+	mappingAnnotPattern = regexp.MustCompile(`@(?:\w+\\)*(` + entityMappings + `)\b(\s*\()?`)
+	phpPropertyPattern  = regexp.MustCompile(`^\s*(?:(?:public|protected|private|readonly|static)\s+)+(?:(\??[\w\\|]+)\s+)?\$(\w+)`)
+)
+
+// phpColumnTypes maps PHP property types to the Doctrine type inferred for untyped columns
+var phpColumnTypes = map[string]string{
+	"int":               "integer",
+	"string":            "string",
+	"bool":              "boolean",
+	"float":             "float",
+	"array":             "json",
+	"DateTime":          "datetime",
+	"DateTimeImmutable": "datetime_immutable",
+	"DateTimeInterface": "datetime",
+	"DateInterval":      "dateinterval",
+	"Uuid":              "uuid",
+	"Ulid":              "ulid",
+}
+
+// isMappingFile reports whether a file can hold Doctrine mappings
+func isMappingFile(file string) bool {
+	lower := strings.ToLower(file)
+	return strings.HasSuffix(lower, ".php") || strings.HasSuffix(lower, ".orm.xml") ||
+		strings.HasSuffix(lower, ".orm.yml") || strings.HasSuffix(lower, ".orm.yaml")
+}
+
+// extractEntities lists the Doctrine entities mapped in a file: PHP attributes and
+// annotations, or XML/YAML mapping files (*.orm.xml, *.orm.yml)
+func extractEntities(file, content string) []Entity {
+	if !isMappingFile(file) {
+		return nil
+	}
+	file = filepath.ToSlash(file)
+	lower := strings.ToLower(file)
+	switch {
+	case strings.HasSuffix(lower, ".php"):
+		if !strings.Contains(content, "Entity") {
+			return nil
+		}
+		return phpEntities(file, content)
+	case strings.HasSuffix(lower, ".xml"):
+		return xmlEntities(file, content)
+	default:
+		return yamlEntities(file, content)
+	}
+}
+
+// mappingCall is an attribute or annotation with its arguments
+type mappingCall struct {
+	args   string
+	source string
+}
+
+// phpEntities reads the entities of a PHP file from their attributes or annotations
+func phpEntities(file, content string) []Entity {
+	var entities []Entity
+	var current *Entity
+	namespace := ""
+	imports := make(map[string]string)
+	pending := make(map[string]mappingCall)
+
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "//"),
+			strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "#["):
+			continue
+
+		case strings.HasPrefix(trimmed, "#["), strings.HasPrefix(trimmed, "/*"):
+			source, end := MappingAttribute, "]"
+			if strings.HasPrefix(trimmed, "/*") {
+				source, end = MappingAnnotation, "*/"
+			}
+			block := line
+			for !blockClosed(block, end) && i+1 < len(lines) {
+				i++
+				block += "\n" + lines[i]
+			}
+			for name, call := range mappingCalls(block, source) {
+				pending[name] = call
+			}
+			continue
+		}
+
+		if m := phpNamespacePattern.FindStringSubmatch(line); m != nil {
+			namespace = m[1]
+		} else if classes := phpUses(line); len(classes) > 0 {
+			for _, class := range classes {
+				imports[shortClass(class)] = class
+			}
+		} else if m := phpClassPattern.FindStringSubmatch(line); m != nil {
+			if current != nil {
+				entities = append(entities, *current)
+				current = nil
+			}
+			if call, ok := pending["Entity"]; ok {
+				current = phpEntity(file, qualify(m[1], namespace, nil), i+1, call, pending, namespace, imports)
+			}
+		} else if m := phpPropertyPattern.FindStringSubmatch(line); m != nil && current != nil {
+			if field, ok := phpField(m[2], m[1], i+1, pending, namespace, imports); ok {
+				current.Fields = append(current.Fields, field)
+			}
+		}
+		pending = make(map[string]mappingCall)
+	}
+	if current != nil {
+		entities = append(entities, *current)
+	}
+	return entities
+}
+
+// phpEntity builds an entity from the mapping of its class
+func phpEntity(file, class string, line int, entity mappingCall, calls map[string]mappingCall, namespace string, imports map[string]string) *Entity {
+	e := &Entity{Class: class, File: file, Line: line, Source: entity.source}
+	_, named := mappingArgs(entity.args)
+	if repo := named["repositoryClass"]; repo != "" {
+		e.Repository = qualify(repo, namespace, imports)
+	}
+	if table, ok := calls["Table"]; ok {
+		positional, named := mappingArgs(table.args)
+		e.Table = named["name"]
+		if e.Table == "" && len(positional) > 0 {
+			e.Table = positional[0]
+		}
+		e.Table = strings.Trim(e.Table, "`")
+	}
+	if e.Table == "" {
+		e.Table = snakeCase(shortClass(class))
+	}
+	return e
+}
+
+// phpField builds a field from the mapping of a property, ok is false for unmapped properties
+func phpField(name, phpType string, line int, calls map[string]mappingCall, namespace string, imports map[string]string) (EntityField, bool) {
+	f := EntityField{Name: name, Line: line}
+	phpType = strings.TrimPrefix(strings.TrimPrefix(phpType, "?"), `\`)
+	_, f.ID = calls["Id"]
+
+	column, hasColumn := calls["Column"]
+	if hasColumn {
+		positional, named := mappingArgs(column.args)
+		f.Column, f.Type = named["name"], named["type"]
+		if f.Column == "" && len(positional) > 0 {
+			f.Column = positional[0]
+		}
+		if f.Type == "" && len(positional) > 1 {
+			f.Type = positional[1]
+		}
+		f.Length, _ = strconv.Atoi(named["length"])
+		f.Nullable = named["nullable"] == "true"
+	}
+
+	for _, relation := range []string{RelationManyToOne, RelationOneToMany, RelationManyToMany, RelationOneToOne, RelationEmbedded} {
+		call, ok := calls[relation]
+		if !ok {
+			continue
+		}
+		positional, named := mappingArgs(call.args)
+		f.Relation = relation
+		f.Target = named["targetEntity"]
+		if relation == RelationEmbedded {
+			f.Target = named["class"]
+		}
+		if f.Target == "" && len(positional) > 0 {
+			f.Target = positional[0]
+		}
+		if f.Target == "" && !strings.Contains(phpType, "Collection") {
+			f.Target = phpType // inferred from the property type
+		}
+		f.Target = qualify(f.Target, namespace, imports)
+		f.MappedBy, f.InversedBy = named["mappedBy"], named["inversedBy"]
+		break
+	}
+
+	if f.Relation == "" && !hasColumn && !f.ID {
+		return f, false
+	}
+	if f.Relation == "" && f.Type == "" {
+		f.Type = phpColumnTypes[phpType]
+		if f.Type == "" {
+			f.Type = "string"
+		}
+	}
+
+	// Owning sides have a join column, nullable unless told otherwise
+	if f.Relation == RelationManyToOne || f.Relation == RelationOneToOne && f.MappedBy == "" {
+		f.Nullable = true
+		if join, ok := calls["JoinColumn"]; ok {
+			_, named := mappingArgs(join.args)
+			f.Column = named["name"]
+			f.Nullable = named["nullable"] != "false"
+		}
+	}
+	f.Column = defaultColumn(f)
+	return f, true
+}
+
+// defaultColumn is the column of a field under Symfony's default naming strategy
+// (underscore, number aware): firstName is first_name, author is author_id
+func defaultColumn(f EntityField) string {
+	if f.Column != "" {
+		return f.Column
+	}
+	switch f.Relation {
+	case "":
+		return snakeCase(f.Name)
+	case RelationManyToOne:
+		return snakeCase(f.Name) + "_id"
+	case RelationOneToOne:
+		if f.MappedBy == "" {
+			return snakeCase(f.Name) + "_id"
+		}
+	}
+	return ""
+}
+
+// mappingCalls reads the Doctrine attributes or annotations of a block, by name
+func mappingCalls(block, source string) map[string]mappingCall {
+	pattern := mappingAttrPattern
+	if source == MappingAnnotation {
+		block, pattern = docblockText(block), mappingAnnotPattern
+	}
+	calls := make(map[string]mappingCall)
+	for _, m := range pattern.FindAllStringSubmatchIndex(block, -1) {
+		name := block[m[2]:m[3]]
+		call := mappingCall{source: source}
+		if m[4] >= 0 {
+			call.args, _ = callArguments(block[m[5]:])
+		}
+		if _, seen := calls[name]; !seen {
+			calls[name] = call
+		}
+	}
+	return calls
+}
+
+// mappingArgs splits the arguments of an attribute or annotation into positional
+// and named values: strings are unquoted, User::class is User, Types::STRING is string
+func mappingArgs(args string) ([]string, map[string]string) {
+	var positional []string
+	named := make(map[string]string)
+	for _, arg := range splitArguments(args) {
+		if m := namedArgPattern.FindStringSubmatch(arg); m != nil {
+			named[m[1]] = mappingValue(m[2])
+		} else {
+			positional = append(positional, mappingValue(arg))
+		}
+	}
+	return positional, named
+}
+
+// mappingValue reads a scalar argument value
+func mappingValue(v string) string {
+	v = strings.TrimSpace(v)
+	switch {
+	case strings.HasPrefix(v, "'") || strings.HasPrefix(v, `"`):
+		if strs := stringLiterals(v); len(strs) > 0 {
+			return strs[0]
+		}
+	case strings.HasSuffix(v, "::class"):
+		return strings.TrimSuffix(v, "::class")
+	case strings.Contains(v, "::"):
+		return strings.ToLower(v[strings.LastIndex(v, "::")+2:])
+	}
+	return v
+}
+
+// qualify resolves a class name written in a file to its fully qualified name,
+// with the use statements of the file and its namespace
+func qualify(name, namespace string, imports map[string]string) string {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.HasPrefix(name, `\`) {
+		return strings.TrimPrefix(name, `\`)
+	}
+	first, rest, qualified := strings.Cut(name, `\`)
+	if class, ok := imports[first]; ok {
+		if qualified {
+			return class + `\` + rest
+		}
+		return class
+	}
+	if qualified || namespace == "" {
+		// Qualified names are taken as fully qualified, as in annotations and mapping files
+		return name
+	}
+	return namespace + `\` + name
+}
+
+// shortClass returns the class name without its namespace
+func shortClass(class string) string {
+	return class[strings.LastIndex(class, `\`)+1:]
+}
+
+// namespaceOf returns the namespace of a fully qualified class name
+func namespaceOf(class string) string {
+	if i := strings.LastIndex(class, `\`); i >= 0 {
+		return class[:i]
+	}
+	return ""
+}
+
+// snakeCase turns a property or class name into its column or table name: firstName
+// is first_name, address1 is address_1
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 {
+			prev := runes[i-1]
+			switch {
+			case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)),
+				unicode.IsDigit(r) && unicode.IsLetter(prev):
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// xmlEntities reads the <entity> elements of a Doctrine XML mapping file
+func xmlEntities(file, content string) []Entity {
+	var entities []Entity
+	var current *Entity
+
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF || err != nil {
+			break
+		}
+		line := strings.Count(content[:offset], "\n") + 1
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			attrs := make(map[string]string)
+			for _, attr := range t.Attr {
+				attrs[attr.Name.Local] = attr.Value
+			}
+			if t.Name.Local == "entity" {
+				current = &Entity{Class: attrs["name"], Table: attrs["table"], Repository: attrs["repository-class"], File: file, Line: line, Source: MappingXML}
+				if current.Table == "" {
+					current.Table = snakeCase(shortClass(current.Class))
+				}
+				continue
+			}
+			if current == nil {
+				continue
+			}
+			namespace := namespaceOf(current.Class)
+			f := EntityField{Name: attrs["name"], Column: attrs["column"], Type: attrs["type"], Nullable: attrs["nullable"] == "true", Line: line}
+			f.Length, _ = strconv.Atoi(attrs["length"])
+			switch t.Name.Local {
+			case "id", "field":
+				f.ID = t.Name.Local == "id"
+				if f.Type == "" {
+					f.Type = "string"
+				}
+			case "many-to-one", "one-to-many", "many-to-many", "one-to-one":
+				f.Name = attrs["field"]
+				f.Relation = xmlRelations[t.Name.Local]
+				f.Target = qualify(attrs["target-entity"], namespace, nil)
+				f.MappedBy, f.InversedBy = attrs["mapped-by"], attrs["inversed-by"]
+				f.Nullable = f.Relation == RelationManyToOne || f.Relation == RelationOneToOne && f.MappedBy == ""
+			case "embedded":
+				f.Relation, f.Target = RelationEmbedded, qualify(attrs["class"], namespace, nil)
+			case "join-column":
+				// Join column of the association being read
+				if n := len(current.Fields); n > 0 && current.Fields[n-1].Relation != "" && attrs["name"] != "" {
+					current.Fields[n-1].Column = attrs["name"]
+					current.Fields[n-1].Nullable = attrs["nullable"] != "false"
+				}
+				continue
+			default:
+				continue
+			}
+			if f.Name != "" {
+				f.Column = defaultColumn(f)
+				current.Fields = append(current.Fields, f)
+			}
+		case xml.EndElement:
+			if t.Name.Local == "entity" && current != nil {
+				entities = append(entities, *current)
+				current = nil
+			}
+		}
+	}
+	return entities
+}
+
+// xmlRelations maps XML association elements to their kind
+var xmlRelations = map[string]string{
+	"many-to-one":  RelationManyToOne,
+	"one-to-many":  RelationOneToMany,
+	"many-to-many": RelationManyToMany,
+	"one-to-one":   RelationOneToOne,
+}
+
+// yamlRelations maps YAML association sections to their kind
+var yamlRelations = map[string]string{
+	"manyToOne":  RelationManyToOne,
+	"oneToMany":  RelationOneToMany,
+	"manyToMany": RelationManyToMany,
+	"oneToOne":   RelationOneToOne,
+	"embedded":   RelationEmbedded,
+}
+
+// yamlEntities reads the entities of a Doctrine YAML mapping file
+func yamlEntities(file, content string) []Entity {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var entities []Entity
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, mapping := root.Content[i], root.Content[i+1]
+		if mapping.Kind != yaml.MappingNode || yamlValue(mapping, "type") != "entity" {
+			continue
+		}
+		e := Entity{Class: key.Value, Table: yamlValue(mapping, "table"), Repository: yamlValue(mapping, "repositoryClass"), File: file, Line: key.Line, Source: MappingYAML}
+		if e.Table == "" {
+			e.Table = snakeCase(shortClass(e.Class))
+		}
+		namespace := namespaceOf(e.Class)
+
+		for j := 0; j+1 < len(mapping.Content); j += 2 {
+			section, fields := mapping.Content[j].Value, mapping.Content[j+1]
+			if fields.Kind != yaml.MappingNode || (section != "id" && section != "fields" && yamlRelations[section] == "") {
+				continue
+			}
+			for k := 0; k+1 < len(fields.Content); k += 2 {
+				name, def := fields.Content[k], fields.Content[k+1]
+				f := EntityField{Name: name.Value, Line: name.Line, ID: section == "id", Relation: yamlRelations[section]}
+				f.Column, f.Type = yamlValue(def, "column"), yamlValue(def, "type")
+				f.Length, _ = strconv.Atoi(yamlValue(def, "length"))
+				f.Nullable = yamlValue(def, "nullable") == "true"
+				if f.Relation != "" {
+					target := yamlValue(def, "targetEntity")
+					if f.Relation == RelationEmbedded {
+						target = yamlValue(def, "class")
+					}
+					f.Target = qualify(target, namespace, nil)
+					f.MappedBy, f.InversedBy = yamlValue(def, "mappedBy"), yamlValue(def, "inversedBy")
+					owning := f.Relation == RelationManyToOne || f.Relation == RelationOneToOne && f.MappedBy == ""
+					f.Nullable = owning
+					if join := yamlChild(def, "joinColumn"); join != nil && owning {
+						f.Column = yamlValue(join, "name")
+						f.Nullable = yamlValue(join, "nullable") != "false"
+					}
+				} else if f.Type == "" {
+					f.Type = "string"
+				}
+				f.Column = defaultColumn(f)
+				e.Fields = append(e.Fields, f)
+			}
+		}
+		entities = append(entities, e)
+	}
+	return entities
+}
+
+// yamlChild returns the value of a key of a mapping node
+func yamlChild(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlValue returns the scalar value of a key of a mapping node
+func yamlValue(node *yaml.Node, key string) string {
+	if child := yamlChild(node, key); child != nil {
+		return child.Value
+	}
+	return ""
+}
+
+// storeEntities replaces the Doctrine model of the project
+func (idx *Indexer) storeEntities(entities []Entity) error {
+	if _, err := idx.db.Exec("DELETE FROM entities WHERE project_id = $1", idx.projectID); err != nil {
+		return fmt.Errorf("failed to clear entities (run 'oview up' to create the entities table): %w", err)
+	}
+
+	for _, e := range entities {
+		var entityID int
+		err := idx.db.QueryRow(`
+			INSERT INTO entities (project_id, class, name, table_name, repository, file, line, source)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id
+		`, idx.projectID, e.Class, shortClass(e.Class), e.Table, e.Repository, e.File, e.Line, e.Source).Scan(&entityID)
+		if err != nil {
+			return fmt.Errorf("failed to store entity %s: %w", e.Class, err)
+		}
+		if len(e.Fields) == 0 {
+			continue
+		}
+
+		n := len(e.Fields)
+		names, columns, types := make([]string, n), make([]string, n), make([]string, n)
+		relations, targets, mappedBy, inversedBy := make([]string, n), make([]string, n), make([]string, n), make([]string, n)
+		lengths, lines := make([]int64, n), make([]int64, n)
+		nullable, ids := make([]bool, n), make([]bool, n)
+		for i, f := range e.Fields {
+			names[i], columns[i], types[i] = f.Name, f.Column, f.Type
+			relations[i], targets[i], mappedBy[i], inversedBy[i] = f.Relation, f.Target, f.MappedBy, f.InversedBy
+			lengths[i], lines[i], nullable[i], ids[i] = int64(f.Length), int64(f.Line), f.Nullable, f.ID
+		}
+		_, err = idx.db.Exec(`
+			INSERT INTO entity_fields (entity_id, position, name, column_name, type, length, nullable, is_id, relation, target_entity, mapped_by, inversed_by, line)
+			SELECT $1, ord, n, c, t, NULLIF(len, 0), nl, pk, r, te, mb, ib, l
+			FROM unnest($2::text[], $3::text[], $4::text[], $5::int[], $6::bool[], $7::bool[], $8::text[], $9::text[], $10::text[], $11::text[], $12::int[])
+				WITH ORDINALITY AS f(n, c, t, len, nl, pk, r, te, mb, ib, l, ord)
+		`, entityID, pq.Array(names), pq.Array(columns), pq.Array(types), pq.Array(lengths), pq.Array(nullable), pq.Array(ids),
+			pq.Array(relations), pq.Array(targets), pq.Array(mappedBy), pq.Array(inversedBy), pq.Array(lines))
+		if err != nil {
+			return fmt.Errorf("failed to store fields of %s: %w", e.Class, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package indexer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPhpEntitiesAttributes(t *testing.T) {
+	content := `<?php
+namespace App\Entity;
+
+use App\Repository\UserRepository;
+use Doctrine\Common\Collections\Collection;
+use Doctrine\ORM\Mapping as ORM;
+
+#[ORM\Entity(repositoryClass: UserRepository::class)]
+#[ORM\Table(name: '` + "`user_account`" + `')]
+class User
+{
+    #[ORM\Id]
+    #[ORM\GeneratedValue]
+    #[ORM\Column]
+    private ?int $id = null;
+
+    #[ORM\Column(length: 180, nullable: true)]
+    private ?string $email = null;
+
+    #[ORM\Column(name: 'created', type: 'datetime_immutable')]
+    private \DateTimeImmutable $createdAt;
+
+    #[ORM\ManyToOne(inversedBy: 'users')]
+    #[ORM\JoinColumn(nullable: false)]
+    private ?Team $team = null;
+
+    #[ORM\OneToMany(mappedBy: 'author', targetEntity: Post::class)]
+    private Collection $posts;
+
+    #[ORM\Embedded(class: Address::class)]
+    private Address $address;
+
+    private ?string $plainPassword = null;
+}
+`
+	entities := phpEntities("src/Entity/User.php", content)
+	if len(entities) != 1 {
+		t.Fatalf("phpEntities() returned %d entities, want 1", len(entities))
+	}
+	e := entities[0]
+	if e.Class != `App\Entity\User` || e.Table != "user_account" || e.Repository != `App\Repository\UserRepository` || e.Source != MappingAttribute || e.Line != 10 {
+		t.Errorf("entity = %s table %s repository %s (%s line %d)", e.Class, e.Table, e.Repository, e.Source, e.Line)
+	}
+
+	want := []EntityField{
+		{Name: "id", Column: "id", Type: "integer", ID: true, Line: 15},
+		{Name: "email", Column: "email", Type: "string", Length: 180, Nullable: true, Line: 18},
+		{Name: "createdAt", Column: "created", Type: "datetime_immutable", Line: 21},
+		{Name: "team", Column: "team_id", Relation: RelationManyToOne, Target: `App\Entity\Team`, InversedBy: "users", Line: 25},
+		{Name: "posts", Relation: RelationOneToMany, Target: `App\Entity\Post`, MappedBy: "author", Line: 28},
+		{Name: "address", Relation: RelationEmbedded, Target: `App\Entity\Address`, Line: 31},
+	}
+	if !reflect.DeepEqual(e.Fields, want) {
+		t.Errorf("fields =\n%+v\nwant\n%+v", e.Fields, want)
+	}
+}
+
+func TestPhpEntitiesAnnotations(t *testing.T) {
+	content := `<?php
+namespace App\Entity;
+
+use Doctrine\ORM\Mapping as ORM;
+use App\Entity\Catalog\{Product, Category};
+
+/**
+ * @ORM\Entity(repositoryClass="App\Repository\OrderLineRepository")
+ */
+class OrderLine
+{
+    /**
+     * @ORM\Id
+     * @ORM\Column(type="integer")
+     */
+    private $id;
+
+    /**
+     * @ORM\Column(type="decimal", nullable=true)
+     */
+    private $unitPrice;
+
+    /**
+     * @ORM\ManyToOne(targetEntity="Product")
+     * @ORM\JoinColumn(name="product_ref", nullable=false)
+     */
+    private $product;
+
+    /**
+     * @ORM\OneToOne(targetEntity=Category::class)
+     */
+    private $category;
+}
+`
+	entities := phpEntities("src/Entity/OrderLine.php", content)
+	if len(entities) != 1 {
+		t.Fatalf("phpEntities() returned %d entities, want 1", len(entities))
+	}
+	e := entities[0]
+	if e.Table != "order_line" || e.Repository != `App\Repository\OrderLineRepository` || e.Source != MappingAnnotation {
+		t.Errorf("entity = table %s repository %s (%s)", e.Table, e.Repository, e.Source)
+	}
+
+	var got []string
+	for _, f := range e.Fields {
+		got = append(got, f.Name+" -> "+f.Column)
+		if f.Name == "product" && (f.Target != `App\Entity\Catalog\Product` || f.Nullable) {
+			t.Errorf("product = %+v, want a non-null association to Catalog\\Product", f)
+		}
+		if f.Name == "category" && (f.Target != `App\Entity\Catalog\Category` || !f.Nullable) {
+			t.Errorf("category = %+v, want a nullable association to Catalog\\Category", f)
+		}
+	}
+	if want := []string{"id -> id", "unitPrice -> unit_price", "product -> product_ref", "category -> category_id"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("columns = %q, want %q", got, want)
+	}
+
+	unmapped := "<?php\nnamespace App\\Service;\n\nclass EntityManagerFactory\n{\n    #[ORM\\Column]\n    private int $count;\n}\n"
+	if entities := phpEntities("src/Service/EntityManagerFactory.php", unmapped); len(entities) != 0 {
+		t.Errorf("phpEntities() on a service = %+v, want none", entities)
+	}
+}
+
+func TestMappingFileEntities(t *testing.T) {
+	xmlMapping := `<?xml version="1.0" encoding="utf-8"?>
+<doctrine-mapping xmlns="http://doctrine-project.org/schemas/orm/doctrine-mapping">
+    <entity name="App\Entity\Invoice" repository-class="App\Repository\InvoiceRepository">
+        <id name="id" type="integer"><generator strategy="AUTO"/></id>
+        <field name="totalAmount" type="decimal" nullable="true"/>
+        <field name="reference" length="32"/>
+        <many-to-one field="customer" target-entity="Customer" inversed-by="invoices">
+            <join-column name="customer_ref" nullable="false"/>
+        </many-to-one>
+        <one-to-many field="lines" target-entity="App\Entity\InvoiceLine" mapped-by="invoice"/>
+    </entity>
+</doctrine-mapping>
+`
+	yamlMapping := `App\Entity\Invoice:
+    type: entity
+    repositoryClass: App\Repository\InvoiceRepository
+    id:
+        id:
+            type: integer
+    fields:
+        totalAmount:
+            type: decimal
+            nullable: true
+        reference:
+            length: 32
+    manyToOne:
+        customer:
+            targetEntity: Customer
+            inversedBy: invoices
+            joinColumn:
+                name: customer_ref
+                nullable: false
+    oneToMany:
+        lines:
+            targetEntity: App\Entity\InvoiceLine
+            mappedBy: invoice
+App\Entity\Money:
+    type: embeddable
+`
+	want := []EntityField{
+		{Name: "id", Column: "id", Type: "integer", ID: true},
+		{Name: "totalAmount", Column: "total_amount", Type: "decimal", Nullable: true},
+		{Name: "reference", Column: "reference", Type: "string", Length: 32},
+		{Name: "customer", Column: "customer_ref", Relation: RelationManyToOne, Target: `App\Entity\Customer`, InversedBy: "invoices"},
+		{Name: "lines", Relation: RelationOneToMany, Target: `App\Entity\InvoiceLine`, MappedBy: "invoice"},
+	}
+
+	for _, tt := range []struct{ file, content, source string }{
+		{"config/doctrine/Invoice.orm.xml", xmlMapping, MappingXML},
+		{"config/doctrine/Invoice.orm.yml", yamlMapping, MappingYAML},
+	} {
+		t.Run(tt.source, func(t *testing.T) {
+			entities := extractEntities(tt.file, tt.content)
+			if len(entities) != 1 {
+				t.Fatalf("extractEntities() returned %d entities, want 1", len(entities))
+			}
+			e := entities[0]
+			if e.Class != `App\Entity\Invoice` || e.Table != "invoice" || e.Repository != `App\Repository\InvoiceRepository` || e.Source != tt.source || e.File != tt.file {
+				t.Errorf("entity = %+v", e)
+			}
+			for i := range e.Fields {
+				e.Fields[i].Line = 0
+			}
+			if !reflect.DeepEqual(e.Fields, want) {
+				t.Errorf("fields =\n%+v\nwant\n%+v", e.Fields, want)
+			}
+		})
+	}
+
+	if entities := extractEntities("config/services.yaml", yamlMapping); entities != nil {
+		t.Errorf("extractEntities() on a non-mapping file = %+v, want nil", entities)
+	}
+}
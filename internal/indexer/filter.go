@@ -0,0 +1,117 @@
+package indexer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yourusername/oview/internal/config"
+)
+
+// sniffSize is the number of leading bytes inspected for binary or minified content
+const sniffSize = 8000
+
+// SkippedFile records a file that was excluded from indexing and why
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// fileFilter decides whether a candidate file should be indexed
+type fileFilter struct {
+	maxFileSize      int64
+	maxAvgLineLength int
+	indexLockfiles   bool
+	lockfiles        map[string]bool
+}
+
+// newFileFilter builds a filter from the RAG config, falling back to defaults
+func newFileFilter(rules config.FileFilters) *fileFilter {
+	f := &fileFilter{
+		maxFileSize:      rules.MaxFileSize,
+		maxAvgLineLength: rules.MaxAvgLineLength,
+		indexLockfiles:   rules.IndexLockfiles,
+		lockfiles:        make(map[string]bool),
+	}
+
+	if f.maxFileSize <= 0 {
+		f.maxFileSize = config.DefaultMaxFileSize
+	}
+	if f.maxAvgLineLength <= 0 {
+		f.maxAvgLineLength = config.DefaultMaxAvgLineLength
+	}
+
+	lockfiles := rules.Lockfiles
+	if len(lockfiles) == 0 {
+		lockfiles = config.DefaultLockfiles()
+	}
+	for _, name := range lockfiles {
+		f.lockfiles[name] = true
+	}
+
+	return f
+}
+
+// skipReason returns a non-empty reason if the file should not be indexed
+func (f *fileFilter) skipReason(fullPath string, info os.FileInfo) string {
+	basename := filepath.Base(fullPath)
+
+	if !f.indexLockfiles && f.lockfiles[basename] {
+		return "lockfile"
+	}
+
+	if info.Size() > f.maxFileSize {
+		return fmt.Sprintf("too large (%d bytes > %d)", info.Size(), f.maxFileSize)
+	}
+
+	if isMinifiedName(basename) {
+		return "minified (file name)"
+	}
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return fmt.Sprintf("unreadable: %v", err)
+	}
+	defer file.Close()
+
+	// Only the head is read: the indexer reads the files it keeps
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return fmt.Sprintf("unreadable: %v", err)
+	}
+	head = head[:n]
+
+	if isBinary(head) {
+		return "binary content"
+	}
+
+	if avg := averageLineLength(head); avg > f.maxAvgLineLength {
+		return fmt.Sprintf("minified (avg line length %d > %d)", avg, f.maxAvgLineLength)
+	}
+
+	return ""
+}
+
+// isMinifiedName detects conventional names of minified bundles
+func isMinifiedName(basename string) bool {
+	lower := strings.ToLower(basename)
+	return strings.Contains(lower, ".min.") || strings.HasSuffix(lower, ".bundle.js")
+}
+
+// isBinary reports whether content contains a NUL byte
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content, 0) != -1
+}
+
+// averageLineLength returns the mean number of bytes per line
+func averageLineLength(content []byte) int {
+	if len(content) == 0 {
+		return 0
+	}
+	lines := bytes.Count(content, []byte("\n")) + 1
+	return len(content) / lines
+}
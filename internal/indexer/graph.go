@@ -0,0 +1,412 @@
+package indexer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Edge kinds stored in the edges table
+const (
+	EdgeUse     = "use"     // PHP use statement
+	EdgeImport  = "import"  // JS/TS import or export ... from
+	EdgeRequire = "require" // CommonJS require
+	EdgeExtends = "extends" // Twig extends
+	EdgeInclude = "include" // Twig include, embed, import, from, use
+	EdgeRender  = "render"  // PHP controller rendering a Twig template
+	EdgeService = "service" // Symfony service definition or reference in YAML
+)
+
+// Edge is a dependency of a file on another file, class, module, template or service
+type Edge struct {
+	From   string
+	To     string // project file the target resolves to, empty when outside the project
+	Target string // as written: class name, module specifier, template or @service id
+	Kind   string
+	Line   int
+}
+
+var (
+	jsFromPattern    = regexp.MustCompile(`\bfrom\s*['"]([^'"]+)['"]`)         // import/export ... from, also closing multi-line imports
+	jsImportPattern  = regexp.MustCompile(`\bimport\s*\(?\s*['"]([^'"]+)['"]`) // side-effect and dynamic imports
+	jsRequirePattern = regexp.MustCompile(`\brequire\(\s*['"]([^'"]+)['"]\s*\)`)
+	twigTagPattern   = regexp.MustCompile(`\{%-?\s*(extends|include|embed|import|from|use)\s+['"]([^'"]+)['"]`)
+	twigFuncPattern  = regexp.MustCompile(`\b(?:include|source)\(\s*['"]([^'"]+)['"]`)
+	phpRenderPattern = regexp.MustCompile(`(?:render|renderView|renderBlock|Template)\(\s*['"]([^'"]+\.twig)['"]`)
+	yamlKeyPattern   = regexp.MustCompile(`^(\s*)['"]?([^'"\s:#{}\[\]-][^'":#]*?)['"]?\s*:(?:\s|$)`)
+	yamlRefPattern   = regexp.MustCompile(`@\??([A-Za-z0-9_.\\]+)`)
+	yamlClassPattern = regexp.MustCompile(`\b[A-Z][A-Za-z0-9_]*(?:\\\\?[A-Z][A-Za-z0-9_]*)+\b`)
+)
+
+// extractEdges lists the dependencies written in a file. Targets are left
+// unresolved: the resolver maps them to project files once every file is known.
+// Services defined in YAML files are added to services (id -> class).
+func extractEdges(file, content string, services map[string]string) []Edge {
+	ext := strings.ToLower(filepath.Ext(file))
+	var edges []Edge
+	add := func(target, kind string, line int) {
+		if target = strings.TrimSpace(target); target != "" {
+			edges = append(edges, Edge{From: file, Target: target, Kind: kind, Line: line})
+		}
+	}
+
+	lines := strings.Split(content, "\n")
+	switch ext {
+	case ".php":
+		for i, line := range lines {
+			for _, class := range phpUses(line) {
+				add(class, EdgeUse, i+1)
+			}
+			for _, m := range phpRenderPattern.FindAllStringSubmatch(line, -1) {
+				add(m[1], EdgeRender, i+1)
+			}
+		}
+
+	case ".js", ".ts", ".jsx", ".tsx", ".mjs", ".cjs", ".vue", ".svelte", ".astro":
+		for i, line := range lines {
+			for _, m := range jsFromPattern.FindAllStringSubmatch(line, -1) {
+				add(m[1], EdgeImport, i+1)
+			}
+			for _, m := range jsImportPattern.FindAllStringSubmatch(line, -1) {
+				add(m[1], EdgeImport, i+1)
+			}
+			for _, m := range jsRequirePattern.FindAllStringSubmatch(line, -1) {
+				add(m[1], EdgeRequire, i+1)
+			}
+		}
+
+	case ".twig":
+		for i, line := range lines {
+			for _, m := range twigTagPattern.FindAllStringSubmatch(line, -1) {
+				kind := EdgeInclude
+				if m[1] == "extends" {
+					kind = EdgeExtends
+				}
+				add(m[2], kind, i+1)
+			}
+			for _, m := range twigFuncPattern.FindAllStringSubmatch(line, -1) {
+				add(m[1], EdgeInclude, i+1)
+			}
+		}
+
+	case ".yaml", ".yml":
+		edges = append(edges, serviceEdges(file, lines, services)...)
+	}
+
+	return dedupeEdges(edges)
+}
+
+// phpUses returns the classes imported by a top-level PHP use statement, including
+// grouped imports (use App\Entity\{User, Order}). Function and const imports,
+// closures and trait uses (indented) are ignored.
+func phpUses(line string) []string {
+	if !strings.HasPrefix(line, "use ") {
+		return nil
+	}
+	stmt := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line[4:]), ";"))
+	if strings.HasPrefix(stmt, "function ") || strings.HasPrefix(stmt, "const ") {
+		return nil
+	}
+
+	var classes []string
+	clean := func(name string) string {
+		if i := strings.Index(strings.ToLower(name), " as "); i >= 0 {
+			name = name[:i]
+		}
+		return strings.TrimPrefix(strings.TrimSpace(name), `\`)
+	}
+	if open := strings.Index(stmt, "{"); open >= 0 {
+		prefix := stmt[:open]
+		group := strings.TrimSuffix(stmt[open+1:], "}")
+		for _, item := range strings.Split(group, ",") {
+			if item = clean(item); item != "" {
+				classes = append(classes, clean(prefix)+item)
+			}
+		}
+		return classes
+	}
+	for _, item := range strings.Split(stmt, ",") {
+		if item = clean(item); item != "" {
+			classes = append(classes, item)
+		}
+	}
+	return classes
+}
+
+// serviceEdges reads the services section of a Symfony YAML file: service classes
+// (class: or FQCN ids), @service references and FQCNs in arguments. Service ids
+// with a class are recorded in services.
+func serviceEdges(file string, lines []string, services map[string]string) []Edge {
+	var edges []Edge
+	inServices := false
+	serviceIndent := -1
+	current := ""
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent == 0 {
+			inServices = strings.HasPrefix(trimmed, "services:")
+			serviceIndent, current = -1, ""
+			continue
+		}
+		if !inServices {
+			continue
+		}
+
+		key, value := "", ""
+		if m := yamlKeyPattern.FindStringSubmatch(line); m != nil {
+			key = m[2]
+			value = strings.TrimSpace(line[len(m[0]):])
+		}
+		if serviceIndent == -1 {
+			serviceIndent = indent
+		}
+
+		// A service definition: "app.mailer:" or "App\Service\Mailer: ~"
+		if indent == serviceIndent && key != "" {
+			current = key
+			if strings.HasPrefix(key, "_") {
+				current = "" // _defaults, _instanceof
+				continue
+			}
+			if strings.Contains(key, `\`) && !strings.HasSuffix(key, `\`) {
+				services[key] = key
+				edges = append(edges, Edge{From: file, Target: key, Kind: EdgeService, Line: i + 1})
+			}
+			if strings.HasPrefix(value, "'@") || strings.HasPrefix(value, "@") || strings.HasPrefix(value, `"@`) {
+				// alias: app.mailer: '@App\Service\Mailer'
+				services[key] = strings.Trim(value, `'"@`)
+				edges = append(edges, Edge{From: file, Target: "@" + services[key], Kind: EdgeService, Line: i + 1})
+			}
+			continue
+		}
+		if current == "" || key == "resource" || key == "exclude" || key == "namespace" {
+			continue
+		}
+
+		if key == "class" {
+			class := strings.Trim(value, `'" `)
+			services[current] = class
+			edges = append(edges, Edge{From: file, Target: class, Kind: EdgeService, Line: i + 1})
+			continue
+		}
+		for _, m := range yamlRefPattern.FindAllStringSubmatch(trimmed, -1) {
+			edges = append(edges, Edge{From: file, Target: "@" + m[1], Kind: EdgeService, Line: i + 1})
+		}
+		for _, class := range yamlClassPattern.FindAllString(yamlRefPattern.ReplaceAllString(trimmed, ""), -1) {
+			edges = append(edges, Edge{From: file, Target: strings.ReplaceAll(class, `\\`, `\`), Kind: EdgeService, Line: i + 1})
+		}
+	}
+	return edges
+}
+
+// dedupeEdges keeps the first edge per target and kind
+func dedupeEdges(edges []Edge) []Edge {
+	seen := make(map[string]bool)
+	out := edges[:0]
+	for _, e := range edges {
+		key := e.Kind + "\x00" + e.Target
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, e)
+	}
+	return out
+}
+
+// edgeResolver maps edge targets to project files
+type edgeResolver struct {
+	projectPath string
+	psr4        []psr4Prefix
+	services    map[string]string
+}
+
+// psr4Prefix is a namespace prefix of composer.json autoload.psr-4
+type psr4Prefix struct {
+	namespace string
+	dirs      []string
+}
+
+// newEdgeResolver reads the PSR-4 autoload rules of composer.json, if any
+func newEdgeResolver(projectPath string, services map[string]string) *edgeResolver {
+	r := &edgeResolver{projectPath: projectPath, services: services}
+
+	data, err := os.ReadFile(filepath.Join(projectPath, "composer.json"))
+	if err != nil {
+		return r
+	}
+	var composer struct {
+		Autoload struct {
+			PSR4 map[string]interface{} `json:"psr-4"`
+		} `json:"autoload"`
+		AutoloadDev struct {
+			PSR4 map[string]interface{} `json:"psr-4"`
+		} `json:"autoload-dev"`
+	}
+	if err := json.Unmarshal(data, &composer); err != nil {
+		return r
+	}
+
+	for _, rules := range []map[string]interface{}{composer.Autoload.PSR4, composer.AutoloadDev.PSR4} {
+		for namespace, dirs := range rules {
+			prefix := psr4Prefix{namespace: namespace}
+			switch d := dirs.(type) {
+			case string:
+				prefix.dirs = []string{d}
+			case []interface{}:
+				for _, item := range d {
+					if s, ok := item.(string); ok {
+						prefix.dirs = append(prefix.dirs, s)
+					}
+				}
+			}
+			r.psr4 = append(r.psr4, prefix)
+		}
+	}
+	// Longest namespace first, so App\Tests\ wins over App\
+	sort.Slice(r.psr4, func(i, j int) bool {
+		return len(r.psr4[i].namespace) > len(r.psr4[j].namespace)
+	})
+	return r
+}
+
+// resolve sets the project file each edge points to, when there is one
+func (r *edgeResolver) resolve(edges []Edge) {
+	for i := range edges {
+		e := &edges[i]
+		switch e.Kind {
+		case EdgeUse:
+			e.To = r.class(e.Target)
+		case EdgeImport, EdgeRequire:
+			e.To = r.module(e.From, e.Target)
+		case EdgeExtends, EdgeInclude, EdgeRender:
+			e.To = r.template(e.Target)
+		case EdgeService:
+			target := e.Target
+			if strings.HasPrefix(target, "@") {
+				target = r.serviceClass(strings.TrimPrefix(target, "@"))
+			}
+			e.To = r.class(target)
+		}
+	}
+}
+
+// serviceClass follows service ids and aliases to a class name
+func (r *edgeResolver) serviceClass(id string) string {
+	for hops := 0; hops < 5; hops++ {
+		class, ok := r.services[id]
+		if !ok || class == id {
+			break
+		}
+		id = class
+	}
+	return id
+}
+
+// class maps a fully qualified class name to its file with the PSR-4 rules
+func (r *edgeResolver) class(name string) string {
+	name = strings.TrimPrefix(name, `\`)
+	for _, p := range r.psr4 {
+		if !strings.HasPrefix(name, p.namespace) {
+			continue
+		}
+		rel := strings.ReplaceAll(strings.TrimPrefix(name, p.namespace), `\`, "/") + ".php"
+		for _, dir := range p.dirs {
+			if file := r.existing(path.Join(dir, rel)); file != "" {
+				return file
+			}
+		}
+	}
+	return ""
+}
+
+// module maps a JS/TS module specifier to a file: relative specifiers from the
+// importing file, "@/" and "~/" from src/. Packages stay unresolved.
+func (r *edgeResolver) module(from, spec string) string {
+	var base string
+	switch {
+	case strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../"):
+		base = path.Join(path.Dir(filepath.ToSlash(from)), spec)
+	case strings.HasPrefix(spec, "@/") || strings.HasPrefix(spec, "~/"):
+		base = path.Join("src", spec[2:])
+	default:
+		return ""
+	}
+
+	candidates := []string{base}
+	for _, ext := range []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".vue", ".svelte"} {
+		candidates = append(candidates, base+ext)
+	}
+	for _, index := range []string{"index.ts", "index.tsx", "index.js", "index.jsx"} {
+		candidates = append(candidates, path.Join(base, index))
+	}
+	for _, c := range candidates {
+		if file := r.existing(c); file != "" {
+			return file
+		}
+	}
+	return ""
+}
+
+// template maps a Twig template name to its file under templates/ (bundle
+// templates such as @Twig/... stay unresolved)
+func (r *edgeResolver) template(name string) string {
+	if strings.HasPrefix(name, "@") {
+		return ""
+	}
+	return r.existing(path.Join("templates", name))
+}
+
+// existing returns rel if it is a regular file of the project
+func (r *edgeResolver) existing(rel string) string {
+	rel = path.Clean(rel)
+	if strings.HasPrefix(rel, "../") {
+		return ""
+	}
+	info, err := os.Stat(filepath.Join(r.projectPath, filepath.FromSlash(rel)))
+	if err != nil || info.IsDir() {
+		return ""
+	}
+	return rel
+}
+
+// storeEdges replaces the dependency graph of the project
+func (idx *Indexer) storeEdges(edges []Edge) error {
+	if _, err := idx.db.Exec("DELETE FROM edges WHERE project_id = $1", idx.projectID); err != nil {
+		return fmt.Errorf("failed to clear edges (run 'oview up' to create the edges table): %w", err)
+	}
+	if len(edges) == 0 {
+		return nil
+	}
+
+	from := make([]string, len(edges))
+	to := make([]string, len(edges))
+	targets := make([]string, len(edges))
+	kinds := make([]string, len(edges))
+	lines := make([]int64, len(edges))
+	for i, e := range edges {
+		from[i], to[i], targets[i], kinds[i], lines[i] = e.From, e.To, e.Target, e.Kind, int64(e.Line)
+	}
+
+	_, err := idx.db.Exec(`
+		INSERT INTO edges (project_id, from_path, to_path, target, kind, line)
+		SELECT $1, f, NULLIF(t, ''), g, k, l
+		FROM unnest($2::text[], $3::text[], $4::text[], $5::text[], $6::int[]) AS e(f, t, g, k, l)
+	`, idx.projectID, pq.Array(from), pq.Array(to), pq.Array(targets), pq.Array(kinds), pq.Array(lines))
+	if err != nil {
+		return fmt.Errorf("failed to store edges: %w", err)
+	}
+	return nil
+}
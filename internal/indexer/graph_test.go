@@ -0,0 +1,217 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtractEdges(t *testing.T) {
+	tests := []struct {
+		file    string
+		content string
+		want    []Edge
+	}{
+		{
+			file: "src/Controller/UserController.php",
+			content: `<?php
+namespace App\Controller;
+
+use App\Entity\User;
+use App\Repository\{UserRepository, TeamRepository as Teams};
+use function App\Util\slugify;
+
+class UserController
+{
+    use TargetPathTrait;
+
+    public function show(User $user): Response
+    {
+        return $this->render('user/show.html.twig', ['user' => $user]);
+    }
+}
+`,
+			want: []Edge{
+				{From: "src/Controller/UserController.php", Target: `App\Entity\User`, Kind: EdgeUse, Line: 4},
+				{From: "src/Controller/UserController.php", Target: `App\Repository\UserRepository`, Kind: EdgeUse, Line: 5},
+				{From: "src/Controller/UserController.php", Target: `App\Repository\TeamRepository`, Kind: EdgeUse, Line: 5},
+				{From: "src/Controller/UserController.php", Target: "user/show.html.twig", Kind: EdgeRender, Line: 14},
+			},
+		},
+		{
+			file: "assets/app.ts",
+			content: `import { createApp } from 'vue'
+import App from './App.vue'
+import {
+  api,
+} from '@/lib/api'
+import './styles/app.css'
+const legacy = require('../legacy')
+const Chart = () => import('./Chart.vue')
+import { createApp as again } from 'vue'
+`,
+			want: []Edge{
+				{From: "assets/app.ts", Target: "vue", Kind: EdgeImport, Line: 1},
+				{From: "assets/app.ts", Target: "./App.vue", Kind: EdgeImport, Line: 2},
+				{From: "assets/app.ts", Target: "@/lib/api", Kind: EdgeImport, Line: 5},
+				{From: "assets/app.ts", Target: "./styles/app.css", Kind: EdgeImport, Line: 6},
+				{From: "assets/app.ts", Target: "../legacy", Kind: EdgeRequire, Line: 7},
+				{From: "assets/app.ts", Target: "./Chart.vue", Kind: EdgeImport, Line: 8},
+			},
+		},
+		{
+			file: "templates/user/show.html.twig",
+			content: `{% extends 'base.html.twig' %}
+{% block body %}
+    {%- include "user/_card.html.twig" with {user: user} %}
+    {{ include('@Twig/Exception/error.html.twig') }}
+{% endblock %}
+`,
+			want: []Edge{
+				{From: "templates/user/show.html.twig", Target: "base.html.twig", Kind: EdgeExtends, Line: 1},
+				{From: "templates/user/show.html.twig", Target: "user/_card.html.twig", Kind: EdgeInclude, Line: 3},
+				{From: "templates/user/show.html.twig", Target: "@Twig/Exception/error.html.twig", Kind: EdgeInclude, Line: 4},
+			},
+		},
+		{
+			file:    "README.md",
+			content: "use App\\Entity\\User;\nimport x from 'y'\n",
+			want:    []Edge{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			got := extractEdges(tt.file, tt.content, map[string]string{})
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractEdges() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhpUses(t *testing.T) {
+	tests := map[string][]string{
+		`use \App\Entity\User;`:                            {`App\Entity\User`},
+		`use App\Entity\User AS Account;`:                  {`App\Entity\User`},
+		`use App\Entity\User, App\Entity\Order;`:           {`App\Entity\User`, `App\Entity\Order`},
+		`use App\{Entity\User, Service\Mailer,};`:          {`App\Entity\User`, `App\Service\Mailer`},
+		`use const App\Config\VERSION;`:                    nil,
+		`$fn = function () use ($user) { return $user; };`: nil,
+		`// use App\Entity\User;`:                          nil,
+	}
+	for line, want := range tests {
+		if got := phpUses(line); !reflect.DeepEqual(got, want) {
+			t.Errorf("phpUses(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestServiceEdges(t *testing.T) {
+	content := `parameters:
+    app.sender: 'noreply@example.com'
+
+services:
+    _defaults:
+        autowire: true
+
+    App\:
+        resource: '../src/'
+        exclude: '../src/{Entity,Kernel.php}'
+
+    App\Service\Mailer:
+        arguments:
+            $transport: '@mailer.transport'
+
+    app.mailer: '@App\Service\Mailer'
+
+    app.notifier:
+        class: App\Service\Notifier
+        arguments: ['@?app.mailer', !tagged_iterator App\Channel\ChannelInterface]
+`
+	services := map[string]string{}
+	edges := extractEdges("config/services.yaml", content, services)
+
+	edge := func(target string, line int) Edge {
+		return Edge{From: "config/services.yaml", Target: target, Kind: EdgeService, Line: line}
+	}
+	want := []Edge{
+		edge(`App\Service\Mailer`, 12),
+		edge("@mailer.transport", 14),
+		edge(`@App\Service\Mailer`, 16),
+		edge(`App\Service\Notifier`, 19),
+		edge("@app.mailer", 20),
+		edge(`App\Channel\ChannelInterface`, 20),
+	}
+	if !reflect.DeepEqual(edges, want) {
+		t.Errorf("edges =\n%+v\nwant\n%+v", edges, want)
+	}
+	wantServices := map[string]string{
+		`App\Service\Mailer`: `App\Service\Mailer`,
+		"app.mailer":         `App\Service\Mailer`,
+		"app.notifier":       `App\Service\Notifier`,
+	}
+	if !reflect.DeepEqual(services, wantServices) {
+		t.Errorf("services = %v, want %v", services, wantServices)
+	}
+}
+
+func TestEdgeResolver(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"composer.json":                 `{"autoload": {"psr-4": {"App\\": "src/"}}, "autoload-dev": {"psr-4": {"App\\Tests\\": ["tests/"]}}}`,
+		"src/Service/Mailer.php":        "",
+		"tests/Service/MailerTest.php":  "",
+		"assets/components/Card.vue":    "",
+		"assets/lib/index.ts":           "",
+		"src/lib/api.ts":                "",
+		"templates/user/show.html.twig": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := newEdgeResolver(root, map[string]string{"app.mailer": "mailer.alias", "mailer.alias": `App\Service\Mailer`})
+	edges := []Edge{
+		{From: "src/Controller/A.php", Target: `App\Service\Mailer`, Kind: EdgeUse},
+		{From: "tests/A.php", Target: `\App\Tests\Service\MailerTest`, Kind: EdgeUse},
+		{From: "src/Controller/A.php", Target: `Symfony\Component\Mailer\Mailer`, Kind: EdgeUse},
+		{From: "assets/components/List.vue", Target: "./Card.vue", Kind: EdgeImport},
+		{From: "assets/components/List.vue", Target: "../lib", Kind: EdgeImport},
+		{From: "assets/components/List.vue", Target: "@/lib/api", Kind: EdgeImport},
+		{From: "assets/components/List.vue", Target: "../../../etc/passwd", Kind: EdgeImport},
+		{From: "assets/components/List.vue", Target: "vue", Kind: EdgeImport},
+		{From: "src/Controller/A.php", Target: "user/show.html.twig", Kind: EdgeRender},
+		{From: "templates/base.html.twig", Target: "@Twig/layout.html.twig", Kind: EdgeExtends},
+		{From: "config/services.yaml", Target: "@app.mailer", Kind: EdgeService},
+	}
+	r.resolve(edges)
+
+	want := []string{
+		"src/Service/Mailer.php",
+		"tests/Service/MailerTest.php",
+		"",
+		"assets/components/Card.vue",
+		"assets/lib/index.ts",
+		"src/lib/api.ts",
+		"",
+		"",
+		"templates/user/show.html.twig",
+		"",
+		"src/Service/Mailer.php",
+	}
+	for i, e := range edges {
+		if e.To != want[i] {
+			t.Errorf("%s resolves to %q, want %q", e.Target, e.To, want[i])
+		}
+	}
+}
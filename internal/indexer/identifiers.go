@@ -0,0 +1,141 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Identifier kinds stored in the identifiers table
+const (
+	IdentifierDefinition = "definition"
+	IdentifierReference  = "reference"
+)
+
+// Identifier is an identifier referenced on a line of a chunk
+type Identifier struct {
+	Name string
+	Line int    // line within the trimmed chunk content, 0-based
+	Kind string // definition or reference
+}
+
+var (
+	identifierPattern = regexp.MustCompile(`[A-Za-z_$][A-Za-z0-9_$]*`)
+	// Dotted names such as Symfony service ids (app.mailer) or config keys (framework.session)
+	dottedPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)+`)
+	// Declarations in the languages we chunk: PHP, JS/TS, SQL tables and views, Twig blocks and macros
+	declarationPattern = regexp.MustCompile(`\b(?:function|class|interface|trait|enum|const|let|var|def|fn|func|type|macro|block|TABLE|table|VIEW|view)\s+&?\$?([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// Identifier length bounds: short names (i, id, db) would flood the index, and
+// the identifier column is a VARCHAR(255)
+const (
+	minIdentifierLength = 3
+	maxIdentifierLength = 255
+)
+
+// identifierStopWords are lowercase language keywords and builtin types, never worth indexing
+var identifierStopWords = func() map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.Fields(`
+		abstract and array as async await bool boolean break case catch class clone const
+		continue declare default define die do echo else elseif empty enddeclare endfor
+		endforeach endif endswitch endwhile enum eval exit export extends false final
+		finally float fn for foreach from function goto if implements import include
+		include_once instanceof insteadof int interface isset let mixed namespace never
+		new null number object or parent private protected public readonly require
+		require_once return self static string switch this throw trait true try typeof
+		undefined unset use var void while with xor yield endblock endmacro
+		`) {
+		words[w] = true
+	}
+	return words
+}()
+
+// extractIdentifiers lists the identifiers each line of a chunk references, once per
+// line. Names declared on a line (function login, class User) are marked as definitions.
+// Dotted names are kept whole in config chunks and in quoted strings, so service ids
+// and config keys can be looked up as such.
+func extractIdentifiers(chunk Chunk) []Identifier {
+	var ids []Identifier
+	for i, line := range strings.Split(strings.TrimSpace(chunk.Content), "\n") {
+		seen := make(map[string]bool)
+		add := func(name, kind string) {
+			if seen[name] || len(name) > maxIdentifierLength {
+				return
+			}
+			seen[name] = true
+			ids = append(ids, Identifier{Name: name, Line: i, Kind: kind})
+		}
+
+		for _, m := range declarationPattern.FindAllStringSubmatch(line, -1) {
+			if len(m[1]) >= minIdentifierLength {
+				add(m[1], IdentifierDefinition)
+			}
+		}
+		for _, loc := range dottedPattern.FindAllStringIndex(line, -1) {
+			quoted := loc[0] > 0 && strings.ContainsRune(`'"`, rune(line[loc[0]-1]))
+			if chunk.Type == "config" || quoted {
+				add(line[loc[0]:loc[1]], IdentifierReference)
+			}
+		}
+		for _, name := range identifierPattern.FindAllString(line, -1) {
+			name = strings.TrimLeft(name, "$")
+			if len(name) < minIdentifierLength || identifierStopWords[name] {
+				continue
+			}
+			add(name, IdentifierReference)
+		}
+	}
+	return ids
+}
+
+// indexIdentifiers adds a stored chunk to the identifier index. The index is best
+// effort: a missing table disables it for the rest of the run, any other failure
+// only skips the chunk
+func (idx *Indexer) indexIdentifiers(chunkID int, chunk Chunk) {
+	if idx.identifiersErr != nil {
+		return
+	}
+	if err := idx.storeIdentifiers(chunkID, chunk); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "42P01" { // undefined_table
+			idx.identifiersErr = err
+			return
+		}
+		idx.identifierFailures++
+		idx.identifierFailure = err
+	}
+}
+
+// storeIdentifiers adds the identifiers of a stored chunk to the inverted index.
+// Documentation is left out: prose would bury the call sites.
+func (idx *Indexer) storeIdentifiers(chunkID int, chunk Chunk) error {
+	if chunk.Type == "doc" {
+		return nil
+	}
+	ids := extractIdentifiers(chunk)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(ids))
+	lines := make([]int64, len(ids))
+	kinds := make([]string, len(ids))
+	for i, id := range ids {
+		names[i], lines[i], kinds[i] = id.Name, int64(id.Line), id.Kind
+	}
+
+	_, err := idx.db.Exec(`
+		INSERT INTO identifiers (chunk_id, project_id, identifier, line, kind)
+		SELECT $1, $2, * FROM unnest($3::text[], $4::int[], $5::text[])
+		ON CONFLICT DO NOTHING
+	`, chunkID, idx.projectID, pq.Array(names), pq.Array(lines), pq.Array(kinds))
+	if err != nil {
+		return fmt.Errorf("failed to store identifiers (run 'oview up' to create the identifiers table): %w", err)
+	}
+	return nil
+}
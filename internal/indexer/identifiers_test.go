@@ -0,0 +1,68 @@
+package indexer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractIdentifiers(t *testing.T) {
+	def := func(name string, line int) Identifier {
+		return Identifier{Name: name, Line: line, Kind: IdentifierDefinition}
+	}
+	ref := func(name string, line int) Identifier {
+		return Identifier{Name: name, Line: line, Kind: IdentifierReference}
+	}
+
+	tests := []struct {
+		name  string
+		chunk Chunk
+		want  []Identifier
+	}{
+		{
+			name: "php method",
+			chunk: Chunk{Type: "code", Content: `
+    public function login(Request $request): Response
+    {
+        $user = $this->users->find($request->get('id'));
+        return $this->render('user.html.twig');
+    }
+`},
+			want: []Identifier{
+				def("login", 0), ref("Request", 0), ref("request", 0), ref("Response", 0),
+				ref("user", 2), ref("users", 2), ref("find", 2), ref("request", 2), ref("get", 2),
+				ref("user.html.twig", 3), ref("render", 3), ref("user", 3), ref("html", 3), ref("twig", 3),
+			},
+		},
+		{
+			name:  "dotted names outside quotes are split in code",
+			chunk: Chunk{Type: "code", Content: "const label = user.name ?? fallback.name"},
+			want:  []Identifier{def("label", 0), ref("user", 0), ref("name", 0), ref("fallback", 0)},
+		},
+		{
+			name:  "dotted names are kept in config",
+			chunk: Chunk{Type: "config", Content: "framework.session:\n    handler_id: app.session_handler"},
+			want: []Identifier{
+				ref("framework.session", 0), ref("framework", 0), ref("session", 0),
+				ref("app.session_handler", 1), ref("handler_id", 1), ref("app", 1), ref("session_handler", 1),
+			},
+		},
+		{
+			name:  "sql table",
+			chunk: Chunk{Type: "code", Content: "CREATE TABLE user_account (email VARCHAR(180))"},
+			want:  []Identifier{def("user_account", 0), ref("CREATE", 0), ref("TABLE", 0), ref("email", 0), ref("VARCHAR", 0)},
+		},
+		{
+			name:  "names over the column size are dropped",
+			chunk: Chunk{Type: "code", Content: "call(" + strings.Repeat("x", 256) + ", 'a." + strings.Repeat("b", 254) + "')"},
+			want:  []Identifier{ref("call", 0), ref(strings.Repeat("b", 254), 0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractIdentifiers(tt.chunk); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractIdentifiers() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
@@ -25,17 +25,28 @@ type Indexer struct {
 	embedder       embeddings.Generator
 	embeddingModel string // Model name to store in DB
 	ragConfig      *config.RAGConfig
+	filter         *fileFilter
+	identifiersErr error // set when the identifiers table is missing, disables the index
+	stack          config.StackInfo
+
+	identifierFailures int   // chunks whose identifiers could not be stored
+	identifierFailure  error // last of those failures
 }
 
 // Stats tracks indexing statistics
 type Stats struct {
-	CommitSHA    string    `json:"commit_sha"`
-	FilesIndexed int       `json:"files_indexed"`
-	ChunksStored int       `json:"chunks_stored"`
-	TotalBytes   int64     `json:"total_bytes"`
-	StartTime    time.Time `json:"start_time"`
-	EndTime      time.Time `json:"end_time"`
-	Duration     string    `json:"duration"`
+	CommitSHA      string        `json:"commit_sha"`
+	FilesIndexed   int           `json:"files_indexed"`
+	ChunksStored   int           `json:"chunks_stored"`
+	EdgesStored    int           `json:"edges_stored"`
+	RoutesStored   int           `json:"routes_stored"`
+	EntitiesStored int           `json:"entities_stored"`
+	FilesSkipped   int           `json:"files_skipped"`
+	TotalBytes     int64         `json:"total_bytes"`
+	StartTime      time.Time     `json:"start_time"`
+	EndTime        time.Time     `json:"end_time"`
+	Duration       string        `json:"duration"`
+	Skipped        []SkippedFile `json:"skipped,omitempty"`
 }
 
 // Manifest tracks indexed files
@@ -72,9 +83,16 @@ func New(projectPath, projectID string, db *sql.DB, ragConfig *config.RAGConfig,
 		embedder:       embedder,
 		embeddingModel: embeddingModel,
 		ragConfig:      ragConfig,
+		filter:         newFileFilter(ragConfig.Indexing.Filters),
 	}
 }
 
+// SetStack enables the framework-specific extraction for the detected stack
+// (Symfony routes, Doctrine entities)
+func (idx *Indexer) SetStack(stack config.StackInfo) {
+	idx.stack = stack
+}
+
 // Index indexes the entire project
 func (idx *Indexer) Index() (*Stats, error) {
 	stats := &Stats{
@@ -85,12 +103,14 @@ func (idx *Indexer) Index() (*Stats, error) {
 	stats.CommitSHA = idx.getGitCommitSHA()
 
 	// Scan files
-	files, err := idx.scanFiles()
+	files, skipped, err := idx.scanFiles()
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan files: %w", err)
 	}
+	stats.Skipped = skipped
+	stats.FilesSkipped = len(skipped)
 
-	fmt.Printf("Found %d files to index\n", len(files))
+	fmt.Printf("Found %d files to index (%d skipped)\n", len(files), len(skipped))
 
 	// Clear existing chunks for this project
 	if err := idx.clearExistingChunks(); err != nil {
@@ -102,10 +122,19 @@ func (idx *Indexer) Index() (*Stats, error) {
 		LastUpdate: time.Now(),
 	}
 
+	// Dependency graph, routes and entities, resolved once every file has been read
+	var edges []Edge
+	var routes []Route
+	var routeImports []routeImport
+	var entities []Entity
+	services := make(map[string]string)
+
 	// Process each file
 	for i, file := range files {
 		fmt.Printf("[%d/%d] Indexing %s...\n", i+1, len(files), file)
 
+		// Taken before reading: a save during chunking and embedding still shows as stale
+		readAt := time.Now()
 		content, err := os.ReadFile(file)
 		if err != nil {
 			fmt.Printf("  ⚠️  Failed to read: %v\n", err)
@@ -118,6 +147,14 @@ func (idx *Indexer) Index() (*Stats, error) {
 			fmt.Printf("  ⚠️  Failed to chunk: %v\n", err)
 			continue
 		}
+		assignLines(string(content), chunks)
+		edges = append(edges, extractEdges(file, string(content), services)...)
+		if idx.stack.Symfony {
+			fileRoutes, imports := extractRoutes(file, string(content))
+			routes = append(routes, fileRoutes...)
+			routeImports = append(routeImports, imports...)
+			entities = append(entities, extractEntities(file, string(content))...)
+		}
 
 		// Store chunks
 		storedCount := 0
@@ -140,12 +177,42 @@ func (idx *Indexer) Index() (*Stats, error) {
 			Path:      file,
 			Hash:      hex.EncodeToString(hash[:]),
 			Chunks:    storedCount,
-			IndexedAt: time.Now(),
+			IndexedAt: readAt,
 		}
 
 		fmt.Printf("  ✓ %d chunks stored\n", storedCount)
 	}
 
+	if idx.identifiersErr != nil {
+		fmt.Printf("⚠️  %v\n", idx.identifiersErr)
+	}
+	if idx.identifierFailures > 0 {
+		fmt.Printf("⚠️  Identifiers of %d chunks not indexed, last error: %v\n", idx.identifierFailures, idx.identifierFailure)
+	}
+
+	resolver := newEdgeResolver(idx.projectPath, services)
+	resolver.resolve(edges)
+	if err := idx.storeEdges(edges); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	} else {
+		stats.EdgesStored = len(edges)
+	}
+
+	if idx.stack.Symfony {
+		applyRouteImports(routes, routeImports)
+		resolver.controllers(routes)
+		if err := idx.storeRoutes(routes); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		} else {
+			stats.RoutesStored = len(routes)
+		}
+		if err := idx.storeEntities(entities); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		} else {
+			stats.EntitiesStored = len(entities)
+		}
+	}
+
 	stats.EndTime = time.Now()
 	stats.Duration = stats.EndTime.Sub(stats.StartTime).String()
 
@@ -161,9 +228,11 @@ func (idx *Indexer) Index() (*Stats, error) {
 	return stats, nil
 }
 
-// scanFiles scans for files to index based on RAG config
-func (idx *Indexer) scanFiles() ([]string, error) {
+// scanFiles scans for files to index based on RAG config.
+// Files rejected by the content filters are returned separately with a reason.
+func (idx *Indexer) scanFiles() ([]string, []SkippedFile, error) {
 	var files []string
+	var skipped []SkippedFile
 
 	includePaths := idx.ragConfig.Indexing.IncludePaths
 	excludePaths := idx.ragConfig.Indexing.ExcludePaths
@@ -184,11 +253,15 @@ func (idx *Indexer) scanFiles() ([]string, error) {
 			continue
 		}
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// If it's a file, add it directly
 		if !info.IsDir() {
+			if reason := idx.filter.skipReason(fullPath, info); reason != "" {
+				skipped = append(skipped, SkippedFile{Path: includePath, Reason: reason})
+				continue
+			}
 			files = append(files, includePath)
 			continue
 		}
@@ -230,16 +303,28 @@ func (idx *Indexer) scanFiles() ([]string, error) {
 				}
 			}
 
+			// Check content filters (size, binary, minified, lockfile)
+			if reason := idx.filter.skipReason(path, info); reason != "" {
+				skipped = append(skipped, SkippedFile{Path: relPath, Reason: reason})
+				return nil
+			}
+
 			files = append(files, relPath)
 			return nil
 		})
 
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	return files, nil
+	return files, skipped, nil
+}
+
+// dbExecutor is the part of *sql.DB and *sql.Tx used to write chunks
+type dbExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
 }
 
 // storeChunk stores a chunk in the database
@@ -250,6 +335,17 @@ func (idx *Indexer) storeChunk(chunk Chunk, commitSHA string) error {
 		return fmt.Errorf("failed to generate embedding: %w", err)
 	}
 
+	chunkID, err := idx.insertChunk(idx.db, chunk, embedding, commitSHA)
+	if err != nil {
+		return err
+	}
+
+	idx.indexIdentifiers(chunkID, chunk)
+	return nil
+}
+
+// insertChunk writes an embedded chunk and returns its id
+func (idx *Indexer) insertChunk(q dbExecutor, chunk Chunk, embedding []float32, commitSHA string) (int, error) {
 	// Generate content hash
 	hash := sha256.Sum256([]byte(chunk.Content))
 	contentHash := hex.EncodeToString(hash[:])
@@ -264,10 +360,15 @@ func (idx *Indexer) storeChunk(chunk Chunk, commitSHA string) error {
 	if chunk.Component != "" {
 		metadata["component"] = chunk.Component
 	}
+	for k, v := range chunk.Metadata {
+		if v != "" {
+			metadata[k] = v
+		}
+	}
 
 	metadataJSON, err := json.Marshal(metadata)
 	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
+		return 0, fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
 	// Convert embedding to postgres array format
@@ -275,15 +376,17 @@ func (idx *Indexer) storeChunk(chunk Chunk, commitSHA string) error {
 
 	// Insert chunk
 	query := `
-		INSERT INTO chunks (project_id, source, type, path, language, symbol, component, content, content_hash, embedding, embedding_model, metadata, commit_sha)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		INSERT INTO chunks (project_id, source, type, path, language, symbol, component, content, content_hash, embedding, embedding_model, metadata, commit_sha, start_line, end_line)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		ON CONFLICT (project_id, content_hash) DO UPDATE
 		SET updated_at = CURRENT_TIMESTAMP,
 		    embedding = EXCLUDED.embedding,
 		    embedding_model = EXCLUDED.embedding_model
+		RETURNING id
 	`
 
-	_, err = idx.db.Exec(query,
+	var chunkID int
+	err = q.QueryRow(query,
 		idx.projectID,
 		"repo",
 		chunk.Type,
@@ -297,9 +400,10 @@ func (idx *Indexer) storeChunk(chunk Chunk, commitSHA string) error {
 		idx.embeddingModel,
 		metadataJSON,
 		nullString(commitSHA),
-	)
-
-	return err
+		nullInt(chunk.StartLine),
+		nullInt(chunk.EndLine),
+	).Scan(&chunkID)
+	return chunkID, err
 }
 
 // clearExistingChunks clears existing chunks for this project
@@ -352,6 +456,13 @@ func nullString(s string) interface{} {
 	return s
 }
 
+func nullInt(n int) interface{} {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
+
 func vectorToPostgresArray(vec []float32) string {
 	// Convert []float32 to postgres array format: [0.1,0.2,0.3,...]
 	parts := make([]string, len(vec))
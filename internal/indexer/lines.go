@@ -0,0 +1,27 @@
+package indexer
+
+import "strings"
+
+// assignLines sets the line range of each chunk by locating its content in the file.
+// Chunks that are not verbatim excerpts (e.g. migration summaries) keep no range.
+func assignLines(content string, chunks []Chunk) {
+	cursor := 0
+	for i := range chunks {
+		text := strings.TrimSpace(chunks[i].Content)
+		if text == "" {
+			continue
+		}
+
+		// Chunks are usually emitted in file order, so search forward first
+		offset := strings.Index(content[cursor:], text)
+		if offset >= 0 {
+			offset += cursor
+		} else if offset = strings.Index(content, text); offset < 0 {
+			continue
+		}
+
+		chunks[i].StartLine = strings.Count(content[:offset], "\n") + 1
+		chunks[i].EndLine = chunks[i].StartLine + strings.Count(text, "\n")
+		cursor = offset + len(text)
+	}
+}
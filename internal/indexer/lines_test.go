@@ -0,0 +1,28 @@
+package indexer
+
+import "testing"
+
+func TestAssignLines(t *testing.T) {
+	content := "<?php\n\nclass A\n{\n    public function a() {}\n\n    public function b() {}\n}\n"
+	chunks := []Chunk{
+		{Content: "    public function b() {}\n"},
+		{Content: "public function a() {}"},
+		{Content: "public function b() {}"},
+		{Content: "Summary of class A"},
+		{Content: "  \n"},
+	}
+	assignLines(content, chunks)
+
+	want := [][2]int{{7, 7}, {5, 5}, {7, 7}, {0, 0}, {0, 0}}
+	for i, c := range chunks {
+		if got := [2]int{c.StartLine, c.EndLine}; got != want[i] {
+			t.Errorf("chunk %d lines = %v, want %v", i, got, want[i])
+		}
+	}
+
+	multi := []Chunk{{Content: "class A\n{"}, {Content: "public function b() {}\n}"}}
+	assignLines(content, multi)
+	if multi[0].StartLine != 3 || multi[0].EndLine != 4 || multi[1].StartLine != 7 || multi[1].EndLine != 8 {
+		t.Errorf("lines = %d-%d and %d-%d, want 3-4 and 7-8", multi[0].StartLine, multi[0].EndLine, multi[1].StartLine, multi[1].EndLine)
+	}
+}
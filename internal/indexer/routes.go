@@ -0,0 +1,633 @@
+package indexer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+	"gopkg.in/yaml.v3"
+)
+
+// Route sources stored in the routes table
+const (
+	RouteAttribute  = "attribute"  // #[Route] on a controller
+	RouteAnnotation = "annotation" // @Route in a controller docblock
+	RouteYAML       = "yaml"       // config/routes*.yaml
+	RouteXML        = "xml"        // config/routes*.xml
+)
+
+// Route is a Symfony route and the controller action handling it
+type Route struct {
+	Name           string   // empty when the route is not named explicitly
+	Path           string   // as declared, with class prefix: /api/users/{id}
+	Methods        []string // empty for any method
+	Controller     string   // App\Controller\UserController::show
+	ControllerPath string   // project file of the controller, empty when unresolved
+	File           string   // file declaring the route
+	Line           int
+	Source         string
+}
+
+// routeImport is a routing import (resource:) whose prefixes apply to the routes
+// it loads: those of a routing file, or of the controllers under a directory
+type routeImport struct {
+	resource   string // project file or directory
+	prefix     string
+	namePrefix string
+}
+
+var (
+	phpNamespacePattern = regexp.MustCompile(`^\s*namespace\s+([A-Za-z0-9_\\]+)\s*;`)
+	phpClassPattern     = regexp.MustCompile(`^\s*(?:(?:abstract|final|readonly)\s+)*class\s+(\w+)`)
+	phpFunctionPattern  = regexp.MustCompile(`^\s*(?:(?:public|protected|private|static|final|abstract)\s+)*function\s+&?(\w+)\s*\(`)
+	routeAttrPattern    = regexp.MustCompile(`(?:^|[^\w\\@])(?:[A-Za-z0-9_\\]*\\)?Route\s*\(`)
+	routeAnnotPattern   = regexp.MustCompile(`@(?:[A-Za-z0-9_\\]*\\)?Route\s*\(`)
+	namedArgPattern     = regexp.MustCompile(`^(\w+)\s*(?::|=)\s*([\s\S]*)$`)
+	stringPattern       = regexp.MustCompile(`'((?:[^'\\]|\\.)*)'|"((?:[^"\\]|\\.)*)"`)
+)
+
+// isRouteFile reports whether a file can declare routes: PHP controllers and the
+// routing config under config/ (routes.yaml, routes/*.yaml, routes/*.xml...)
+func isRouteFile(file string) bool {
+	file = filepath.ToSlash(file)
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".php":
+		return true
+	case ".yaml", ".yml", ".xml":
+		return strings.HasPrefix(file, "config/") && strings.Contains(file, "routes")
+	}
+	return false
+}
+
+// extractRoutes lists the routes and the routing imports declared in a file.
+// Controllers of routes declared in config are resolved to files, and import
+// prefixes applied, later, once every file is known.
+func extractRoutes(file, content string) ([]Route, []routeImport) {
+	if !isRouteFile(file) {
+		return nil, nil
+	}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".php":
+		if !strings.Contains(content, "Route") {
+			return nil, nil
+		}
+		return phpRoutes(file, content), nil
+	case ".xml":
+		return xmlRoutes(file, content)
+	default:
+		return yamlRoutes(file, content)
+	}
+}
+
+// importResource resolves the resource of an import declared in file to a
+// project path: ../src/Controller/ in config/routes.yaml is src/Controller.
+// Globs are cut at the first wildcard; bundle (@AcmeBundle) and out-of-project
+// resources are left out.
+func importResource(file, resource string) string {
+	if resource == "" || strings.HasPrefix(resource, "@") {
+		return ""
+	}
+	if i := strings.IndexAny(resource, "*{"); i >= 0 {
+		resource = resource[:i]
+	}
+	path := filepath.ToSlash(filepath.Clean(filepath.Join(filepath.Dir(file), resource)))
+	if path == "." || path == ".." || strings.HasPrefix(path, "../") || strings.HasPrefix(path, "/") {
+		return ""
+	}
+	return path
+}
+
+// applyRouteImports prefixes the path and name of the routes loaded by an import:
+// routes declared in the imported file, or in a file under the imported
+// directory. The most specific import wins when several cover a file.
+func applyRouteImports(routes []Route, imports []routeImport) {
+	for i := range routes {
+		route := &routes[i]
+		var best *routeImport
+		for j := range imports {
+			imp := &imports[j]
+			if route.File != imp.resource && !strings.HasPrefix(route.File, imp.resource+"/") {
+				continue
+			}
+			if best == nil || len(imp.resource) > len(best.resource) {
+				best = imp
+			}
+		}
+		if best == nil {
+			continue
+		}
+		if best.prefix != "" {
+			route.Path = strings.TrimRight(best.prefix, "/") + route.Path
+		}
+		if best.namePrefix != "" && route.Name != "" {
+			route.Name = best.namePrefix + route.Name
+		}
+	}
+}
+
+// routeSpec is the content of one #[Route] attribute or @Route annotation
+type routeSpec struct {
+	path, name string
+	methods    []string
+	line       int
+	source     string
+}
+
+// phpRoutes reads #[Route] attributes and @Route annotations of a controller.
+// Routes on the class are prefixes for the routes of its methods, or the route
+// of __invoke for an invokable controller.
+func phpRoutes(file, content string) []Route {
+	var routes []Route
+	namespace, class := "", ""
+	var classSpecs, pending []routeSpec
+
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "//"),
+			strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "#["):
+			continue
+
+		case strings.HasPrefix(trimmed, "#["), strings.HasPrefix(trimmed, "/*"):
+			// Attribute or docblock, possibly spanning several lines
+			start, source, end := i, RouteAttribute, "]"
+			if strings.HasPrefix(trimmed, "/*") {
+				source, end = RouteAnnotation, "*/"
+			}
+			block := line
+			for !blockClosed(block, end) && i+1 < len(lines) {
+				i++
+				block += "\n" + lines[i]
+			}
+			pending = append(pending, routeSpecs(block, source, start+1)...)
+			continue
+		}
+
+		if m := phpNamespacePattern.FindStringSubmatch(line); m != nil {
+			namespace = m[1]
+			continue
+		}
+		if m := phpClassPattern.FindStringSubmatch(line); m != nil {
+			class = m[1]
+			if namespace != "" {
+				class = namespace + `\` + class
+			}
+			classSpecs, pending = pending, nil
+			continue
+		}
+		if m := phpFunctionPattern.FindStringSubmatch(line); m != nil && class != "" {
+			action := m[1]
+			if len(pending) == 0 && action == "__invoke" && len(classSpecs) > 0 {
+				pending = []routeSpec{{line: classSpecs[0].line, source: classSpecs[0].source}}
+			}
+			for _, spec := range pending {
+				routes = append(routes, classRoutes(file, class+"::"+action, classSpecs, spec)...)
+			}
+			pending = nil
+			continue
+		}
+		pending = nil
+	}
+
+	return routes
+}
+
+// classRoutes combines a method route with the routes of its class, one route per class prefix
+func classRoutes(file, controller string, classSpecs []routeSpec, spec routeSpec) []Route {
+	if len(classSpecs) == 0 {
+		classSpecs = []routeSpec{{}}
+	}
+	var routes []Route
+	for _, prefix := range classSpecs {
+		methods := spec.methods
+		if len(methods) == 0 {
+			methods = prefix.methods
+		}
+		name := spec.name
+		if name != "" {
+			name = prefix.name + name
+		} else if spec.path == "" {
+			name = prefix.name // invokable controller: the class route itself
+		}
+		path := strings.TrimSuffix(prefix.path, "/") + spec.path
+		if spec.path == "" {
+			path = prefix.path
+		}
+		if path == "" {
+			path = "/"
+		}
+		routes = append(routes, Route{
+			Name: name, Path: path, Methods: methods, Controller: controller,
+			ControllerPath: filepath.ToSlash(file), File: filepath.ToSlash(file), Line: spec.line, Source: spec.source,
+		})
+	}
+	return routes
+}
+
+// blockClosed reports whether an attribute (#[...]) or docblock is complete
+func blockClosed(block, end string) bool {
+	if end == "*/" {
+		return strings.Contains(block, "*/")
+	}
+	depth := 0
+	inString := byte(0)
+	for i := 0; i < len(block); i++ {
+		c := block[i]
+		switch {
+		case inString != 0:
+			if c == '\\' {
+				i++
+			} else if c == inString {
+				inString = 0
+			}
+		case c == '\'' || c == '"':
+			inString = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+			if depth == 0 && c == ']' {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// routeSpecs reads the Route(...) calls of an attribute or docblock
+func routeSpecs(block, source string, line int) []routeSpec {
+	pattern := routeAttrPattern
+	if source == RouteAnnotation {
+		block, pattern = docblockText(block), routeAnnotPattern
+	}
+
+	var specs []routeSpec
+	for _, loc := range pattern.FindAllStringIndex(block, -1) {
+		args, ok := callArguments(block[loc[1]:])
+		if !ok {
+			continue
+		}
+		spec := routeSpec{line: line + strings.Count(block[:loc[0]], "\n"), source: source}
+		for i, arg := range splitArguments(args) {
+			key, value := "", arg
+			if m := namedArgPattern.FindStringSubmatch(arg); m != nil {
+				key, value = m[1], m[2]
+			} else if i > 0 {
+				continue
+			}
+			switch key {
+			case "", "path", "value":
+				strs := stringLiterals(value)
+				if strings.Contains(value, "=>") && len(strs) > 1 {
+					// Localized paths: ['en' => '/about', 'fr' => '/a-propos'] keep the first one
+					spec.path = strs[1]
+				} else if len(strs) > 0 {
+					spec.path = strs[0]
+				}
+			case "name":
+				if strs := stringLiterals(value); len(strs) > 0 {
+					spec.name = strs[0]
+				}
+			case "methods":
+				for _, s := range stringLiterals(value) {
+					spec.methods = append(spec.methods, splitMethods(s)...)
+				}
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// docblockText drops the decoration of a docblock so annotations can be read as code
+func docblockText(block string) string {
+	var b strings.Builder
+	for _, l := range strings.Split(block, "\n") {
+		l = strings.TrimSpace(l)
+		l = strings.TrimPrefix(strings.TrimPrefix(l, "/**"), "/*")
+		l = strings.TrimSuffix(l, "*/")
+		b.WriteString(strings.TrimPrefix(strings.TrimSpace(l), "*") + "\n")
+	}
+	return b.String()
+}
+
+// callArguments returns the text up to the parenthesis closing a call
+func callArguments(s string) (string, bool) {
+	depth := 1
+	inString := byte(0)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inString != 0:
+			if c == '\\' {
+				i++
+			} else if c == inString {
+				inString = 0
+			}
+		case c == '\'' || c == '"':
+			inString = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+			if depth == 0 {
+				return s[:i], true
+			}
+		}
+	}
+	return "", false
+}
+
+// splitArguments splits call arguments on top-level commas
+func splitArguments(args string) []string {
+	var parts []string
+	depth, start := 0, 0
+	inString := byte(0)
+	for i := 0; i < len(args); i++ {
+		c := args[i]
+		switch {
+		case inString != 0:
+			if c == '\\' {
+				i++
+			} else if c == inString {
+				inString = 0
+			}
+		case c == '\'' || c == '"':
+			inString = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(args[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(args[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// stringLiterals returns the quoted strings of a PHP expression, unescaped
+func stringLiterals(s string) []string {
+	var strs []string
+	for _, m := range stringPattern.FindAllStringSubmatch(s, -1) {
+		value := m[1]
+		if value == "" {
+			value = m[2]
+		}
+		strs = append(strs, strings.NewReplacer(`\\`, `\`, `\'`, `'`, `\"`, `"`).Replace(value))
+	}
+	return strs
+}
+
+// splitMethods normalizes "GET|POST" or "get" to upper-case methods
+func splitMethods(s string) []string {
+	var methods []string
+	for _, m := range strings.FieldsFunc(s, func(r rune) bool { return r == '|' || r == ',' || r == ' ' }) {
+		methods = append(methods, strings.ToUpper(m))
+	}
+	return methods
+}
+
+// yamlRoutes reads the routes and imports (resource:) of a YAML routing file;
+// when@env sections are read like the top level
+func yamlRoutes(file, content string) ([]Route, []routeImport) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil || len(doc.Content) == 0 {
+		return nil, nil
+	}
+	return yamlRouteMap(filepath.ToSlash(file), doc.Content[0])
+}
+
+// yamlRouteMap reads route definitions and imports from a mapping of route names
+func yamlRouteMap(file string, node *yaml.Node) ([]Route, []routeImport) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	var routes []Route
+	var imports []routeImport
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if strings.HasPrefix(key.Value, "when@") {
+			envRoutes, envImports := yamlRouteMap(file, value)
+			routes = append(routes, envRoutes...)
+			imports = append(imports, envImports...)
+			continue
+		}
+		if value.Kind != yaml.MappingNode {
+			continue
+		}
+
+		route := Route{Name: key.Value, File: file, Line: key.Line, Source: RouteYAML}
+		var imp *routeImport
+		prefix, namePrefix := "", ""
+		for j := 0; j+1 < len(value.Content); j += 2 {
+			field, v := value.Content[j].Value, value.Content[j+1]
+			switch field {
+			case "resource":
+				resource := v.Value
+				if v.Kind == yaml.MappingNode { // {path: ../src/Controller/, namespace: App\Controller}
+					for k := 0; k+1 < len(v.Content); k += 2 {
+						if v.Content[k].Value == "path" {
+							resource = v.Content[k+1].Value
+						}
+					}
+				}
+				imp = &routeImport{resource: importResource(file, resource)}
+			case "prefix":
+				prefix = yamlFirstScalar(v)
+			case "name_prefix":
+				namePrefix = v.Value
+			case "path":
+				route.Path = yamlFirstScalar(v)
+			case "controller":
+				route.Controller = v.Value
+			case "methods":
+				for _, m := range yamlScalars(v) {
+					route.Methods = append(route.Methods, splitMethods(m)...)
+				}
+			case "defaults":
+				if route.Controller == "" {
+					for k := 0; k+1 < len(v.Content); k += 2 {
+						if v.Content[k].Value == "_controller" {
+							route.Controller = v.Content[k+1].Value
+						}
+					}
+				}
+			}
+		}
+		if imp != nil {
+			if imp.resource != "" {
+				imp.prefix, imp.namePrefix = prefix, namePrefix
+				imports = append(imports, *imp)
+			}
+			continue
+		}
+		if route.Path != "" {
+			routes = append(routes, route)
+		}
+	}
+	return routes, imports
+}
+
+// yamlFirstScalar returns a scalar, or the first value of a mapping (localized paths)
+func yamlFirstScalar(node *yaml.Node) string {
+	if node.Kind == yaml.MappingNode && len(node.Content) >= 2 {
+		return node.Content[1].Value
+	}
+	return node.Value
+}
+
+// yamlScalars returns the values of a scalar or a sequence
+func yamlScalars(node *yaml.Node) []string {
+	if node.Kind != yaml.SequenceNode {
+		return []string{node.Value}
+	}
+	var values []string
+	for _, item := range node.Content {
+		values = append(values, item.Value)
+	}
+	return values
+}
+
+// xmlRoutes reads the <route> and <import> elements of an XML routing file
+func xmlRoutes(file, content string) ([]Route, []routeImport) {
+	var routes []Route
+	var imports []routeImport
+	var current *Route
+	field := ""
+
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF || err != nil {
+			break
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == "import":
+				var imp routeImport
+				for _, attr := range t.Attr {
+					switch attr.Name.Local {
+					case "resource":
+						imp.resource = importResource(file, attr.Value)
+					case "prefix":
+						imp.prefix = attr.Value
+					case "name-prefix":
+						imp.namePrefix = attr.Value
+					}
+				}
+				if imp.resource != "" {
+					imports = append(imports, imp)
+				}
+			case t.Name.Local == "route":
+				current = &Route{File: filepath.ToSlash(file), Line: strings.Count(content[:offset], "\n") + 1, Source: RouteXML}
+				for _, attr := range t.Attr {
+					switch attr.Name.Local {
+					case "id":
+						current.Name = attr.Value
+					case "path":
+						current.Path = attr.Value
+					case "controller":
+						current.Controller = attr.Value
+					case "methods":
+						current.Methods = splitMethods(attr.Value)
+					}
+				}
+			case current != nil && t.Name.Local == "default":
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "key" && attr.Value == "_controller" {
+						field = "controller"
+					}
+				}
+			case current != nil && t.Name.Local == "path" && current.Path == "":
+				field = "path" // localized path, keep the first one
+			}
+		case xml.CharData:
+			if current == nil || field == "" {
+				continue
+			}
+			value := strings.TrimSpace(string(t))
+			if field == "controller" && current.Controller == "" {
+				current.Controller = value
+			} else if field == "path" && current.Path == "" {
+				current.Path = value
+			}
+		case xml.EndElement:
+			field = ""
+			if t.Name.Local == "route" && current != nil {
+				if current.Path != "" {
+					routes = append(routes, *current)
+				}
+				current = nil
+			}
+		}
+	}
+	return routes, imports
+}
+
+// controllers sets the file of the controller of each route declared in config.
+// Controllers are class names (Class::action, or Class for __invoke) or service ids.
+func (r *edgeResolver) controllers(routes []Route) {
+	for i := range routes {
+		route := &routes[i]
+		if route.ControllerPath != "" || route.Controller == "" {
+			continue
+		}
+		class, action, found := strings.Cut(route.Controller, "::")
+		if !found {
+			action = "__invoke"
+		}
+		class = strings.TrimPrefix(r.serviceClass(class), `\`)
+		route.Controller = class + "::" + action
+		route.ControllerPath = r.class(class)
+	}
+}
+
+// storeRoutes replaces the route table of the project
+func (idx *Indexer) storeRoutes(routes []Route) error {
+	if _, err := idx.db.Exec("DELETE FROM routes WHERE project_id = $1", idx.projectID); err != nil {
+		return fmt.Errorf("failed to clear routes (run 'oview up' to create the routes table): %w", err)
+	}
+	if len(routes) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(routes))
+	paths := make([]string, len(routes))
+	methods := make([]string, len(routes))
+	controllers := make([]string, len(routes))
+	controllerPaths := make([]string, len(routes))
+	files := make([]string, len(routes))
+	lines := make([]int64, len(routes))
+	sources := make([]string, len(routes))
+	for i, r := range routes {
+		names[i], paths[i], methods[i] = r.Name, r.Path, strings.Join(r.Methods, "|")
+		controllers[i], controllerPaths[i] = r.Controller, r.ControllerPath
+		files[i], lines[i], sources[i] = r.File, int64(r.Line), r.Source
+	}
+
+	_, err := idx.db.Exec(`
+		INSERT INTO routes (project_id, name, path, methods, controller, controller_path, file, line, source)
+		SELECT $1, n, p, m, c, NULLIF(cp, ''), f, l, s
+		FROM unnest($2::text[], $3::text[], $4::text[], $5::text[], $6::text[], $7::text[], $8::int[], $9::text[])
+			AS r(n, p, m, c, cp, f, l, s)
+	`, idx.projectID, pq.Array(names), pq.Array(paths), pq.Array(methods), pq.Array(controllers),
+		pq.Array(controllerPaths), pq.Array(files), pq.Array(lines), pq.Array(sources))
+	if err != nil {
+		return fmt.Errorf("failed to store routes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package indexer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPhpRoutes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Route
+	}{
+		{
+			name: "attributes with class prefix",
+			content: `<?php
+namespace App\Controller;
+
+use Symfony\Component\Routing\Attribute\Route;
+
+#[Route('/api/users', name: 'api_user_')]
+final class UserController extends AbstractController
+{
+    #[Route('', name: 'list', methods: ['GET'])]
+    public function list(): Response {}
+
+    #[Route('/{id<\d+>}', name: 'show', methods: 'GET|HEAD')]
+    public function show(int $id): Response {}
+
+    public function helper(): void {}
+
+    #[Route(path: '/{id}/edit', methods: ['get', 'post'])]
+    public function edit(int $id): Response {}
+}
+`,
+			want: []Route{
+				{Name: "api_user_list", Path: "/api/users", Methods: []string{"GET"}, Controller: `App\Controller\UserController::list`, Line: 9},
+				{Name: "api_user_show", Path: `/api/users/{id<\d+>}`, Methods: []string{"GET", "HEAD"}, Controller: `App\Controller\UserController::show`, Line: 12},
+				{Path: "/api/users/{id}/edit", Methods: []string{"GET", "POST"}, Controller: `App\Controller\UserController::edit`, Line: 17},
+			},
+		},
+		{
+			name: "multi-line attribute with localized paths",
+			content: `<?php
+namespace App\Controller;
+
+class PageController
+{
+    #[Route(
+        path: ['en' => '/about', 'fr' => '/a-propos'],
+        name: 'about',
+    )]
+    public function about(): Response {}
+}
+`,
+			want: []Route{{Name: "about", Path: "/about", Controller: `App\Controller\PageController::about`, Line: 6}},
+		},
+		{
+			name: "invokable controller",
+			content: `<?php
+namespace App\Controller;
+
+#[Route('/health', name: 'health', methods: ['GET'])]
+class HealthController
+{
+    public function __invoke(): Response {}
+}
+`,
+			want: []Route{{Name: "health", Path: "/health", Methods: []string{"GET"}, Controller: `App\Controller\HealthController::__invoke`, Line: 4}},
+		},
+		{
+			name: "one route per class prefix",
+			content: `<?php
+#[Route('/v1')]
+#[Route('/v2')]
+class ApiController
+{
+    #[Route('/ping')]
+    public function ping() {}
+}
+`,
+			want: []Route{
+				{Path: "/v1/ping", Controller: "ApiController::ping", Line: 6},
+				{Path: "/v2/ping", Controller: "ApiController::ping", Line: 6},
+			},
+		},
+		{
+			name: "unrelated attributes",
+			content: `<?php
+class Listener
+{
+    #[AsEventListener(event: 'kernel.request')]
+    public function onRequest() {}
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range tt.want {
+				tt.want[i].File, tt.want[i].ControllerPath, tt.want[i].Source = "src/Controller/X.php", "src/Controller/X.php", RouteAttribute
+			}
+			if got := phpRoutes("src/Controller/X.php", tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("phpRoutes() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPhpRoutesAnnotations(t *testing.T) {
+	content := `<?php
+namespace App\Controller;
+
+use Symfony\Component\Routing\Annotation\Route;
+
+/**
+ * @Route("/blog", name="blog_")
+ */
+class BlogController
+{
+    /**
+     * Shows a post.
+     *
+     * @Route("/{slug}", name="show", methods={"GET"})
+     */
+    public function show(string $slug) {}
+}
+`
+	want := []Route{{
+		Name:           "blog_show",
+		Path:           "/blog/{slug}",
+		Methods:        []string{"GET"},
+		Controller:     `App\Controller\BlogController::show`,
+		File:           "src/Controller/BlogController.php",
+		ControllerPath: "src/Controller/BlogController.php",
+		Line:           14,
+		Source:         RouteAnnotation,
+	}}
+	if got := phpRoutes("src/Controller/BlogController.php", content); !reflect.DeepEqual(got, want) {
+		t.Errorf("phpRoutes() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestBlockClosed(t *testing.T) {
+	tests := []struct {
+		block, end string
+		want       bool
+	}{
+		{`#[Route('/users')]`, "]", true},
+		{`#[Route(`, "]", false},
+		{`#[Route(path: ['en' => '/a'], name: 'x')]`, "]", true},
+		{`#[Route('/users/{id<[0-9]+>}', name: 'show'`, "]", false},
+		{`#[Route('/]')`, "]", false},
+		{`#[Route('it\'s]')]`, "]", true},
+		{"/**\n * @Route(\"/blog\")", "*/", false},
+		{"/**\n * @Route(\"/blog\")\n */", "*/", true},
+	}
+	for _, tt := range tests {
+		if got := blockClosed(tt.block, tt.end); got != tt.want {
+			t.Errorf("blockClosed(%q, %q) = %v, want %v", tt.block, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestSplitArguments(t *testing.T) {
+	tests := map[string][]string{
+		``:                                       nil,
+		`'/users', name: 'list'`:                 {`'/users'`, `name: 'list'`},
+		`path: '/a,b', methods: ['GET', 'POST']`: {`path: '/a,b'`, `methods: ['GET', 'POST']`},
+		`"/blog", requirements={"page"="\d+", "x"="y"}`: {`"/blog"`, `requirements={"page"="\d+", "x"="y"}`},
+		`'it\'s, fine', x: f(1, 2),`:                    {`'it\'s, fine'`, `x: f(1, 2)`},
+	}
+	for args, want := range tests {
+		if got := splitArguments(args); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitArguments(%q) = %q, want %q", args, got, want)
+		}
+	}
+}
+
+func TestYAMLRoutes(t *testing.T) {
+	content := `
+home:
+    path: /
+    controller: App\Controller\HomeController::index
+
+legacy:
+    path:
+        en: /legacy
+        fr: /ancien
+    methods: [GET, POST]
+    defaults:
+        _controller: App\Controller\LegacyController
+
+controllers:
+    resource: ../src/Controller/
+    type: attribute
+    prefix: /api
+    name_prefix: api_
+
+admin:
+    resource:
+        path: ../src/Admin/Controller/
+        namespace: App\Admin\Controller
+    prefix: /admin
+
+bundle:
+    resource: '@AcmeBundle/config/routes.yaml'
+    prefix: /acme
+
+when@dev:
+    profiler:
+        resource: 'routes/dev/*.yaml'
+        prefix: /_dev
+`
+	routes, imports := yamlRoutes("config/routes.yaml", content)
+
+	wantRoutes := []Route{
+		{Name: "home", Path: "/", Controller: `App\Controller\HomeController::index`, File: "config/routes.yaml", Line: 2, Source: RouteYAML},
+		{Name: "legacy", Path: "/legacy", Methods: []string{"GET", "POST"}, Controller: `App\Controller\LegacyController`, File: "config/routes.yaml", Line: 6, Source: RouteYAML},
+	}
+	if !reflect.DeepEqual(routes, wantRoutes) {
+		t.Errorf("routes =\n%+v\nwant\n%+v", routes, wantRoutes)
+	}
+
+	wantImports := []routeImport{
+		{resource: "src/Controller", prefix: "/api", namePrefix: "api_"},
+		{resource: "src/Admin/Controller", prefix: "/admin"},
+		{resource: "config/routes/dev", prefix: "/_dev"},
+	}
+	if !reflect.DeepEqual(imports, wantImports) {
+		t.Errorf("imports = %+v, want %+v", imports, wantImports)
+	}
+}
+
+func TestXMLRoutes(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8" ?>
+<routes xmlns="http://symfony.com/schema/routing">
+    <import resource="../src/Controller/" type="attribute" prefix="/api" name-prefix="api_"/>
+    <route id="home" path="/" controller="App\Controller\HomeController::index" methods="GET"/>
+    <route id="legacy" path="/legacy">
+        <default key="_controller">App\Controller\LegacyController</default>
+    </route>
+</routes>
+`
+	routes, imports := xmlRoutes("config/routes.xml", content)
+
+	wantRoutes := []Route{
+		{Name: "home", Path: "/", Methods: []string{"GET"}, Controller: `App\Controller\HomeController::index`, File: "config/routes.xml", Line: 4, Source: RouteXML},
+		{Name: "legacy", Path: "/legacy", Controller: `App\Controller\LegacyController`, File: "config/routes.xml", Line: 5, Source: RouteXML},
+	}
+	if !reflect.DeepEqual(routes, wantRoutes) {
+		t.Errorf("routes =\n%+v\nwant\n%+v", routes, wantRoutes)
+	}
+	wantImports := []routeImport{{resource: "src/Controller", prefix: "/api", namePrefix: "api_"}}
+	if !reflect.DeepEqual(imports, wantImports) {
+		t.Errorf("imports = %+v, want %+v", imports, wantImports)
+	}
+}
+
+func TestImportResource(t *testing.T) {
+	tests := []struct {
+		file, resource, want string
+	}{
+		{"config/routes.yaml", "../src/Controller/", "src/Controller"},
+		{"config/routes/attributes.yaml", "../../src/Controller/", "src/Controller"},
+		{"config/routes.yaml", "routes/api.yaml", "config/routes/api.yaml"},
+		{"config/routes.yaml", "../src/Controller/{Admin,Api}/", "src/Controller"},
+		{"config/routes.yaml", "routes/*.yaml", "config/routes"},
+		{"config/routes.yaml", "@AcmeBundle/config/routes.yaml", ""},
+		{"config/routes.yaml", "../../vendor/acme/routes.yaml", ""},
+		{"config/routes.yaml", "", ""},
+	}
+	for _, tt := range tests {
+		if got := importResource(tt.file, tt.resource); got != tt.want {
+			t.Errorf("importResource(%q, %q) = %q, want %q", tt.file, tt.resource, got, tt.want)
+		}
+	}
+}
+
+func TestApplyRouteImports(t *testing.T) {
+	routes := []Route{
+		{Name: "list", Path: "/users", File: "src/Controller/UserController.php"},
+		{Name: "dashboard", Path: "/", File: "src/Controller/Admin/DashboardController.php"},
+		{Path: "/ping", File: "src/Controller/PingController.php"},
+		{Name: "home", Path: "/", File: "config/routes.yaml"},
+		{Name: "webhook", Path: "/stripe", File: "config/routes/webhooks.yaml"},
+		{Name: "other", Path: "/other", File: "src/ControllerX/OtherController.php"},
+	}
+	applyRouteImports(routes, []routeImport{
+		{resource: "src/Controller", prefix: "/api/", namePrefix: "api_"},
+		{resource: "src/Controller/Admin", prefix: "/admin", namePrefix: "admin_"},
+		{resource: "config/routes/webhooks.yaml", prefix: "/hooks"},
+	})
+
+	want := []struct{ name, path string }{
+		{"api_list", "/api/users"},
+		{"admin_dashboard", "/admin/"},
+		{"", "/api/ping"},
+		{"home", "/"},
+		{"webhook", "/hooks/stripe"},
+		{"other", "/other"},
+	}
+	for i, r := range routes {
+		if r.Name != want[i].name || r.Path != want[i].path {
+			t.Errorf("route %d = %s %s, want %s %s", i, r.Name, r.Path, want[i].name, want[i].path)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package indexer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Reasons an indexed file is stale
+const (
+	StaleModified = "modified" // content changed since indexing
+	StaleDeleted  = "deleted"  // removed from the working tree
+)
+
+// StaleFile is an indexed file whose chunks no longer match the working tree
+type StaleFile struct {
+	Path      string    `json:"path"`
+	Reason    string    `json:"reason"`
+	IndexedAt time.Time `json:"indexed_at"`
+}
+
+// LoadManifest reads .oview/index/manifest.json, written by each 'oview index'
+func LoadManifest(projectPath string) (*Manifest, error) {
+	manifestPath := filepath.Join(projectPath, ".oview", "index", "manifest.json")
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]FileInfo)
+	}
+	return manifest, nil
+}
+
+// Staleness compares an indexed file with the working tree and returns
+// StaleModified, StaleDeleted or "" when it is up to date. Files are hashed only
+// when modified after indexing, and files not in the manifest are never stale
+func (m *Manifest) Staleness(projectPath, path string) string {
+	file, ok := m.Files[filepath.FromSlash(path)]
+	if !ok {
+		return ""
+	}
+
+	fullPath := filepath.Join(projectPath, file.Path)
+	info, err := os.Stat(fullPath)
+	if os.IsNotExist(err) {
+		return StaleDeleted
+	}
+	if err != nil || !info.ModTime().After(file.IndexedAt) {
+		return ""
+	}
+
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return ""
+	}
+	hash := sha256.Sum256(content)
+	if hex.EncodeToString(hash[:]) != file.Hash {
+		return StaleModified
+	}
+	return ""
+}
+
+// StaleFiles returns the indexed files changed or deleted since indexing,
+// ordered by path
+func (m *Manifest) StaleFiles(projectPath string) []StaleFile {
+	var stale []StaleFile
+	for path, file := range m.Files {
+		if reason := m.Staleness(projectPath, path); reason != "" {
+			stale = append(stale, StaleFile{Path: filepath.ToSlash(path), Reason: reason, IndexedAt: file.IndexedAt})
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Path < stale[j].Path })
+	return stale
+}
+
+// Refresh re-chunks and re-embeds the given files in place of their indexed
+// chunks, removes the chunks of deleted or now skipped files and updates the
+// manifest. Each file is embedded before its chunks are replaced in one
+// transaction, so a failing embeddings provider leaves the file indexed as it
+// was. The dependency graph, routes and entities are left to the next 'oview index'
+func (idx *Indexer) Refresh(files []string) error {
+	manifest, err := LoadManifest(idx.projectPath)
+	if err != nil {
+		return err
+	}
+	commitSHA := idx.getGitCommitSHA()
+
+	var refreshErr error
+	for _, file := range files {
+		file = filepath.FromSlash(file)
+		info, err := idx.refreshFile(file, commitSHA)
+		if err != nil {
+			refreshErr = err
+			break
+		}
+		if info == nil {
+			delete(manifest.Files, file)
+		} else {
+			manifest.Files[file] = *info
+		}
+	}
+
+	// Files refreshed before a failure are up to date
+	manifest.LastUpdate = time.Now()
+	if err := idx.saveManifest(manifest); err != nil {
+		return fmt.Errorf("failed to save manifest: %w", err)
+	}
+	return refreshErr
+}
+
+// refreshFile replaces the chunks of one file and returns its manifest entry,
+// nil when the file has been deleted or is now skipped by the file filter
+func (idx *Indexer) refreshFile(file, commitSHA string) (*FileInfo, error) {
+	fullPath := filepath.Join(idx.projectPath, file)
+	info, err := os.Stat(fullPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	// A file that grew too large or became minified loses its chunks, as 'oview index' would skip it
+	removed := os.IsNotExist(err) || idx.filter.skipReason(fullPath, info) != ""
+
+	// As in Index, IndexedAt is the time before reading
+	readAt := time.Now()
+	var content []byte
+	var chunks []Chunk
+	var vectors [][]float32
+	if !removed {
+		if content, err = os.ReadFile(fullPath); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		chunks, err = idx.chunker.ChunkFile(file, content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to chunk %s: %w", file, err)
+		}
+		assignLines(string(content), chunks)
+		for _, chunk := range chunks {
+			embedding, err := idx.embedder.Embed(chunk.Content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to embed %s: %w", file, err)
+			}
+			vectors = append(vectors, embedding)
+		}
+	}
+
+	tx, err := idx.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM chunks WHERE project_id = $1 AND path = $2", idx.projectID, file); err != nil {
+		return nil, fmt.Errorf("failed to clear chunks of %s: %w", file, err)
+	}
+	chunkIDs := make([]int, len(chunks))
+	for i, chunk := range chunks {
+		if chunkIDs[i], err = idx.insertChunk(tx, chunk, vectors[i], commitSHA); err != nil {
+			return nil, fmt.Errorf("failed to store chunk of %s: %w", file, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit chunks of %s: %w", file, err)
+	}
+
+	// Outside the transaction: a failed identifier insert must not undo the chunks
+	for i, chunk := range chunks {
+		idx.indexIdentifiers(chunkIDs[i], chunk)
+	}
+
+	if removed {
+		return nil, nil
+	}
+	hash := sha256.Sum256(content)
+	return &FileInfo{
+		Path:      file,
+		Hash:      hex.EncodeToString(hash[:]),
+		Chunks:    len(chunks),
+		IndexedAt: readAt,
+	}, nil
+}
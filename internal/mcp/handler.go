@@ -1,30 +1,64 @@
 package mcp
 
 import (
-	"database/sql"
 	"fmt"
-	"os"
+	"sort"
 	"strings"
+	"sync"
 
-	_ "github.com/lib/pq"
 	"github.com/yourusername/oview/internal/config"
 	"github.com/yourusername/oview/internal/embeddings"
+	"github.com/yourusername/oview/internal/indexer"
+	"github.com/yourusername/oview/internal/search"
 )
 
+// maxRefresh bounds the files re-indexed on the fly by one call, beyond which
+// stale results are only flagged
+const maxRefresh = 20
+
 // ToolHandler handles MCP tool calls
 type ToolHandler struct {
+	projectPath   string
 	projectConfig *config.ProjectConfig
 	globalConfig  *config.GlobalConfig
-	db            *sql.DB
-	generator     embeddings.Generator
+
+	mu      sync.Mutex
+	engine  *search.Engine
+	cache   *search.EmbeddingCache // query embeddings, shared by every call of the session
+	warmErr error                  // failure of the startup Warm, reported by project_info
 }
 
 // NewToolHandler creates a new tool handler
-func NewToolHandler(projectConfig *config.ProjectConfig, globalConfig *config.GlobalConfig) *ToolHandler {
+func NewToolHandler(projectPath string, projectConfig *config.ProjectConfig, globalConfig *config.GlobalConfig) *ToolHandler {
 	return &ToolHandler{
+		projectPath:   projectPath,
 		projectConfig: projectConfig,
 		globalConfig:  globalConfig,
+		cache:         search.NewEmbeddingCache(projectConfig.Search.WithDefaults().QueryCache.Size),
+	}
+}
+
+// Warm connects to the database and preloads the persisted query embeddings,
+// so the first calls of a session don't pay for the connection and the provider.
+// The error is kept for project_info.
+func (h *ToolHandler) Warm() error {
+	err := h.warm()
+	h.mu.Lock()
+	h.warmErr = err
+	h.mu.Unlock()
+	return err
+}
+
+// warm does the work of Warm
+func (h *ToolHandler) warm() error {
+	if err := h.connect(); err != nil {
+		return err
+	}
+	if !h.projectConfig.Search.QueryCache.Persist {
+		return nil
 	}
+	_, err := h.cache.Warm(h.engine.EmbeddingModel())
+	return err
 }
 
 // CallTool executes a tool and returns the result
@@ -36,6 +70,20 @@ func (h *ToolHandler) CallTool(name string, args map[string]interface{}) (interf
 		return h.handleGetContext(args)
 	case "project_info":
 		return h.handleProjectInfo(args)
+	case "assemble_context":
+		return h.handleAssembleContext(args)
+	case "find_symbol":
+		return h.handleFindSymbol(args)
+	case "find_references":
+		return h.handleFindReferences(args)
+	case "related_files":
+		return h.handleRelatedFiles(args)
+	case "repo_map":
+		return h.handleRepoMap(args)
+	case "find_route":
+		return h.handleFindRoute(args)
+	case "describe_entity":
+		return h.handleDescribeEntity(args)
 	default:
 		return nil, fmt.Errorf("unknown tool: %s", name)
 	}
@@ -49,43 +97,59 @@ func (h *ToolHandler) handleSearch(args map[string]interface{}) (interface{}, er
 		return nil, fmt.Errorf("query is required")
 	}
 
-	limit := 5
-	if l, ok := args["limit"].(float64); ok {
-		limit = int(l)
-		if limit > 20 {
-			limit = 20
-		}
+	// Connect to database if needed
+	if err := h.connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Initialize embeddings generator if needed
-	if h.generator == nil {
-		if err := h.initGenerator(); err != nil {
-			return nil, fmt.Errorf("failed to initialize embeddings: %w", err)
-		}
+	opts := h.engine.DefaultOptions()
+	opts.Query = query
+	opts.Filters = filtersFromArgs(args)
+	if l, ok := args["limit"].(float64); ok {
+		opts.Limit = int(l)
 	}
-
-	// Generate query embedding
-	queryEmbedding, err := h.generator.Embed(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate embedding: %w", err)
+	if m, ok := args["mode"].(string); ok && m != "" {
+		opts.Mode = m
 	}
-
-	// Connect to database if needed
-	if h.db == nil {
-		if err := h.connectDB(); err != nil {
-			return nil, fmt.Errorf("failed to connect to database: %w", err)
-		}
+	if v, ok := args["mmr"].(bool); ok {
+		opts.MMR = v
+	}
+	if v, ok := args["max_per_file"].(float64); ok {
+		opts.MaxPerFile = int(v)
+	}
+	if v, ok := args["min_similarity"].(float64); ok {
+		opts.Threshold = v
+	}
+	if v, ok := args["expand"].(float64); ok {
+		opts.Expand = int(v)
 	}
+	if v, ok := args["max_tokens"].(float64); ok {
+		opts.ExpandBudget = int(v)
+	}
+	if v, ok := args["rerank"].(string); ok && v != "" {
+		opts.Rerank = v
+	}
+	if v, ok := args["explain"].(bool); ok {
+		opts.Explain = v
+	}
+
+	refreshed, refreshErr := h.refreshStale()
 
 	// Search
-	results, err := h.searchSimilarChunks(queryEmbedding, limit)
+	resp, err := h.engine.Search(opts)
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
+	paths := make([]string, len(resp.Results))
+	for i, r := range resp.Results {
+		paths[i] = r.Path
+	}
+	stale := h.staleness(paths...)
+
 	// Format results
-	formattedResults := make([]map[string]interface{}, len(results))
-	for i, r := range results {
+	formattedResults := make([]map[string]interface{}, len(resp.Results))
+	for i, r := range resp.Results {
 		formattedResults[i] = map[string]interface{}{
 			"path":       r.Path,
 			"type":       r.Type,
@@ -94,12 +158,121 @@ func (h *ToolHandler) handleSearch(args map[string]interface{}) (interface{}, er
 			"content":    r.Content,
 			"similarity": fmt.Sprintf("%.2f%%", r.Similarity*100),
 		}
+		if resp.Mode != search.ModeVector {
+			formattedResults[i]["lexical_score"] = r.LexicalScore
+			formattedResults[i]["score"] = r.Score
+		}
+		if resp.Reranker != "" {
+			formattedResults[i]["rerank_score"] = r.RerankScore
+		}
+		if r.StartLine > 0 {
+			formattedResults[i]["start_line"] = r.StartLine
+			formattedResults[i]["end_line"] = r.EndLine
+		}
+		if r.Explain != nil {
+			formattedResults[i]["explain"] = r.Explain
+		}
+		if r.Excerpt != "" {
+			formattedResults[i]["excerpt"] = r.Excerpt
+			if r.ExcerptStart > 0 {
+				formattedResults[i]["excerpt_lines"] = fmt.Sprintf("%d-%d", r.ExcerptStart, r.ExcerptEnd)
+			}
+		}
+		if reason, ok := stale[r.Path]; ok {
+			formattedResults[i]["stale"] = reason
+		}
+	}
+
+	result := map[string]interface{}{
+		"query":          query,
+		"interpretation": resp.Interpretation(),
+		"mode":           resp.Mode,
+		"count":          len(resp.Results),
+		"results":        formattedResults,
+		"timings_ms":     resp.TimingsMS(),
+	}
+	if resp.Reranker != "" {
+		result["reranker"] = resp.Reranker
+	}
+	warnings := append(resp.Warnings, staleWarnings(stale, refreshErr)...)
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	if len(refreshed) > 0 {
+		result["refreshed"] = refreshed
+	}
+	if opts.Explain {
+		result["cut"] = cutInfo(resp.Cut, 10)
+	}
+
+	return result, nil
+}
+
+// handleAssembleContext packs the best search hits into a token budget
+func (h *ToolHandler) handleAssembleContext(args map[string]interface{}) (interface{}, error) {
+	// Parse arguments
+	query, ok := args["query"].(string)
+	if !ok || query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+
+	maxTokens := search.DefaultAssembleTokens
+	if v, ok := args["max_tokens"].(float64); ok {
+		maxTokens = int(v)
+	}
+
+	// Connect to database if needed
+	if err := h.connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	opts := h.engine.DefaultOptions()
+	opts.Query = query
+	opts.Filters = filtersFromArgs(args)
+	if m, ok := args["mode"].(string); ok && m != "" {
+		opts.Mode = m
+	}
+
+	asm, err := h.engine.Assemble(opts, maxTokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assemble context: %w", err)
+	}
+
+	// Format results
+	files := make([]map[string]interface{}, len(asm.Files))
+	for i, f := range asm.Files {
+		files[i] = map[string]interface{}{
+			"path":    f.Path,
+			"symbols": f.Symbols,
+			"tokens":  f.Tokens,
+		}
+		if f.StartLine > 0 {
+			files[i]["lines"] = fmt.Sprintf("%d-%d", f.StartLine, f.EndLine)
+		}
+	}
+
+	omitted := make([]map[string]interface{}, len(asm.Omitted))
+	for i, o := range asm.Omitted {
+		omitted[i] = map[string]interface{}{
+			"path":   o.Path,
+			"symbol": o.Symbol,
+			"tokens": o.Tokens,
+			"reason": o.Reason,
+		}
+		if o.StartLine > 0 {
+			omitted[i]["lines"] = fmt.Sprintf("%d-%d", o.StartLine, o.EndLine)
+		}
 	}
 
 	return map[string]interface{}{
-		"query":   query,
-		"count":   len(results),
-		"results": formattedResults,
+		"query":          query,
+		"interpretation": asm.Response.Interpretation(),
+		"mode":           asm.Response.Mode,
+		"max_tokens":     asm.MaxTokens,
+		"used_tokens":    asm.UsedTokens,
+		"context":        asm.Context,
+		"files":          files,
+		"omitted":        omitted,
 	}, nil
 }
 
@@ -122,17 +295,18 @@ func (h *ToolHandler) handleGetContext(args map[string]interface{}) (interface{}
 	}
 
 	// Connect to database if needed
-	if h.db == nil {
-		if err := h.connectDB(); err != nil {
-			return nil, fmt.Errorf("failed to connect to database: %w", err)
-		}
+	if err := h.connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	refreshed, refreshErr := h.refreshStale()
+
 	// Get context
 	results, err := h.getFileContext(path, symbol, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get context: %w", err)
 	}
+	stale := h.staleness(path)
 
 	// Format results
 	formattedResults := make([]map[string]interface{}, len(results))
@@ -146,42 +320,395 @@ func (h *ToolHandler) handleGetContext(args map[string]interface{}) (interface{}
 		}
 	}
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"path":    path,
 		"symbol":  symbol,
 		"count":   len(results),
 		"context": formattedResults,
+	}
+	if reason, ok := stale[path]; ok {
+		result["stale"] = reason
+	}
+	if warnings := staleWarnings(stale, refreshErr); len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	if len(refreshed) > 0 {
+		result["refreshed"] = refreshed
+	}
+
+	// Direct collaborators, when the dependency graph has been built
+	if deps, err := h.engine.RelatedFiles(path, 1); err == nil && len(deps.Related) > 0 {
+		related := make([]map[string]interface{}, len(deps.Related))
+		for i, r := range deps.Related {
+			related[i] = map[string]interface{}{
+				"path":      r.Path,
+				"direction": r.Direction,
+				"kind":      r.Kind,
+			}
+		}
+		result["related_files"] = related
+	}
+
+	return result, nil
+}
+
+// handleFindSymbol looks up the definitions of a symbol
+func (h *ToolHandler) handleFindSymbol(args map[string]interface{}) (interface{}, error) {
+	// Parse arguments
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	limit := search.DefaultSymbolLimit
+	if l, ok := args["limit"].(float64); ok {
+		limit = int(l)
+	}
+
+	// Connect to database if needed
+	if err := h.connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	defs, err := h.engine.FindSymbol(name, limit, filtersFromArgs(args))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find symbol: %w", err)
+	}
+
+	// Format results
+	definitions := make([]map[string]interface{}, len(defs))
+	for i, d := range defs {
+		definitions[i] = map[string]interface{}{
+			"symbol":    d.Symbol,
+			"path":      d.Path,
+			"type":      d.Type,
+			"language":  d.Language,
+			"match":     d.Match,
+			"signature": d.Signature,
+		}
+		if d.Match == search.MatchFuzzy {
+			definitions[i]["similarity"] = fmt.Sprintf("%.2f", d.Similarity)
+		}
+		if d.StartLine > 0 {
+			definitions[i]["start_line"] = d.StartLine
+			definitions[i]["end_line"] = d.EndLine
+		}
+	}
+
+	return map[string]interface{}{
+		"name":        search.NormalizeSymbol(name),
+		"count":       len(defs),
+		"definitions": definitions,
 	}, nil
 }
 
-// handleProjectInfo returns project information
-func (h *ToolHandler) handleProjectInfo(args map[string]interface{}) (interface{}, error) {
-	// Check database status
-	dbStatus := "unknown"
-	if h.db == nil {
-		if err := h.connectDB(); err == nil {
-			dbStatus = "connected"
-		} else {
-			dbStatus = "not connected"
+// handleFindReferences looks up the lines using an identifier
+func (h *ToolHandler) handleFindReferences(args map[string]interface{}) (interface{}, error) {
+	// Parse arguments
+	symbol, ok := args["symbol"].(string)
+	if !ok || symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+
+	opts := search.ReferenceOptions{
+		Limit:   search.DefaultReferenceLimit,
+		Context: search.DefaultReferenceContext,
+		Filters: filtersFromArgs(args),
+	}
+	if l, ok := args["limit"].(float64); ok {
+		opts.Limit = int(l)
+	}
+	if c, ok := args["context"].(float64); ok {
+		opts.Context = int(c)
+	}
+	if v, ok := args["ignore_case"].(bool); ok {
+		opts.IgnoreCase = v
+	}
+	if v, ok := args["include_definitions"].(bool); ok {
+		opts.Definitions = v
+	}
+
+	// Connect to database if needed
+	if err := h.connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	refs, total, err := h.engine.FindReferences(symbol, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find references: %w", err)
+	}
+
+	// Format results
+	references := make([]map[string]interface{}, len(refs))
+	for i, r := range refs {
+		references[i] = map[string]interface{}{
+			"path":    r.Path,
+			"symbol":  r.Symbol,
+			"kind":    r.Kind,
+			"text":    r.Text,
+			"context": r.Context,
 		}
-	} else {
-		if err := h.db.Ping(); err == nil {
-			dbStatus = "connected"
+		if r.Line > 0 {
+			references[i]["line"] = r.Line
+		}
+	}
+
+	return map[string]interface{}{
+		"identifier": search.ReferenceName(symbol),
+		"total":      total,
+		"count":      len(refs),
+		"references": references,
+	}, nil
+}
+
+// handleRelatedFiles walks the dependency graph from a file
+func (h *ToolHandler) handleRelatedFiles(args map[string]interface{}) (interface{}, error) {
+	// Parse arguments
+	path, ok := args["path"].(string)
+	if !ok || path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	depth := search.DefaultRelatedDepth
+	if d, ok := args["depth"].(float64); ok {
+		depth = int(d)
+	}
+
+	// Connect to database if needed
+	if err := h.connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	deps, err := h.engine.RelatedFiles(path, depth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load related files: %w", err)
+	}
+
+	// Format results
+	dependsOn := []map[string]interface{}{}
+	usedBy := []map[string]interface{}{}
+	for _, r := range deps.Related {
+		file := map[string]interface{}{
+			"path":  r.Path,
+			"kind":  r.Kind,
+			"depth": r.Depth,
+		}
+		if r.Depth > 1 {
+			file["via"] = r.Via
+		}
+		if r.Direction == search.DependsOn {
+			dependsOn = append(dependsOn, file)
 		} else {
-			dbStatus = "connection lost"
+			usedBy = append(usedBy, file)
 		}
 	}
+	external := make([]string, len(deps.External))
+	for i, x := range deps.External {
+		external[i] = x.Target
+	}
+
+	return map[string]interface{}{
+		"path":       deps.Path,
+		"depends_on": dependsOn,
+		"used_by":    usedBy,
+		"external":   external,
+	}, nil
+}
+
+// handleRepoMap returns the ranked repository map
+func (h *ToolHandler) handleRepoMap(args map[string]interface{}) (interface{}, error) {
+	// Parse arguments
+	opts := search.RepoMapOptions{Filters: filtersFromArgs(args)}
+	if t, ok := args["max_tokens"].(float64); ok {
+		opts.MaxTokens = int(t)
+	}
+	if n, ok := args["symbols"].(float64); ok {
+		opts.MaxSymbols = int(n)
+	}
+
+	// Connect to database if needed
+	if err := h.connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	m, err := h.engine.RepoMap(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build repository map: %w", err)
+	}
+
+	files := make([]string, len(m.Files))
+	for i, f := range m.Files {
+		files[i] = f.Path
+	}
+	return map[string]interface{}{
+		"map":         m.Text,
+		"files":       files,
+		"total_files": m.TotalFiles,
+		"tokens":      m.UsedTokens,
+	}, nil
+}
+
+// handleFindRoute finds the routes matching a URL or name with their controller action
+func (h *ToolHandler) handleFindRoute(args map[string]interface{}) (interface{}, error) {
+	// Parse arguments
+	query, ok := args["url_or_name"].(string)
+	if !ok || query == "" {
+		return nil, fmt.Errorf("url_or_name is required")
+	}
+
+	opts := search.RouteOptions{}
+	if m, ok := args["method"].(string); ok {
+		opts.Method = m
+	}
+	if l, ok := args["limit"].(float64); ok {
+		opts.Limit = int(l)
+	}
+
+	// Connect to database if needed
+	if err := h.connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	routes, err := h.engine.FindRoute(query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find route: %w", err)
+	}
+
+	// Format results
+	results := make([]map[string]interface{}, len(routes))
+	for i, r := range routes {
+		methods := r.Methods
+		if methods == nil {
+			methods = []string{}
+		}
+		route := map[string]interface{}{
+			"name":        r.Name,
+			"path":        r.Path,
+			"methods":     methods,
+			"controller":  r.Controller,
+			"declared_in": fmt.Sprintf("%s:%d", r.File, r.Line),
+			"match":       r.Match,
+		}
+		if len(r.Params) > 0 {
+			route["params"] = r.Params
+		}
+		if r.Handler != nil {
+			route["handler"] = map[string]interface{}{
+				"path":       r.Handler.Path,
+				"symbol":     r.Handler.Symbol,
+				"start_line": r.Handler.StartLine,
+				"end_line":   r.Handler.EndLine,
+				"content":    r.Handler.Content,
+			}
+		}
+		results[i] = route
+	}
+
+	return map[string]interface{}{
+		"query":  query,
+		"count":  len(results),
+		"routes": results,
+	}, nil
+}
+
+// handleDescribeEntity describes the Doctrine entities matching a name
+func (h *ToolHandler) handleDescribeEntity(args map[string]interface{}) (interface{}, error) {
+	// Parse arguments
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	// Connect to database if needed
+	if err := h.connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	entities, err := h.engine.DescribeEntity(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe entity: %w", err)
+	}
+
+	// Format results
+	results := make([]map[string]interface{}, len(entities))
+	for i, en := range entities {
+		fields := make([]map[string]interface{}, len(en.Fields))
+		for j, f := range en.Fields {
+			field := map[string]interface{}{
+				"name":     f.Name,
+				"column":   f.Column,
+				"nullable": f.Nullable,
+				"line":     f.Line,
+			}
+			if f.Relation != "" {
+				field["relation"] = f.Relation
+				field["target_entity"] = f.Target
+				if f.MappedBy != "" {
+					field["mapped_by"] = f.MappedBy
+				}
+				if f.InversedBy != "" {
+					field["inversed_by"] = f.InversedBy
+				}
+			} else {
+				field["type"] = f.Type
+			}
+			if f.Length > 0 {
+				field["length"] = f.Length
+			}
+			if f.ID {
+				field["id"] = true
+			}
+			fields[j] = field
+		}
+		refs := make([]string, len(en.ReferencedBy))
+		for j, ref := range en.ReferencedBy {
+			refs[j] = fmt.Sprintf("%s.%s (%s)", ref.Entity, ref.Field, ref.Relation)
+		}
+		results[i] = map[string]interface{}{
+			"class":         en.Class,
+			"table":         en.Table,
+			"repository":    en.Repository,
+			"declared_in":   fmt.Sprintf("%s:%d", en.File, en.Line),
+			"mapping":       en.Source,
+			"fields":        fields,
+			"referenced_by": refs,
+		}
+	}
+
+	return map[string]interface{}{
+		"query":    name,
+		"count":    len(results),
+		"entities": results,
+	}, nil
+}
+
+// handleProjectInfo returns project information
+func (h *ToolHandler) handleProjectInfo(args map[string]interface{}) (interface{}, error) {
+	// Check database status
+	dbStatus := "connected"
+	connected := true
+	if err := h.connect(); err != nil {
+		dbStatus = "not connected"
+		connected = false
+	} else if err := h.engine.DB().Ping(); err != nil {
+		dbStatus = "connection lost"
+	}
 
 	// Get chunk count
 	chunkCount := 0
-	if h.db != nil {
+	if connected {
 		var count int
-		err := h.db.QueryRow("SELECT COUNT(*) FROM chunks WHERE project_id = $1", h.projectConfig.ProjectID).Scan(&count)
+		err := h.engine.DB().QueryRow("SELECT COUNT(*) FROM chunks WHERE project_id = $1", h.projectConfig.ProjectID).Scan(&count)
 		if err == nil {
 			chunkCount = count
 		}
 	}
 
+	queryCache := cacheInfo(h.cache.Stats())
+	if warmErr := h.warmError(); warmErr != "" {
+		queryCache["warm_error"] = warmErr
+	}
+
 	return map[string]interface{}{
 		"project_id":   h.projectConfig.ProjectID,
 		"project_slug": h.projectConfig.ProjectSlug,
@@ -199,106 +726,186 @@ func (h *ToolHandler) handleProjectInfo(args map[string]interface{}) (interface{
 			"status":      dbStatus,
 			"chunk_count": chunkCount,
 		},
-		"stack": h.projectConfig.Stack,
+		"query_cache": queryCache,
+		"index":       h.indexInfo(),
+		"stack":       h.projectConfig.Stack,
 	}, nil
 }
 
-// initGenerator initializes the embeddings generator
-func (h *ToolHandler) initGenerator() error {
-	switch h.projectConfig.Embeddings.Provider {
-	case "openai":
-		apiKey := os.Getenv("OPENAI_API_KEY")
-		if apiKey == "" {
-			apiKey = h.projectConfig.Embeddings.APIKey
-		}
-		if apiKey == "" {
-			return fmt.Errorf("OPENAI_API_KEY not set")
+// indexInfo reports when the project was last indexed and the indexed files
+// changed or deleted since
+func (h *ToolHandler) indexInfo() map[string]interface{} {
+	manifest, err := indexer.LoadManifest(h.projectPath)
+	if err != nil {
+		return map[string]interface{}{"error": err.Error()}
+	}
+
+	stale := manifest.StaleFiles(h.projectPath)
+	info := map[string]interface{}{
+		"files":        len(manifest.Files),
+		"last_update":  manifest.LastUpdate,
+		"stale_files":  len(stale),
+		"auto_refresh": h.projectConfig.Search.RefreshStale,
+	}
+	if len(stale) > 0 {
+		shown := stale
+		if len(shown) > 10 {
+			shown = shown[:10]
 		}
-		h.generator = embeddings.NewOpenAIGenerator(apiKey, h.projectConfig.Embeddings.Model)
+		info["stale"] = shown
+		info["hint"] = "run 'oview index' to re-index changed files"
+	}
+	return info
+}
 
-	case "ollama":
-		baseURL := h.projectConfig.Embeddings.BaseURL
-		if baseURL == "" {
-			baseURL = "http://localhost:11434"
+// staleness returns the given indexed paths changed or deleted since indexing,
+// with the reason
+func (h *ToolHandler) staleness(paths ...string) map[string]string {
+	stale := make(map[string]string)
+	manifest, err := indexer.LoadManifest(h.projectPath)
+	if err != nil {
+		return stale
+	}
+	for _, path := range paths {
+		if _, seen := stale[path]; seen {
+			continue
+		}
+		if reason := manifest.Staleness(h.projectPath, path); reason != "" {
+			stale[path] = reason
 		}
-		h.generator = embeddings.NewOllamaGenerator(baseURL, h.projectConfig.Embeddings.Model)
+	}
+	return stale
+}
 
-	default:
-		return fmt.Errorf("unsupported embeddings provider: %s", h.projectConfig.Embeddings.Provider)
+// refreshStale re-indexes the files changed or deleted since indexing when
+// search.refresh_stale is set, so results match the working tree
+func (h *ToolHandler) refreshStale() ([]string, error) {
+	if !h.projectConfig.Search.RefreshStale {
+		return nil, nil
+	}
+	manifest, err := indexer.LoadManifest(h.projectPath)
+	if err != nil {
+		return nil, err
+	}
+	stale := manifest.StaleFiles(h.projectPath)
+	if len(stale) == 0 {
+		return nil, nil
+	}
+	if len(stale) > maxRefresh {
+		return nil, fmt.Errorf("%d files changed since indexing (more than %d), run 'oview index'", len(stale), maxRefresh)
 	}
 
-	return nil
-}
+	ragConfig, err := config.LoadRAGConfig(h.projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load RAG config: %w", err)
+	}
+	embedder, err := embeddings.NewGenerator(h.projectConfig.Embeddings)
+	if err != nil {
+		return nil, err
+	}
 
-// connectDB connects to the project database
-func (h *ToolHandler) connectDB() error {
-	dbName := fmt.Sprintf("oview_%s", h.projectConfig.ProjectSlug)
-	dbUser := fmt.Sprintf("oview_%s", h.projectConfig.ProjectSlug)
-	dbPassword := h.projectConfig.Database.Password
+	files := make([]string, len(stale))
+	for i, f := range stale {
+		files[i] = f.Path
+	}
+	idx := indexer.New(h.projectPath, h.projectConfig.ProjectID, h.engine.DB(), ragConfig, embedder, h.projectConfig.Embeddings.Model)
+	if err := idx.Refresh(files); err != nil {
+		return nil, fmt.Errorf("failed to refresh stale files: %w", err)
+	}
+	return files, nil
+}
 
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		dbUser, dbPassword, h.globalConfig.PostgresHost, h.globalConfig.PostgresPort, dbName)
+// staleWarnings explains stale results and failed refreshes to the agent
+func staleWarnings(stale map[string]string, refreshErr error) []string {
+	var warnings []string
+	if refreshErr != nil {
+		warnings = append(warnings, refreshErr.Error())
+	}
+	if len(stale) > 0 {
+		paths := make([]string, 0, len(stale))
+		for path := range stale {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		warnings = append(warnings, fmt.Sprintf("%d files changed since indexing, their results may be outdated (read them from disk or run 'oview index'): %s", len(paths), strings.Join(paths, ", ")))
+	}
+	return warnings
+}
 
-	db, err := sql.Open("postgres", dsn)
-	if err != nil {
-		return err
+// cutInfo formats the first max candidates left out of the results
+func cutInfo(cuts []search.Cut, max int) map[string]interface{} {
+	shown := cuts
+	if len(shown) > max {
+		shown = shown[:max]
 	}
 
-	if err := db.Ping(); err != nil {
-		return err
+	candidates := make([]map[string]interface{}, len(shown))
+	for i, c := range shown {
+		candidates[i] = map[string]interface{}{
+			"path":   c.Result.Path,
+			"symbol": c.Result.Symbol,
+			"stage":  c.Stage,
+			"reason": c.Reason,
+		}
+	}
+	return map[string]interface{}{
+		"total":      len(cuts),
+		"candidates": candidates,
 	}
+}
 
-	h.db = db
-	return nil
+// cacheInfo formats the query embedding cache statistics
+func cacheInfo(stats search.CacheStats) map[string]interface{} {
+	info := map[string]interface{}{
+		"size":           stats.Size,
+		"capacity":       stats.Capacity,
+		"persisted":      stats.Persisted,
+		"hits":           stats.Hits,
+		"persisted_hits": stats.PersistedHits,
+		"misses":         stats.Misses,
+		"hit_rate":       fmt.Sprintf("%.1f%%", stats.HitRate()*100),
+	}
+	if stats.PersistError != "" {
+		info["persist_error"] = stats.PersistError
+	}
+	return info
 }
 
-// SearchResult represents a search result
-type SearchResult struct {
-	ID         int
-	Path       string
-	Type       string
-	Language   string
-	Symbol     string
-	Content    string
-	Similarity float64
+// warmError returns the failure of the startup Warm, empty when it succeeded
+func (h *ToolHandler) warmError() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.warmErr == nil {
+		return ""
+	}
+	return h.warmErr.Error()
 }
 
-// searchSimilarChunks searches for chunks similar to the query embedding
-func (h *ToolHandler) searchSimilarChunks(queryEmbedding []float32, limit int) ([]SearchResult, error) {
-	// Convert embedding to PostgreSQL array format
-	embeddingStr := embeddingToString(queryEmbedding)
-
-	query := `
-		SELECT
-			id, path, type, COALESCE(language, ''), COALESCE(symbol, ''), content,
-			1 - (embedding <=> $1::vector) as similarity
-		FROM chunks
-		WHERE project_id = $2
-		ORDER BY embedding <=> $1::vector
-		LIMIT $3
-	`
-
-	rows, err := h.db.Query(query, embeddingStr, h.projectConfig.ProjectID, limit)
+// connect opens the search engine on the project database
+func (h *ToolHandler) connect() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.engine != nil {
+		return nil
+	}
+
+	engine, err := search.Open(h.projectConfig, h.globalConfig)
 	if err != nil {
-		return nil, fmt.Errorf("query failed: %w", err)
+		return err
 	}
-	defer rows.Close()
 
-	var results []SearchResult
-	for rows.Next() {
-		var r SearchResult
-		err := rows.Scan(&r.ID, &r.Path, &r.Type, &r.Language, &r.Symbol, &r.Content, &r.Similarity)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
-		}
-		results = append(results, r)
+	if h.projectConfig.Search.QueryCache.Persist {
+		h.cache.Persist(engine.DB(), h.projectConfig.ProjectID)
 	}
+	engine.SetCache(h.cache)
 
-	return results, nil
+	h.engine = engine
+	return nil
 }
 
 // getFileContext gets context for a specific file
-func (h *ToolHandler) getFileContext(path string, symbol string, limit int) ([]SearchResult, error) {
+func (h *ToolHandler) getFileContext(path string, symbol string, limit int) ([]search.Result, error) {
 	var query string
 	var args []interface{}
 
@@ -332,15 +939,15 @@ func (h *ToolHandler) getFileContext(path string, symbol string, limit int) ([]S
 		args = []interface{}{h.projectConfig.ProjectID, path, limit}
 	}
 
-	rows, err := h.db.Query(query, args...)
+	rows, err := h.engine.DB().Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
 	defer rows.Close()
 
-	var results []SearchResult
+	var results []search.Result
 	for rows.Next() {
-		var r SearchResult
+		var r search.Result
 		err := rows.Scan(&r.ID, &r.Path, &r.Type, &r.Language, &r.Symbol, &r.Content, &r.Similarity)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
@@ -351,11 +958,37 @@ func (h *ToolHandler) getFileContext(path string, symbol string, limit int) ([]S
 	return results, nil
 }
 
-// embeddingToString converts a float32 slice to PostgreSQL vector string format
-func embeddingToString(embedding []float32) string {
-	parts := make([]string, len(embedding))
-	for i, v := range embedding {
-		parts[i] = fmt.Sprintf("%f", v)
+// filtersFromArgs reads the structured search filters of a tool call
+func filtersFromArgs(args map[string]interface{}) search.Filters {
+	return search.Filters{
+		Types:      stringList(args["type"]),
+		Languages:  stringList(args["language"]),
+		Paths:      stringList(args["path"]),
+		Components: stringList(args["component"]),
+		Sources:    stringList(args["source"]),
+		Exclude:    stringList(args["exclude"]),
+	}
+}
+
+// stringList reads a tool argument given either as a string (comma-separated) or an array of strings
+func stringList(v interface{}) []string {
+	var values []string
+	switch t := v.(type) {
+	case string:
+		values = strings.Split(t, ",")
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	var result []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
 	}
-	return "[" + strings.Join(parts, ",") + "]"
+	return result
 }
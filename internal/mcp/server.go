@@ -39,7 +39,15 @@ func (s *Server) Run() error {
 	}
 
 	// Initialize tool handler
-	s.handler = NewToolHandler(s.projectConfig, s.globalConfig)
+	s.handler = NewToolHandler(s.projectPath, s.projectConfig, s.globalConfig)
+
+	// Connect and load cached query embeddings while the client starts up.
+	// A failure is reported by project_info, and the tools connect again on use
+	go func() {
+		if err := s.handler.Warm(); err != nil {
+			fmt.Fprintf(os.Stderr, "oview mcp: warm-up failed: %v\n", err)
+		}
+	}()
 
 	// MCP protocol: read from stdin, write to stdout
 	scanner := bufio.NewScanner(os.Stdin)
@@ -111,26 +119,84 @@ func (s *Server) handleToolsList(req *MCPRequest) *MCPResponse {
 	tools := []Tool{
 		{
 			Name:        "search",
-			Description: "Search the codebase using semantic similarity. Returns relevant code chunks with similarity scores.",
+			Description: "Search the codebase using semantic similarity, exact lexical matching or both (hybrid). Returns relevant code chunks with scores. Results from files changed since indexing are marked stale: read those files from disk.",
 			InputSchema: map[string]interface{}{
 				"type": "object",
-				"properties": map[string]interface{}{
+				"properties": withFilterProperties(map[string]interface{}{
 					"query": map[string]interface{}{
 						"type":        "string",
-						"description": "The search query (e.g., 'authentication logic', 'database connection', 'error handling')",
+						"description": "The search query (e.g., 'authentication logic', 'database connection', 'error handling'). Supports inline qualifiers: type:, lang:, path:, component:, source:, symbol:, exclude: (a leading '-' on the value excludes, e.g. type:-test). Quoted phrases must appear verbatim. Example: 'lang:php path:src/Controller type:-test \"password reset\"'",
 					},
 					"limit": map[string]interface{}{
 						"type":        "integer",
 						"description": "Number of results to return (default: 5, max: 20)",
 						"default":     5,
 					},
-				},
+					"mode": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"vector", "lexical", "hybrid"},
+						"description": "Ranking mode: 'vector' (semantic), 'lexical' (exact identifiers, error codes) or 'hybrid' (both fused). Default comes from the project config.",
+					},
+					"mmr": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Re-rank with maximal marginal relevance so near-duplicate chunks don't fill the results. Default comes from the project config (on for new projects).",
+					},
+					"max_per_file": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum results from the same file, 0 for unlimited. Default comes from the project config (2 for new projects).",
+					},
+					"expand": map[string]interface{}{
+						"type":        "integer",
+						"description": "Return each hit with this many neighbouring chunks of the same file on each side (or the whole symbol when the hit is part of a split one), merged into an 'excerpt' with line numbers. Default: 0 (off).",
+					},
+					"max_tokens": map[string]interface{}{
+						"type":        "integer",
+						"description": "Token budget shared by all excerpts when 'expand' is set (default: 4000). Hits beyond the budget come back without excerpt.",
+					},
+					"min_similarity": map[string]interface{}{
+						"type":        "number",
+						"description": "Drop semantic hits below this cosine similarity (0-1, e.g. 0.3). Default comes from the project config.",
+					},
+					"rerank": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"none", "lexical", "llm", "openai-compatible"},
+						"description": "Re-score the top hits against the query: 'lexical' (built-in, offline), 'llm' (the project's LLM) or 'openai-compatible' (endpoint from the project config). Default comes from the project config.",
+					},
+					"explain": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Add a score breakdown to each result (vector distance, lexical components, boosts, matched filters, commit and index time) and list the candidates that were cut and why. Use it when results look off.",
+					},
+				}),
+				"required": []string{"query"},
+			},
+		},
+		{
+			Name:        "assemble_context",
+			Description: "Build a ready-to-read context for a task within a token budget: runs the search, drops overlapping chunks, groups hits by file in line order with path and line headers, and lists what was left out. Prefer this over repeated search/get_context calls.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": withFilterProperties(map[string]interface{}{
+					"query": map[string]interface{}{
+						"type":        "string",
+						"description": "What the context is for (same syntax as the search tool, inline qualifiers allowed)",
+					},
+					"max_tokens": map[string]interface{}{
+						"type":        "integer",
+						"description": "Token budget for the assembled context (default: 6000)",
+						"default":     6000,
+					},
+					"mode": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"vector", "lexical", "hybrid"},
+						"description": "Ranking mode. Default comes from the project config.",
+					},
+				}),
 				"required": []string{"query"},
 			},
 		},
 		{
 			Name:        "get_context",
-			Description: "Get relevant code context for a specific file or symbol. Useful before making changes to understand related code.",
+			Description: "Get relevant code context for a specific file or symbol. Useful before making changes to understand related code. Also lists the file's direct collaborators (related_files with depth 1). Marked stale when the file changed since indexing.",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -151,9 +217,135 @@ func (s *Server) handleToolsList(req *MCPRequest) *MCPResponse {
 				"required": []string{"path"},
 			},
 		},
+		{
+			Name:        "find_symbol",
+			Description: "Go to definition: find where a class, function, method or config key is defined, by name or partial name (e.g. login, UserController::login, useAuth). Returns path, line range and signature, ranked by exactness (exact, case-insensitive, class member, prefix, substring, then fuzzy). Prefer this over search when you know the name.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": withFilterProperties(map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Symbol name or partial name. Class::method, Class->method and namespaced names are accepted.",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of definitions to return (default: 10)",
+						"default":     10,
+					},
+				}),
+				"required": []string{"name"},
+			},
+		},
+		{
+			Name:        "find_references",
+			Description: "Find usages: the lines that use a function, method, class or service id (e.g. sendResetEmail, UserRepository, app.mailer), with surrounding lines. Exact lookup in the identifier index, use it before renaming or changing a signature.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": withFilterProperties(map[string]interface{}{
+					"symbol": map[string]interface{}{
+						"type":        "string",
+						"description": "Identifier to look up. For Class::method the method name is looked up.",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of references to return (default: 50)",
+						"default":     50,
+					},
+					"context": map[string]interface{}{
+						"type":        "integer",
+						"description": "Lines shown on each side of a reference (default: 2)",
+						"default":     2,
+					},
+					"ignore_case": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Match the identifier case-insensitively",
+					},
+					"include_definitions": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Also return the lines declaring the identifier",
+					},
+				}),
+				"required": []string{"symbol"},
+			},
+		},
+		{
+			Name:        "related_files",
+			Description: "Collaborators of a file from the dependency graph: the files it depends on (PHP use, JS/TS imports, Twig extends/include, rendered templates, Symfony service references) and the files that depend on it, up to depth hops. Use it to see what a change can affect.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "File path relative to the project root",
+					},
+					"depth": map[string]interface{}{
+						"type":        "integer",
+						"description": "Hops to follow in each direction (default: 1, max: 3)",
+						"default":     1,
+					},
+				},
+				"required": []string{"path"},
+			},
+		},
+		{
+			Name:        "find_route",
+			Description: "Symfony projects: find the controller action handling a URL or route. Accepts a URL (/api/users/42, a full URL or 'GET /api/users/42'), a route path (/api/users/{id}), a route name (api_users_show) or a controller. Returns the matching routes with methods, name, placeholder values and the controller action code.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"url_or_name": map[string]interface{}{
+						"type":        "string",
+						"description": "URL, route path, route name or controller (Class::action)",
+					},
+					"method": map[string]interface{}{
+						"type":        "string",
+						"description": "Only routes accepting this HTTP method (e.g. POST)",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of routes to return (default: 10)",
+						"default":     10,
+					},
+				},
+				"required": []string{"url_or_name"},
+			},
+		},
+		{
+			Name:        "describe_entity",
+			Description: "Symfony projects: describe a Doctrine entity from the mapping extracted at index time. Accepts a class (App\\Entity\\User), a short name (User) or a table name. Returns the table, the fields with their column, type, length and nullability, the associations with their target entity and owning/inverse side, and the associations of other entities targeting it.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Entity class, short name or table name",
+					},
+				},
+				"required": []string{"name"},
+			},
+		},
+		{
+			Name:        "repo_map",
+			Description: "Compact overview of the repository: the most central files (ranked by PageRank over imports, service references and identifier references) with their key symbols and signatures, within a token budget. Read it when starting a task instead of running many searches.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": withFilterProperties(map[string]interface{}{
+					"max_tokens": map[string]interface{}{
+						"type":        "integer",
+						"description": "Token budget of the map (default: 2000)",
+						"default":     2000,
+					},
+					"symbols": map[string]interface{}{
+						"type":        "integer",
+						"description": "Symbols shown per file (default: 8)",
+						"default":     8,
+					},
+				}),
+			},
+		},
 		{
 			Name:        "project_info",
-			Description: "Get information about the current project (stack, embeddings config, database status)",
+			Description: "Get information about the current project (stack, embeddings config, database status, files changed since indexing)",
 			InputSchema: map[string]interface{}{
 				"type":       "object",
 				"properties": map[string]interface{}{},
@@ -251,3 +443,43 @@ type Tool struct {
 	Description string                 `json:"description"`
 	InputSchema map[string]interface{} `json:"inputSchema"`
 }
+
+// withFilterProperties adds the structured filter properties shared by the search tools
+func withFilterProperties(properties map[string]interface{}) map[string]interface{} {
+	filters := map[string]interface{}{
+		"type": map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string", "enum": []string{"code", "test", "config", "doc"}},
+			"description": "Only return chunks of these types",
+		},
+		"language": map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string"},
+			"description": "Only return chunks in these languages, case-insensitive (e.g. ['php'], ['typescript', 'javascript'])",
+		},
+		"path": map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string"},
+			"description": "Only return chunks whose path starts with one of these prefixes or matches one of these globs (e.g. ['src/Controller'], ['src/**/*Repository.php'])",
+		},
+		"component": map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string"},
+			"description": "Only return chunks of these components (the file's parent directory, e.g. ['Controller'])",
+		},
+		"source": map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string", "enum": []string{"repo", "docs", "external"}},
+			"description": "Only return chunks from these sources",
+		},
+		"exclude": map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string"},
+			"description": "Leave out paths starting with one of these prefixes or matching one of these globs (e.g. ['vendor', 'src/Legacy/**'])",
+		},
+	}
+	for name, schema := range filters {
+		properties[name] = schema
+	}
+	return properties
+}
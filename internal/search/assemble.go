@@ -0,0 +1,170 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultAssembleTokens is the token budget of an assembled context when none is given
+const DefaultAssembleTokens = 6000
+
+// Assembly is a token-budgeted context built from search hits
+type Assembly struct {
+	Response   *Response
+	MaxTokens  int
+	UsedTokens int
+	Context    string // packed excerpts grouped by file, with headers
+	Files      []AssembledFile
+	Omitted    []OmittedHit // hits left out, best first
+}
+
+// AssembledFile describes the material packed for one file
+type AssembledFile struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Symbols   []string
+	Tokens    int
+}
+
+// OmittedHit is a search hit that did not make it into the context
+type OmittedHit struct {
+	Path      string
+	Symbol    string
+	StartLine int
+	EndLine   int
+	Tokens    int
+	Reason    string // budget, duplicate
+}
+
+// Assemble runs a search and packs the best hits into maxTokens: overlapping chunks
+// are de-duplicated, hits are grouped by file in line order and each file gets a
+// header with its path and line range
+func (e *Engine) Assemble(opts Options, maxTokens int) (*Assembly, error) {
+	if maxTokens <= 0 {
+		maxTokens = DefaultAssembleTokens
+	}
+	opts.Limit = MaxLimit
+	opts.Expand = 0
+
+	resp, err := e.Search(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	asm := &Assembly{Response: resp, MaxTokens: maxTokens}
+
+	type fileGroup struct {
+		path     string
+		language string
+		hits     []Result
+		section  string // rendered with the hits packed so far
+		start    int
+		end      int
+	}
+	var order []*fileGroup
+	groups := make(map[string]*fileGroup)
+	seen := make(map[string]bool)
+	used := 0
+
+	for _, r := range resp.Results {
+		tokens := EstimateTokens(r.Content)
+		omitted := OmittedHit{Path: r.Path, Symbol: r.Symbol, StartLine: r.StartLine, EndLine: r.EndLine, Tokens: tokens}
+
+		group, known := groups[r.Path]
+		if seen[r.Content] || (known && coveredBy(r, group.hits)) {
+			omitted.Reason = "duplicate"
+			asm.Omitted = append(asm.Omitted, omitted)
+			continue
+		}
+
+		// Charge what the hit adds to the rendered section: line number prefixes,
+		// header and fence included
+		var hits []Result
+		previous := 0
+		if known {
+			hits = append(hits, group.hits...)
+			previous = EstimateTokens(group.section)
+		}
+		hits = append(hits, r)
+		section, start, end := renderSection(r.Path, r.Language, hits)
+		cost := EstimateTokens(section) - previous
+		if used+cost > maxTokens {
+			omitted.Reason = "budget"
+			asm.Omitted = append(asm.Omitted, omitted)
+			continue
+		}
+
+		if !known {
+			group = &fileGroup{path: r.Path, language: r.Language}
+			groups[r.Path] = group
+			order = append(order, group)
+		}
+		group.hits = hits
+		group.section, group.start, group.end = section, start, end
+		seen[r.Content] = true
+		used += cost
+	}
+
+	var b strings.Builder
+	for _, group := range order {
+		var symbols []string
+		for _, h := range group.hits {
+			if h.Symbol != "" {
+				symbols = append(symbols, h.Symbol)
+			}
+		}
+		b.WriteString(group.section)
+
+		asm.Files = append(asm.Files, AssembledFile{
+			Path:      group.path,
+			StartLine: group.start,
+			EndLine:   group.end,
+			Symbols:   symbols,
+			Tokens:    EstimateTokens(group.section),
+		})
+	}
+
+	asm.Context = strings.TrimRight(b.String(), "\n")
+	asm.UsedTokens = EstimateTokens(asm.Context)
+	return asm, nil
+}
+
+// renderSection formats the hits of one file as packed in the context: a header
+// with the path and line range, then the hits merged in line order in a code fence
+func renderSection(path, language string, hits []Result) (string, int, int) {
+	chunks := make([]fileChunk, len(hits))
+	for i, h := range hits {
+		chunks[i] = fileChunk{ID: h.ID, Symbol: h.Symbol, Content: h.Content, StartLine: h.StartLine, EndLine: h.EndLine}
+	}
+	sort.SliceStable(chunks, func(i, j int) bool {
+		return chunks[i].StartLine < chunks[j].StartLine
+	})
+
+	excerpt, start, end := mergeExcerpt(chunks)
+	return fileHeader(path, language, start, end) + excerpt + "\n```\n\n", start, end
+}
+
+// coveredBy reports whether the hit's lines are already inside one of the packed hits
+func coveredBy(hit Result, packed []Result) bool {
+	if hit.StartLine == 0 {
+		return false
+	}
+	for _, p := range packed {
+		if p.StartLine > 0 && p.StartLine <= hit.StartLine && hit.EndLine <= p.EndLine {
+			return true
+		}
+	}
+	return false
+}
+
+// fileHeader opens the section of a file: a heading with the path and line range,
+// then a code fence
+func fileHeader(path, language string, start, end int) string {
+	heading := "### " + path
+	if start > 0 {
+		heading += fmt.Sprintf(" (lines %d-%d)", start, end)
+	}
+	return heading + "\n```" + strings.ToLower(language) + "\n"
+}
@@ -0,0 +1,240 @@
+package search
+
+import (
+	"container/list"
+	"database/sql"
+	"strings"
+	"sync"
+)
+
+// DefaultCacheSize is the number of query embeddings kept in memory when none is configured
+const DefaultCacheSize = 256
+
+// EmbeddingCache is an LRU cache of query embeddings keyed by model and normalised
+// query, optionally backed by the query_embeddings table of the project database
+type EmbeddingCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // most recently used first
+	items    map[string]*list.Element
+
+	db        *sql.DB // nil when the cache is not persisted
+	projectID string
+
+	hits          int64
+	persistedHits int64
+	misses        int64
+	persistErr    error
+}
+
+// cacheEntry is an embedding held by the cache
+type cacheEntry struct {
+	key       string
+	embedding []float32
+}
+
+// CacheStats describes the cache usage since it was created
+type CacheStats struct {
+	Size          int
+	Capacity      int
+	Persisted     bool
+	Hits          int64 // served from memory
+	PersistedHits int64 // served from the database
+	Misses        int64 // embedded by the provider
+	PersistError  string
+}
+
+// HitRate returns the share of lookups that did not reach the embeddings provider
+func (s CacheStats) HitRate() float64 {
+	total := s.Hits + s.PersistedHits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits+s.PersistedHits) / float64(total)
+}
+
+// NewEmbeddingCache creates an in-memory cache holding up to capacity embeddings
+func NewEmbeddingCache(capacity int) *EmbeddingCache {
+	if capacity <= 0 {
+		capacity = DefaultCacheSize
+	}
+	return &EmbeddingCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Persist stores new entries in the project database and falls back to it on misses
+func (c *EmbeddingCache) Persist(db *sql.DB, projectID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.db = db
+	c.projectID = projectID
+}
+
+// Warm loads the most recently used persisted embeddings of a model into memory
+func (c *EmbeddingCache) Warm(model string) (int, error) {
+	c.mu.Lock()
+	db, projectID := c.db, c.projectID
+	c.mu.Unlock()
+	if db == nil {
+		return 0, nil
+	}
+
+	rows, err := db.Query(`
+		SELECT query, embedding::text
+		FROM query_embeddings
+		WHERE project_id = $1 AND model = $2
+		ORDER BY last_used_at DESC
+		LIMIT $3
+	`, projectID, model, c.capacity)
+	if err != nil {
+		c.disablePersistence(err)
+		return 0, err
+	}
+	defer rows.Close()
+
+	var entries []cacheEntry
+	for rows.Next() {
+		var query, text string
+		if err := rows.Scan(&query, &text); err != nil {
+			return 0, err
+		}
+		embedding, err := parseVector(text)
+		if err != nil {
+			return 0, err
+		}
+		entries = append(entries, cacheEntry{key: cacheKey(model, query), embedding: embedding})
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// Oldest first, so the most recent end up at the front
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := len(entries) - 1; i >= 0; i-- {
+		c.add(entries[i].key, entries[i].embedding)
+	}
+	return len(entries), nil
+}
+
+// Get returns the cached embedding of a query for a model
+func (c *EmbeddingCache) Get(model, query string) ([]float32, bool) {
+	query = normalizeQuery(query)
+	key := cacheKey(model, query)
+
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		c.order.MoveToFront(el)
+		c.hits++
+		embedding := el.Value.(*cacheEntry).embedding
+		c.mu.Unlock()
+		return embedding, true
+	}
+	db, projectID := c.db, c.projectID
+	c.mu.Unlock()
+
+	if db != nil {
+		var text string
+		err := db.QueryRow(`
+			UPDATE query_embeddings SET hits = hits + 1, last_used_at = CURRENT_TIMESTAMP
+			WHERE project_id = $1 AND model = $2 AND query = $3
+			RETURNING embedding::text
+		`, projectID, model, query).Scan(&text)
+		if err == nil {
+			if embedding, err := parseVector(text); err == nil {
+				c.mu.Lock()
+				c.add(key, embedding)
+				c.persistedHits++
+				c.mu.Unlock()
+				return embedding, true
+			}
+		} else if err != sql.ErrNoRows {
+			c.disablePersistence(err)
+		}
+	}
+
+	c.mu.Lock()
+	c.misses++
+	c.mu.Unlock()
+	return nil, false
+}
+
+// Put stores the embedding of a query for a model
+func (c *EmbeddingCache) Put(model, query string, embedding []float32) {
+	query = normalizeQuery(query)
+
+	c.mu.Lock()
+	c.add(cacheKey(model, query), embedding)
+	db, projectID := c.db, c.projectID
+	c.mu.Unlock()
+
+	if db != nil {
+		_, err := db.Exec(`
+			INSERT INTO query_embeddings (project_id, model, query, embedding)
+			VALUES ($1, $2, $3, $4::vector)
+			ON CONFLICT (project_id, model, query)
+			DO UPDATE SET embedding = EXCLUDED.embedding, last_used_at = CURRENT_TIMESTAMP
+		`, projectID, model, query, embeddingToString(embedding))
+		if err != nil {
+			c.disablePersistence(err)
+		}
+	}
+}
+
+// Stats returns the cache usage
+func (c *EmbeddingCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := CacheStats{
+		Size:          c.order.Len(),
+		Capacity:      c.capacity,
+		Persisted:     c.db != nil,
+		Hits:          c.hits,
+		PersistedHits: c.persistedHits,
+		Misses:        c.misses,
+	}
+	if c.persistErr != nil {
+		stats.PersistError = c.persistErr.Error()
+	}
+	return stats
+}
+
+// add inserts or refreshes an entry and evicts the least recently used one; c.mu must be held
+func (c *EmbeddingCache) add(key string, embedding []float32) {
+	if el, ok := c.items[key]; ok {
+		el.Value.(*cacheEntry).embedding = embedding
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, embedding: embedding})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// disablePersistence keeps the cache in memory only, e.g. when the table is missing
+// because 'oview up' has not been run since the upgrade
+func (c *EmbeddingCache) disablePersistence(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.db = nil
+	c.persistErr = err
+}
+
+// cacheKey identifies a normalised query embedded by a model
+func cacheKey(model, query string) string {
+	return model + "\x00" + query
+}
+
+// normalizeQuery collapses whitespace, so queries differing only in spacing share
+// an embedding. Case is kept: embeddings models tell UserService from userservice
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
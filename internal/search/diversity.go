@@ -0,0 +1,152 @@
+package search
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// mmr re-ranks results with maximal marginal relevance: each pick maximises
+// lambda*relevance - (1-lambda)*max similarity to the results already picked,
+// so near-duplicate chunks (e.g. adjacent pieces of one file) are pushed down.
+// Relevance is the ranking score normalised to [0, 1].
+func mmr(results []Result, vectors map[int][]float32, lambda float64, limit int) []Result {
+	if len(results) == 0 {
+		return results
+	}
+
+	maxScore := 0.0
+	for _, r := range results {
+		if r.Score > maxScore {
+			maxScore = r.Score
+		}
+	}
+
+	relevance := func(r Result) float64 {
+		if maxScore <= 0 {
+			return 0
+		}
+		return r.Score / maxScore
+	}
+
+	remaining := append([]Result{}, results...)
+	var selected []Result
+
+	for len(remaining) > 0 && (limit <= 0 || len(selected) < limit) {
+		best := 0
+		bestScore := math.Inf(-1)
+
+		for i, candidate := range remaining {
+			redundancy := 0.0
+			for _, s := range selected {
+				if sim := cosine(vectors[candidate.ID], vectors[s.ID]); sim > redundancy {
+					redundancy = sim
+				}
+			}
+
+			score := lambda*relevance(candidate) - (1-lambda)*redundancy
+			if score > bestScore {
+				best = i
+				bestScore = score
+			}
+		}
+
+		selected = append(selected, remaining[best])
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+
+	return selected
+}
+
+// capPerFile keeps at most max results per path, preserving order
+func capPerFile(results []Result, max int) []Result {
+	if max <= 0 {
+		return results
+	}
+
+	perFile := make(map[string]int)
+	kept := make([]Result, 0, len(results))
+	for _, r := range results {
+		if perFile[r.Path] >= max {
+			continue
+		}
+		perFile[r.Path]++
+		kept = append(kept, r)
+	}
+	return kept
+}
+
+// loadEmbeddings fetches the stored embeddings of the given chunks
+func loadEmbeddings(db *sql.DB, ids []int64) (map[int][]float32, error) {
+	rows, err := db.Query(`SELECT id, embedding::text FROM chunks WHERE id = ANY($1) AND embedding IS NOT NULL`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	vectors := make(map[int][]float32, len(ids))
+	for rows.Next() {
+		var id int
+		var text string
+		if err := rows.Scan(&id, &text); err != nil {
+			return nil, fmt.Errorf("failed to scan embedding: %w", err)
+		}
+		vector, err := parseVector(text)
+		if err != nil {
+			return nil, err
+		}
+		vectors[id] = vector
+	}
+
+	return vectors, rows.Err()
+}
+
+// parseVector parses the pgvector text format "[0.1,0.2,...]"
+func parseVector(text string) ([]float32, error) {
+	text = strings.Trim(strings.TrimSpace(text), "[]")
+	if text == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(text, ",")
+	vector := make([]float32, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector value %q: %w", p, err)
+		}
+		vector[i] = float32(v)
+	}
+	return vector, nil
+}
+
+// cosine returns the cosine similarity of two vectors, 0 if either is missing
+func cosine(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// resultIDs returns the chunk IDs of the results
+func resultIDs(results []Result) []int64 {
+	ids := make([]int64, len(results))
+	for i, r := range results {
+		ids[i] = int64(r.ID)
+	}
+	return ids
+}
@@ -0,0 +1,429 @@
+package search
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/yourusername/oview/internal/config"
+	"github.com/yourusername/oview/internal/embeddings"
+)
+
+// Result limits shared by the CLI, the MCP server and benchmarks
+const (
+	DefaultLimit = 5
+	MaxLimit     = 20
+)
+
+// Options configures a search
+type Options struct {
+	Query     string    // raw query, inline qualifiers allowed
+	Embedding []float32 // precomputed query embedding, skips the embedder
+	Mode      string    // vector, lexical or hybrid; empty uses the project default
+	Limit     int       // number of results (DefaultLimit when 0, capped at MaxLimit)
+	Threshold float64   // minimum cosine similarity of vector hits (0 disables)
+	Filters   Filters   // merged with the inline qualifiers of the query
+
+	MMR        bool     // re-rank with maximal marginal relevance
+	MMRLambda  *float64 // MMR trade-off: 1 = relevance only, 0 = diversity only; nil uses the project's
+	MaxPerFile int      // max results from the same file (0 = unlimited)
+
+	Expand       int // neighbour chunks to add on each side of a hit (0 = no expansion)
+	ExpandBudget int // token budget for all excerpts (DefaultExpandBudget when 0)
+
+	Rerank     string // re-rank provider: none, lexical, llm or openai-compatible
+	RerankTopN int    // hits re-scored by the re-ranker (DefaultRerankTopN when 0)
+
+	Explain bool // attach a score breakdown to each result and report cut candidates
+}
+
+// Response is the outcome of a search
+type Response struct {
+	Query   Query
+	Mode    string
+	Limit   int
+	Filters Filters // explicit filters merged with inline qualifiers
+	Results []Result
+
+	Reranker string   // re-ranker applied, empty when none
+	Timings  []Timing // duration of each stage, in order
+	Warnings []string // non-fatal problems, e.g. a failed re-rank
+	Cut      []Cut    // candidates left out and why, closest to the results first (explain only)
+
+	Projects []ProjectStatus // projects searched, set by cross-project searches
+
+	explain bool
+}
+
+// Timing is the duration of one search stage
+type Timing struct {
+	Stage    string // embed, retrieve, rerank, diversify, expand, fan-out
+	Duration time.Duration
+}
+
+// TimingsMS returns the stage durations in milliseconds, keyed by stage
+func (r *Response) TimingsMS() map[string]float64 {
+	timings := make(map[string]float64, len(r.Timings))
+	for _, t := range r.Timings {
+		timings[t.Stage] = float64(t.Duration.Microseconds()) / 1000
+	}
+	return timings
+}
+
+// track records the time elapsed since start for a stage
+func (r *Response) track(stage string, start time.Time) {
+	r.Timings = append(r.Timings, Timing{Stage: stage, Duration: time.Since(start)})
+}
+
+// Interpretation describes how the query was understood, for echoing back to the caller
+func (r *Response) Interpretation() map[string]interface{} {
+	interp := r.Query.Interpretation()
+	if filters := r.Filters.String(); filters != "" {
+		interp["filters"] = filters
+	}
+	return interp
+}
+
+// Engine runs searches against a project database
+type Engine struct {
+	db        *sql.DB
+	projectID string
+	config    config.SearchConfig
+	embedCfg  config.EmbeddingsConfig
+	llmCfg    config.LLMConfig
+
+	mu        sync.Mutex
+	embedder  embeddings.Generator
+	cache     *EmbeddingCache
+	rerankers map[string]Reranker
+}
+
+// Open connects to the project database and returns an engine for it
+func Open(project *config.ProjectConfig, global *config.GlobalConfig) (*Engine, error) {
+	db, err := sql.Open("postgres", global.GetProjectDSN(project))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return NewEngine(db, project), nil
+}
+
+// NewEngine creates an engine on an open database connection
+func NewEngine(db *sql.DB, project *config.ProjectConfig) *Engine {
+	return &Engine{
+		db:        db,
+		projectID: project.ProjectID,
+		config:    project.Search.WithDefaults(),
+		embedCfg:  project.Embeddings,
+		llmCfg:    project.LLM,
+	}
+}
+
+// DefaultOptions returns options with the project's search settings, for callers to override
+func (e *Engine) DefaultOptions() Options {
+	return Options{
+		Mode:       e.config.Mode,
+		Limit:      DefaultLimit,
+		Threshold:  e.config.MinSimilarity,
+		MMR:        e.config.MMR,
+		MaxPerFile: e.config.MaxPerFile,
+		Rerank:     e.config.Rerank.Provider,
+		RerankTopN: e.config.Rerank.TopN,
+	}
+}
+
+// DB returns the underlying database connection
+func (e *Engine) DB() *sql.DB {
+	return e.db
+}
+
+// ProjectID returns the project the engine searches
+func (e *Engine) ProjectID() string {
+	return e.projectID
+}
+
+// Close closes the database connection
+func (e *Engine) Close() error {
+	return e.db.Close()
+}
+
+// SetCache makes the engine look up query embeddings in cache before calling the provider
+func (e *Engine) SetCache(cache *EmbeddingCache) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cache = cache
+}
+
+// EmbeddingModel identifies the embeddings provider, model and dimension of the project
+func (e *Engine) EmbeddingModel() string {
+	return fmt.Sprintf("%s/%s/%d", e.embedCfg.Provider, e.embedCfg.Model, e.embedCfg.Dim)
+}
+
+// Embed generates an embedding with the project's embeddings provider
+func (e *Engine) Embed(text string) ([]float32, error) {
+	e.mu.Lock()
+	cache := e.cache
+	e.mu.Unlock()
+
+	if cache == nil {
+		return e.embed(text)
+	}
+	if embedding, ok := cache.Get(e.EmbeddingModel(), text); ok {
+		return embedding, nil
+	}
+	embedding, err := e.embed(text)
+	if err != nil {
+		return nil, err
+	}
+	cache.Put(e.EmbeddingModel(), text, embedding)
+	return embedding, nil
+}
+
+// embed calls the embeddings provider, creating it on first use
+func (e *Engine) embed(text string) ([]float32, error) {
+	e.mu.Lock()
+	if e.embedder == nil {
+		embedder, err := embeddings.NewGenerator(e.embedCfg)
+		if err != nil {
+			e.mu.Unlock()
+			return nil, fmt.Errorf("failed to initialize embeddings: %w", err)
+		}
+		e.embedder = embedder
+	}
+	embedder := e.embedder
+	e.mu.Unlock()
+
+	return embedder.Embed(text)
+}
+
+// reranker returns the re-ranker of a provider, created on first use
+func (e *Engine) reranker(provider string) (Reranker, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if r, ok := e.rerankers[provider]; ok {
+		return r, nil
+	}
+	r, err := NewReranker(provider, e.config.Rerank, e.llmCfg)
+	if err != nil {
+		return nil, err
+	}
+	if e.rerankers == nil {
+		e.rerankers = make(map[string]Reranker)
+	}
+	e.rerankers[provider] = r
+	return r, nil
+}
+
+// Search parses the query, embeds it when the mode needs it and ranks chunks
+func (e *Engine) Search(opts Options) (*Response, error) {
+	parsed, err := ParseQuery(opts.Query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	if parsed.EmbeddingText() == "" {
+		return nil, fmt.Errorf("query has no search text besides qualifiers")
+	}
+
+	mode := opts.Mode
+	if mode == "" {
+		mode = e.config.Mode
+	}
+	if !ValidMode(mode) {
+		return nil, fmt.Errorf("invalid search mode: %s (use vector, lexical or hybrid)", mode)
+	}
+	reranker, err := e.reranker(opts.Rerank)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize re-ranker: %w", err)
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	resp := &Response{
+		Query:   parsed,
+		Mode:    mode,
+		Limit:   limit,
+		Filters: opts.Filters.Merge(parsed.Filters),
+		explain: opts.Explain,
+	}
+
+	// Generate query embedding (not needed for lexical search)
+	queryEmbedding := opts.Embedding
+	if mode != ModeLexical && queryEmbedding == nil {
+		start := time.Now()
+		queryEmbedding, err = e.Embed(parsed.EmbeddingText())
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+		}
+		resp.track("embed", start)
+	}
+
+	// Re-rank and diversity stages need more candidates than the final limit
+	topN := 0
+	if reranker != nil {
+		topN = opts.RerankTopN
+		if topN <= 0 {
+			topN = DefaultRerankTopN
+		}
+		if topN < limit {
+			topN = limit
+		}
+	}
+	fetch := limit
+	if (opts.MMR || opts.MaxPerFile > 0) && e.config.Candidates > fetch {
+		fetch = e.config.Candidates
+	}
+	if topN > fetch {
+		fetch = topN
+	}
+
+	start := time.Now()
+
+	var results []Result
+	var vectorRanks, lexicalRanks map[int]int
+	belowThreshold := func(r Result) string {
+		return fmt.Sprintf("similarity %.3f below min_similarity %.2f", r.Similarity, opts.Threshold)
+	}
+
+	switch mode {
+	case ModeLexical:
+		results, err = lexicalSearch(e.db, e.projectID, parsed.LexicalText(), fetch, resp.Filters)
+		if err != nil {
+			return nil, err
+		}
+		lexicalRanks = ranks(results)
+
+	case ModeHybrid:
+		pool := e.config.Candidates
+		if pool < fetch {
+			pool = fetch
+		}
+
+		vector, err := vectorSearch(e.db, e.projectID, queryEmbedding, pool, resp.Filters)
+		if err != nil {
+			return nil, err
+		}
+		lexical, err := lexicalSearch(e.db, e.projectID, parsed.LexicalText(), pool, resp.Filters)
+		if err != nil {
+			return nil, err
+		}
+
+		kept := aboveThreshold(vector, opts.Threshold)
+		resp.recordCuts("retrieve", vector, kept, belowThreshold)
+		vectorRanks, lexicalRanks = ranks(kept), ranks(lexical)
+
+		fused := fuseRRF(kept, lexical, e.config.VectorWeight, e.config.LexicalWeight, e.config.RRFK, 0)
+		results = fused
+		if len(results) > fetch {
+			results = results[:fetch]
+		}
+		resp.recordCuts("fusion", fused, results, func(r Result) string {
+			return fmt.Sprintf("fused score %.4f outside the top %d candidates", r.Score, fetch)
+		})
+
+	default:
+		vector, err := vectorSearch(e.db, e.projectID, queryEmbedding, fetch, resp.Filters)
+		if err != nil {
+			return nil, err
+		}
+		results = aboveThreshold(vector, opts.Threshold)
+		resp.recordCuts("retrieve", vector, results, belowThreshold)
+		vectorRanks = ranks(results)
+	}
+	resp.track("retrieve", start)
+
+	// The re-ranker only sees the top hits; its order replaces the retrieval order
+	if reranker != nil && len(results) > 0 {
+		start := time.Now()
+		if len(results) > topN {
+			resp.recordCuts("rerank", results, results[:topN], func(r Result) string {
+				return fmt.Sprintf("outside the %d hits sent to the re-ranker", topN)
+			})
+			results = results[:topN]
+		}
+		reranked, err := rerank(reranker, parsed.EmbeddingText(), results)
+		if err != nil {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("re-rank skipped: %v", err))
+		} else {
+			results = reranked
+			resp.Reranker = reranker.Name()
+		}
+		resp.track("rerank", start)
+	}
+
+	start = time.Now()
+	capped := capPerFile(results, opts.MaxPerFile)
+	resp.recordCuts("diversify", results, capped, func(r Result) string {
+		return fmt.Sprintf("max_per_file %d reached for %s", opts.MaxPerFile, r.Path)
+	})
+	results = capped
+	if opts.MMR && len(results) > 1 {
+		vectors, err := loadEmbeddings(e.db, resultIDs(results))
+		if err != nil {
+			return nil, err
+		}
+		lambda := *e.config.MMRLambda
+		if opts.MMRLambda != nil && *opts.MMRLambda >= 0 && *opts.MMRLambda <= 1 {
+			lambda = *opts.MMRLambda
+		}
+		picked := mmr(results, vectors, lambda, limit)
+		resp.recordCuts("diversify", results, picked, func(r Result) string {
+			return mmrReason(r, picked, vectors)
+		})
+		results = picked
+	}
+	if len(results) > limit {
+		resp.recordCuts("limit", results, results[:limit], func(r Result) string {
+			return fmt.Sprintf("score %.4f, beyond the limit of %d results", r.Score, limit)
+		})
+		results = results[:limit]
+	}
+	resp.track("diversify", start)
+
+	if opts.Expand > 0 {
+		start := time.Now()
+		if err := expand(e.db, e.projectID, results, opts.Expand, opts.ExpandBudget); err != nil {
+			return nil, err
+		}
+		resp.track("expand", start)
+	}
+
+	if opts.Explain {
+		if err := e.explain(resp, results, queryEmbedding, vectorRanks, lexicalRanks); err != nil {
+			return nil, err
+		}
+	}
+
+	resp.Results = results
+	if opts.Explain {
+		resp.finishCuts()
+	}
+	return resp, nil
+}
+
+// aboveThreshold drops vector hits whose similarity is below threshold
+func aboveThreshold(results []Result, threshold float64) []Result {
+	if threshold <= 0 {
+		return results
+	}
+
+	kept := make([]Result, 0, len(results))
+	for _, r := range results {
+		if r.Similarity >= threshold {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,199 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Entity is a Doctrine entity from the entity model built at index time
+type Entity struct {
+	Name       string // short class name
+	Class      string // App\Entity\User
+	Table      string
+	Repository string
+	File       string // file holding the mapping
+	Line       int
+	Source     string // attribute, annotation, xml or yaml
+	Fields     []EntityField
+
+	ReferencedBy []EntityReference // associations of other entities targeting this one
+}
+
+// EntityField is a mapped property of an entity: a column or an association
+type EntityField struct {
+	Name       string
+	Column     string // join column for owning associations, empty for inverse sides
+	Type       string // Doctrine type, empty for associations
+	Length     int
+	Nullable   bool
+	ID         bool
+	Relation   string // ManyToOne, OneToMany, ManyToMany, OneToOne, Embedded; empty for columns
+	Target     string // target class
+	MappedBy   string
+	InversedBy string
+	Line       int
+}
+
+// EntityReference is an association of another entity targeting an entity
+type EntityReference struct {
+	Entity   string // class of the entity holding the association
+	Field    string
+	Relation string
+}
+
+// Entities loads the entity model of the project ordered by name, with the
+// associations targeting each entity
+func (e *Engine) Entities() ([]Entity, error) {
+	rows, err := e.db.Query(`
+		SELECT en.id, en.name, en.class, en.table_name, en.repository, en.file, COALESCE(en.line, 0), en.source,
+			f.entity_id IS NOT NULL, COALESCE(f.name, ''), COALESCE(f.column_name, ''), COALESCE(f.type, ''),
+			COALESCE(f.length, 0), COALESCE(f.nullable, FALSE), COALESCE(f.is_id, FALSE), COALESCE(f.relation, ''),
+			COALESCE(f.target_entity, ''), COALESCE(f.mapped_by, ''), COALESCE(f.inversed_by, ''), COALESCE(f.line, 0)
+		FROM entities en
+		LEFT JOIN entity_fields f ON f.entity_id = en.id
+		WHERE en.project_id = $1
+		ORDER BY en.name, en.class, en.id, f.position
+	`, e.projectID)
+	if err != nil {
+		return nil, fmt.Errorf("entity query failed (run 'oview up' and 'oview index' on a Symfony project to extract entities): %w", err)
+	}
+	defer rows.Close()
+
+	var entities []Entity
+	lastID := -1
+	for rows.Next() {
+		var id int
+		var en Entity
+		var f EntityField
+		var hasField bool
+		if err := rows.Scan(&id, &en.Name, &en.Class, &en.Table, &en.Repository, &en.File, &en.Line, &en.Source,
+			&hasField, &f.Name, &f.Column, &f.Type, &f.Length, &f.Nullable, &f.ID, &f.Relation,
+			&f.Target, &f.MappedBy, &f.InversedBy, &f.Line); err != nil {
+			return nil, fmt.Errorf("failed to scan entity: %w", err)
+		}
+		if id != lastID {
+			entities = append(entities, en)
+			lastID = id
+		}
+		if hasField {
+			current := &entities[len(entities)-1]
+			current.Fields = append(current.Fields, f)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(entities))
+	for i, en := range entities {
+		index[en.Class] = i
+	}
+	for _, en := range entities {
+		for _, f := range en.Fields {
+			if i, ok := index[f.Target]; ok && f.Relation != "" && f.Target != en.Class {
+				entities[i].ReferencedBy = append(entities[i].ReferencedBy, EntityReference{Entity: en.Class, Field: f.Name, Relation: f.Relation})
+			}
+		}
+	}
+	return entities, nil
+}
+
+// DescribeEntity looks up entities by class (App\Entity\User), short name (User,
+// case-insensitive) or table name, falling back to names containing the query
+func (e *Engine) DescribeEntity(name string) ([]Entity, error) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), `\`)
+	if name == "" {
+		return nil, fmt.Errorf("entity name is required")
+	}
+	entities, err := e.Entities()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Entity
+	for _, en := range entities {
+		if en.Class == name || strings.EqualFold(en.Name, name) || strings.EqualFold(en.Table, name) {
+			matches = append(matches, en)
+		}
+	}
+	if len(matches) > 0 {
+		return matches, nil
+	}
+	lower := strings.ToLower(name)
+	for _, en := range entities {
+		if strings.Contains(strings.ToLower(en.Class), lower) || strings.Contains(strings.ToLower(en.Table), lower) {
+			matches = append(matches, en)
+		}
+	}
+	return matches, nil
+}
+
+// Describe formats a field on one line: "email: string(180), nullable" or
+// "orders: OneToMany -> Order (mapped by customer)"
+func (f EntityField) Describe() string {
+	var b strings.Builder
+	b.WriteString(f.Name + ": ")
+	if f.Relation != "" {
+		b.WriteString(f.Relation + " -> " + shortName(f.Target))
+		var details []string
+		if f.Column != "" {
+			details = append(details, f.Column)
+		}
+		if f.MappedBy != "" {
+			details = append(details, "mapped by "+f.MappedBy)
+		}
+		if f.InversedBy != "" {
+			details = append(details, "inversed by "+f.InversedBy)
+		}
+		if f.Nullable {
+			details = append(details, "nullable")
+		}
+		if len(details) > 0 {
+			b.WriteString(" (" + strings.Join(details, ", ") + ")")
+		}
+		return b.String()
+	}
+
+	b.WriteString(f.Type)
+	if f.Length > 0 {
+		fmt.Fprintf(&b, "(%d)", f.Length)
+	}
+	if f.ID {
+		b.WriteString(", PK")
+	}
+	if f.Nullable {
+		b.WriteString(", nullable")
+	}
+	if f.Column != "" && f.Column != f.Name {
+		b.WriteString(" [" + f.Column + "]")
+	}
+	return b.String()
+}
+
+// SchemaMarkdown renders the entity model as a compact markdown reference: one
+// section per entity with its table, fields and associations
+func SchemaMarkdown(entities []Entity) string {
+	sorted := append([]Entity(nil), entities...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var b strings.Builder
+	for _, en := range sorted {
+		fmt.Fprintf(&b, "### %s (table `%s`)\n", en.Name, en.Table)
+		fmt.Fprintf(&b, "%s, %s:%d", en.Class, en.File, en.Line)
+		if en.Repository != "" {
+			fmt.Fprintf(&b, ", repository %s", shortName(en.Repository))
+		}
+		b.WriteString("\n")
+		for _, f := range en.Fields {
+			b.WriteString("- " + f.Describe() + "\n")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// shortName returns a class name without its namespace
+func shortName(class string) string {
+	return class[strings.LastIndex(class, `\`)+1:]
+}
@@ -0,0 +1,205 @@
+package search
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultExpandBudget is the token budget shared by all expanded excerpts of a search
+const DefaultExpandBudget = 4000
+
+// fileChunk is a chunk of the file being expanded
+type fileChunk struct {
+	ID        int
+	Symbol    string
+	Content   string
+	StartLine int
+	EndLine   int
+}
+
+// EstimateTokens approximates the token count of a text (about 4 characters per token)
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// expand attaches a contiguous excerpt to each hit: the whole enclosing symbol when
+// the hit is a piece of a split symbol ("X#2"), otherwise the hit with neighbours
+// chunks on each side. Excerpts shrink to fit the token budget; once it is spent,
+// the remaining hits are returned without excerpt.
+func expand(db *sql.DB, projectID string, results []Result, neighbours int, budget int) error {
+	if budget <= 0 {
+		budget = DefaultExpandBudget
+	}
+
+	files := make(map[string][]fileChunk)
+	used := 0
+
+	for i := range results {
+		r := &results[i]
+
+		chunks, ok := files[r.Path]
+		if !ok {
+			var err error
+			chunks, err = loadFileChunks(db, projectID, r.Path)
+			if err != nil {
+				return err
+			}
+			files[r.Path] = chunks
+		}
+
+		// Largest candidate first: enclosing symbol, neighbours, then the hit alone
+		candidates := [][]fileChunk{
+			expansionGroup(chunks, *r, neighbours, true),
+			expansionGroup(chunks, *r, neighbours, false),
+			expansionGroup(chunks, *r, 0, false),
+		}
+
+		var excerpt string
+		var start, end, cost int
+		fits := false
+		for _, group := range candidates {
+			excerpt, start, end = mergeExcerpt(group)
+			if cost = EstimateTokens(excerpt); used+cost <= budget {
+				fits = true
+				break
+			}
+		}
+		if !fits {
+			continue
+		}
+
+		used += cost
+		r.Excerpt = excerpt
+		r.ExcerptStart = start
+		r.ExcerptEnd = end
+	}
+
+	return nil
+}
+
+// loadFileChunks returns the chunks of a file in line order
+func loadFileChunks(db *sql.DB, projectID string, path string) ([]fileChunk, error) {
+	rows, err := db.Query(`
+		SELECT id, COALESCE(symbol, ''), content, COALESCE(start_line, 0), COALESCE(end_line, 0)
+		FROM chunks
+		WHERE project_id = $1 AND path = $2
+		ORDER BY start_line NULLS LAST, id
+	`, projectID, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load file chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []fileChunk
+	for rows.Next() {
+		var c fileChunk
+		if err := rows.Scan(&c.ID, &c.Symbol, &c.Content, &c.StartLine, &c.EndLine); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk: %w", err)
+		}
+		chunks = append(chunks, c)
+	}
+
+	return chunks, rows.Err()
+}
+
+// expansionGroup selects the chunks that make up the excerpt of a hit
+func expansionGroup(chunks []fileChunk, hit Result, neighbours int, wholeSymbol bool) []fileChunk {
+	pos := -1
+	for i, c := range chunks {
+		if c.ID == hit.ID {
+			pos = i
+			break
+		}
+	}
+	if pos == -1 {
+		return []fileChunk{{ID: hit.ID, Symbol: hit.Symbol, Content: hit.Content, StartLine: hit.StartLine, EndLine: hit.EndLine}}
+	}
+
+	// A piece of a split symbol: return every piece of that symbol
+	if wholeSymbol {
+		if base := splitSymbolBase(hit.Symbol); base != "" {
+			var group []fileChunk
+			for _, c := range chunks {
+				if splitSymbolBase(c.Symbol) == base {
+					group = append(group, c)
+				}
+			}
+			return group
+		}
+	}
+
+	from := pos - neighbours
+	if from < 0 {
+		from = 0
+	}
+	to := pos + neighbours + 1
+	if to > len(chunks) {
+		to = len(chunks)
+	}
+	return chunks[from:to]
+}
+
+// splitSymbolBase returns the symbol a split piece belongs to ("X" for "X#2"),
+// or "" for whole symbols and size-based pieces of unnamed content
+func splitSymbolBase(symbol string) string {
+	if i := strings.LastIndex(symbol, "#"); i > 0 && isDigits(symbol[i+1:]) {
+		return symbol[:i]
+	}
+	return ""
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeExcerpt joins chunks into one excerpt with line numbers, skipping lines
+// shown twice and marking gaps. Chunks without line information are concatenated.
+func mergeExcerpt(chunks []fileChunk) (string, int, int) {
+	if len(chunks) == 0 {
+		return "", 0, 0
+	}
+	for _, c := range chunks {
+		if c.StartLine == 0 {
+			parts := make([]string, len(chunks))
+			for i, c := range chunks {
+				parts[i] = strings.TrimSpace(c.Content)
+			}
+			return strings.Join(parts, "\n\n"), 0, 0
+		}
+	}
+
+	sorted := append([]fileChunk{}, chunks...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartLine < sorted[j].StartLine
+	})
+
+	var b strings.Builder
+	start := sorted[0].StartLine
+	last := 0
+	for _, c := range sorted {
+		if last > 0 && c.StartLine > last+1 {
+			b.WriteString("      ...\n")
+		}
+		for j, line := range strings.Split(strings.TrimSpace(c.Content), "\n") {
+			n := c.StartLine + j
+			if n <= last {
+				continue
+			}
+			fmt.Fprintf(&b, "%5d | %s\n", n, line)
+			last = n
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n"), start, last
+}
@@ -0,0 +1,68 @@
+package search
+
+import "testing"
+
+func TestMergeExcerpt(t *testing.T) {
+	tests := []struct {
+		name       string
+		chunks     []fileChunk
+		want       string
+		start, end int
+	}{
+		{
+			name:   "no chunks",
+			chunks: nil,
+		},
+		{
+			name: "overlapping chunks out of order",
+			chunks: []fileChunk{
+				{Content: "c\nd", StartLine: 12, EndLine: 13},
+				{Content: "a\nb\nc", StartLine: 10, EndLine: 12},
+			},
+			want:  "   10 | a\n   11 | b\n   12 | c\n   13 | d",
+			start: 10,
+			end:   13,
+		},
+		{
+			name: "gap between chunks",
+			chunks: []fileChunk{
+				{Content: "func a() {}", StartLine: 3, EndLine: 3},
+				{Content: "func b() {}\n", StartLine: 8, EndLine: 8},
+			},
+			want:  "    3 | func a() {}\n      ...\n    8 | func b() {}",
+			start: 3,
+			end:   8,
+		},
+		{
+			name: "chunks without lines",
+			chunks: []fileChunk{
+				{Content: " summary ", StartLine: 0},
+				{Content: "code", StartLine: 4, EndLine: 4},
+			},
+			want: "summary\n\ncode",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, start, end := mergeExcerpt(tt.chunks)
+			if got != tt.want || start != tt.start || end != tt.end {
+				t.Errorf("mergeExcerpt() = %q, %d, %d, want %q, %d, %d", got, start, end, tt.want, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestSplitSymbolBase(t *testing.T) {
+	tests := map[string]string{
+		"UserController::login#2": "UserController::login",
+		"UserController::login":   "",
+		"#3":                      "",
+		"issue#abc":               "",
+		"":                        "",
+	}
+	for symbol, want := range tests {
+		if got := splitSymbolBase(symbol); got != want {
+			t.Errorf("splitSymbolBase(%q) = %q, want %q", symbol, got, want)
+		}
+	}
+}
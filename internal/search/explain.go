@@ -0,0 +1,270 @@
+package search
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Explanation breaks down how a result was retrieved and scored
+type Explanation struct {
+	VectorRank       int      `json:"vector_rank"`               // rank in the vector ranking (0 = not retrieved by it)
+	VectorDistance   *float64 `json:"vector_distance,omitempty"` // cosine distance to the query embedding, nil when not computed
+	LexicalRank      int      `json:"lexical_rank"`              // rank in the lexical ranking (0 = not retrieved by it)
+	TextRank         float64  `json:"full_text_rank"`            // full-text rank of the content
+	SymbolSimilarity float64  `json:"symbol_similarity"`         // trigram similarity of the symbol to the query
+	ExactMatch       bool     `json:"exact_match"`               // content contains the query verbatim
+	Boosts           []Boost  `json:"boosts"`                    // contributions to the final score, in pipeline order
+	MatchedFilters   []string `json:"matched_filters,omitempty"`
+
+	Component string     `json:"component,omitempty"`
+	Source    string     `json:"source"`
+	CommitSHA string     `json:"commit_sha,omitempty"`
+	IndexedAt *time.Time `json:"indexed_at,omitempty"` // nil when unknown
+}
+
+// Boost is one contribution to the score of a result
+type Boost struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+// Cut is a candidate that was retrieved but did not make it into the results
+type Cut struct {
+	Result Result
+	Stage  string // retrieve, fusion, rerank, diversify, limit
+	Reason string
+}
+
+// recordCuts adds the results of before that are missing from after
+func (r *Response) recordCuts(stage string, before, after []Result, reason func(Result) string) {
+	if !r.explain {
+		return
+	}
+
+	kept := make(map[int]bool, len(after))
+	for _, res := range after {
+		kept[res.ID] = true
+	}
+	for _, res := range before {
+		if !kept[res.ID] {
+			r.Cut = append(r.Cut, Cut{Result: res, Stage: stage, Reason: reason(res)})
+		}
+	}
+}
+
+// cutStages lists the pipeline stages from first to last
+var cutStages = []string{"retrieve", "fusion", "rerank", "diversify", "limit"}
+
+// finishCuts keeps the first reason per candidate, drops candidates that made it
+// into the results through another ranking and puts the latest cuts first
+func (r *Response) finishCuts() {
+	final := make(map[int]bool, len(r.Results))
+	for _, res := range r.Results {
+		final[res.ID] = true
+	}
+
+	seen := make(map[int]bool)
+	cuts := r.Cut[:0]
+	for _, c := range r.Cut {
+		if final[c.Result.ID] || seen[c.Result.ID] {
+			continue
+		}
+		seen[c.Result.ID] = true
+		cuts = append(cuts, c)
+	}
+
+	stage := make(map[string]int, len(cutStages))
+	for i, s := range cutStages {
+		stage[s] = i
+	}
+	sort.SliceStable(cuts, func(i, j int) bool {
+		return stage[cuts[i].Stage] > stage[cuts[j].Stage]
+	})
+	r.Cut = cuts
+}
+
+// ranks maps chunk IDs to their 1-based rank in a result list
+func ranks(results []Result) map[int]int {
+	m := make(map[int]int, len(results))
+	for i, r := range results {
+		m[r.ID] = i + 1
+	}
+	return m
+}
+
+// mmrReason explains why MMR left a candidate out: the kept result closest to it
+func mmrReason(candidate Result, kept []Result, vectors map[int][]float32) string {
+	best, bestSim := -1, 0.0
+	for i, k := range kept {
+		if sim := cosine(vectors[candidate.ID], vectors[k.ID]); sim > bestSim {
+			best, bestSim = i, sim
+		}
+	}
+	if best == -1 {
+		return "not picked by MMR (no embedding to compare)"
+	}
+	return fmt.Sprintf("not picked by MMR: %.2f similar to result #%d (%s)", bestSim, best+1, label(kept[best]))
+}
+
+// label names a result as path:symbol or path:line
+func label(r Result) string {
+	switch {
+	case r.Symbol != "":
+		return r.Path + ":" + r.Symbol
+	case r.StartLine > 0:
+		return fmt.Sprintf("%s:%d", r.Path, r.StartLine)
+	default:
+		return r.Path
+	}
+}
+
+// explain attaches an Explanation to each result: ranks and lexical components,
+// score contributions, matched filters and index metadata
+func (e *Engine) explain(resp *Response, results []Result, queryEmbedding []float32, vectorRanks, lexicalRanks map[int]int) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	distance := "NULL::float8"
+	lexicalText := resp.Query.LexicalText()
+	args := []interface{}{pq.Array(resultIDs(results)), lexicalText, "%" + escapeLike(lexicalText) + "%"}
+	if queryEmbedding != nil {
+		distance = "embedding <=> $4::vector"
+		args = append(args, embeddingToString(queryEmbedding))
+	}
+
+	rows, err := e.db.Query(`
+		SELECT
+			id, COALESCE(component, ''), source, COALESCE(commit_sha, ''), COALESCE(updated_at, created_at),
+			ts_rank_cd(content_tsv, q), similarity(COALESCE(symbol, ''), $2), content ILIKE $3 ESCAPE '\',
+			`+distance+`
+		FROM chunks, websearch_to_tsquery('simple', $2) q
+		WHERE id = ANY($1)
+	`, args...)
+	if err != nil {
+		return fmt.Errorf("explain query failed (run 'oview up' to add the full-text indexes): %w", err)
+	}
+	defer rows.Close()
+
+	details := make(map[int]*Explanation, len(results))
+	for rows.Next() {
+		var id int
+		var x Explanation
+		var indexedAt sql.NullTime
+		var dist sql.NullFloat64
+		if err := rows.Scan(&id, &x.Component, &x.Source, &x.CommitSHA, &indexedAt,
+			&x.TextRank, &x.SymbolSimilarity, &x.ExactMatch, &dist); err != nil {
+			return fmt.Errorf("failed to scan explanation: %w", err)
+		}
+		if indexedAt.Valid && !indexedAt.Time.IsZero() {
+			x.IndexedAt = &indexedAt.Time
+		}
+		if dist.Valid {
+			x.VectorDistance = &dist.Float64
+		}
+		details[id] = &x
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range results {
+		r := &results[i]
+		x, ok := details[r.ID]
+		if !ok {
+			continue
+		}
+		x.VectorRank = vectorRanks[r.ID]
+		x.LexicalRank = lexicalRanks[r.ID]
+		x.Boosts = e.boosts(resp, *r, x)
+		x.MatchedFilters = resp.Filters.matched(*r, x.Component, x.Source)
+		r.Explain = x
+	}
+	return nil
+}
+
+// boosts lists what contributed to the score of a result in the response's mode.
+// The hybrid score is the RRF terms alone: the lexical components that ranked
+// the result are diagnostics, reported in the Explanation fields
+func (e *Engine) boosts(resp *Response, r Result, x *Explanation) []Boost {
+	var boosts []Boost
+	lexical := func() {
+		boosts = append(boosts, Boost{Name: "full-text rank", Value: x.TextRank})
+		if x.SymbolSimilarity > 0 {
+			boosts = append(boosts, Boost{Name: "symbol similarity", Value: x.SymbolSimilarity})
+		}
+		if x.ExactMatch {
+			boosts = append(boosts, Boost{Name: "exact match", Value: 1})
+		}
+	}
+
+	switch resp.Mode {
+	case ModeLexical:
+		lexical()
+	case ModeHybrid:
+		if x.VectorRank > 0 {
+			boosts = append(boosts, Boost{
+				Name:  fmt.Sprintf("rrf vector rank %d (weight %.2f)", x.VectorRank, e.config.VectorWeight),
+				Value: e.config.VectorWeight / float64(e.config.RRFK+x.VectorRank),
+			})
+		}
+		if x.LexicalRank > 0 {
+			boosts = append(boosts, Boost{
+				Name:  fmt.Sprintf("rrf lexical rank %d (weight %.2f)", x.LexicalRank, e.config.LexicalWeight),
+				Value: e.config.LexicalWeight / float64(e.config.RRFK+x.LexicalRank),
+			})
+		}
+	default:
+		boosts = append(boosts, Boost{Name: "cosine similarity", Value: r.Similarity})
+	}
+
+	if resp.Reranker != "" {
+		boosts = append(boosts, Boost{Name: "re-rank (" + resp.Reranker + ")", Value: r.RerankScore})
+	}
+	return boosts
+}
+
+// matched lists the active filters a result satisfies, e.g. "type=code", "path=src/"
+func (f Filters) matched(r Result, component, source string) []string {
+	var matched []string
+	in := func(name, value string, values []string, fold bool) {
+		for _, v := range values {
+			if v == value || (fold && strings.EqualFold(v, value)) {
+				matched = append(matched, name+"="+v)
+				return
+			}
+		}
+	}
+
+	in("type", r.Type, f.Types, false)
+	in("lang", r.Language, f.Languages, true)
+	in("component", component, f.Components, false)
+	in("source", source, f.Sources, false)
+	for _, p := range f.Paths {
+		if pathMatches(r.Path, p) {
+			matched = append(matched, "path="+p)
+			break
+		}
+	}
+	for _, p := range f.Exclude {
+		matched = append(matched, "not path="+p)
+	}
+	for _, t := range f.ExcludeTypes {
+		matched = append(matched, "not type="+t)
+	}
+	for _, l := range f.ExcludeLanguages {
+		matched = append(matched, "not lang="+l)
+	}
+	for _, s := range f.Symbols {
+		matched = append(matched, "symbol~"+s)
+	}
+	for _, p := range f.Phrases {
+		matched = append(matched, fmt.Sprintf("phrase %q", p))
+	}
+	return matched
+}
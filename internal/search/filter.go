@@ -0,0 +1,201 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Filters restricts a search to a subset of chunks. Empty fields match everything.
+type Filters struct {
+	Types      []string // code, test, config, doc
+	Languages  []string // PHP, TypeScript... (case-insensitive)
+	Paths      []string // path prefixes or globs (*, ** and ?)
+	Components []string
+	Sources    []string // repo, docs, external
+	Exclude    []string // path prefixes or globs to leave out
+
+	ExcludeTypes     []string
+	ExcludeLanguages []string
+	Symbols          []string // symbol substrings (case-insensitive)
+	Phrases          []string // exact phrases the content must contain (case-insensitive)
+}
+
+// IsEmpty reports whether no filter is set
+func (f Filters) IsEmpty() bool {
+	return len(f.Types) == 0 && len(f.Languages) == 0 && len(f.Paths) == 0 &&
+		len(f.Components) == 0 && len(f.Sources) == 0 && len(f.Exclude) == 0 &&
+		len(f.ExcludeTypes) == 0 && len(f.ExcludeLanguages) == 0 &&
+		len(f.Symbols) == 0 && len(f.Phrases) == 0
+}
+
+// Merge returns the union of both filter sets
+func (f Filters) Merge(other Filters) Filters {
+	return Filters{
+		Types:            append(append([]string{}, f.Types...), other.Types...),
+		Languages:        append(append([]string{}, f.Languages...), other.Languages...),
+		Paths:            append(append([]string{}, f.Paths...), other.Paths...),
+		Components:       append(append([]string{}, f.Components...), other.Components...),
+		Sources:          append(append([]string{}, f.Sources...), other.Sources...),
+		Exclude:          append(append([]string{}, f.Exclude...), other.Exclude...),
+		ExcludeTypes:     append(append([]string{}, f.ExcludeTypes...), other.ExcludeTypes...),
+		ExcludeLanguages: append(append([]string{}, f.ExcludeLanguages...), other.ExcludeLanguages...),
+		Symbols:          append(append([]string{}, f.Symbols...), other.Symbols...),
+		Phrases:          append(append([]string{}, f.Phrases...), other.Phrases...),
+	}
+}
+
+// String describes the active filters, e.g. "type=test lang=php path=src/"
+func (f Filters) String() string {
+	var parts []string
+	add := func(name string, values []string) {
+		if len(values) > 0 {
+			parts = append(parts, name+"="+strings.Join(values, ","))
+		}
+	}
+	add("type", f.Types)
+	add("lang", f.Languages)
+	add("path", f.Paths)
+	add("component", f.Components)
+	add("source", f.Sources)
+	add("exclude", f.Exclude)
+	add("type!", f.ExcludeTypes)
+	add("lang!", f.ExcludeLanguages)
+	add("symbol", f.Symbols)
+	return strings.Join(parts, " ")
+}
+
+// clause builds the SQL conditions for the filters, prefixed with AND.
+// Placeholders are numbered from next, so the caller can append args after its own.
+func (f Filters) clause(next int) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", next+len(args)-1)
+	}
+
+	if len(f.Types) > 0 {
+		conds = append(conds, "type = ANY("+arg(pq.Array(f.Types))+")")
+	}
+	if len(f.ExcludeTypes) > 0 {
+		conds = append(conds, "NOT type = ANY("+arg(pq.Array(f.ExcludeTypes))+")")
+	}
+	if len(f.Languages) > 0 {
+		conds = append(conds, "LOWER(language) = ANY("+arg(pq.Array(lowerAll(f.Languages)))+")")
+	}
+	if len(f.ExcludeLanguages) > 0 {
+		conds = append(conds, "(language IS NULL OR NOT LOWER(language) = ANY("+arg(pq.Array(lowerAll(f.ExcludeLanguages)))+"))")
+	}
+	if len(f.Components) > 0 {
+		conds = append(conds, "component = ANY("+arg(pq.Array(f.Components))+")")
+	}
+	if len(f.Sources) > 0 {
+		conds = append(conds, "source = ANY("+arg(pq.Array(f.Sources))+")")
+	}
+
+	if len(f.Paths) > 0 {
+		var alts []string
+		for _, p := range f.Paths {
+			alts = append(alts, pathCondition(p, arg))
+		}
+		conds = append(conds, "("+strings.Join(alts, " OR ")+")")
+	}
+	for _, p := range f.Exclude {
+		conds = append(conds, "NOT "+pathCondition(p, arg))
+	}
+
+	if len(f.Symbols) > 0 {
+		var alts []string
+		for _, s := range f.Symbols {
+			alts = append(alts, "symbol ILIKE "+arg("%"+escapeLike(s)+"%")+` ESCAPE '\'`)
+		}
+		conds = append(conds, "("+strings.Join(alts, " OR ")+")")
+	}
+	for _, p := range f.Phrases {
+		conds = append(conds, "content ILIKE "+arg("%"+escapeLike(p)+"%")+` ESCAPE '\'`)
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " AND " + strings.Join(conds, " AND "), args
+}
+
+// pathCondition matches a path prefix on segment boundaries, or a glob with an
+// anchored regex: src/Controller matches src/Controller and src/Controller/...,
+// not src/ControllerX
+func pathCondition(pattern string, arg func(interface{}) string) string {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if strings.ContainsAny(pattern, "*?") {
+		return "path ~ " + arg(globToRegex(pattern))
+	}
+	if strings.HasSuffix(pattern, "/") {
+		return "path LIKE " + arg(escapeLike(pattern)+"%") + ` ESCAPE '\'`
+	}
+	return "(path = " + arg(pattern) + " OR path LIKE " + arg(escapeLike(pattern)+"/%") + ` ESCAPE '\')`
+}
+
+// pathMatches reports whether path satisfies a prefix or glob filter, as pathCondition does in SQL
+func pathMatches(path, pattern string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if !strings.ContainsAny(pattern, "*?") {
+		if strings.HasSuffix(pattern, "/") {
+			return strings.HasPrefix(path, pattern)
+		}
+		return path == pattern || strings.HasPrefix(path, pattern+"/")
+	}
+	re, err := regexp.Compile(globToRegex(pattern))
+	return err == nil && re.MatchString(path)
+}
+
+// globToRegex converts a path glob to a POSIX regex: ** crosses directories,
+// * and ? stay within one path segment
+func globToRegex(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch ch := glob[i]; ch {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				// "**/" also matches zero directories
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					b.WriteString("(.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// lowerAll lowercases every value
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, v := range values {
+		lowered[i] = strings.ToLower(v)
+	}
+	return lowered
+}
+
+// quoteAll wraps every value in double quotes
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = `"` + v + `"`
+	}
+	return quoted
+}
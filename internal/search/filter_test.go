@@ -0,0 +1,86 @@
+package search
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestGlobToRegex(t *testing.T) {
+	tests := []struct {
+		glob    string
+		match   []string
+		noMatch []string
+	}{
+		{"src/**/*.php", []string{"src/Kernel.php", "src/Controller/Admin/UserController.php"}, []string{"lib/src/Kernel.php", "src/Kernel.php.bak"}},
+		{"tests/*Test.php", []string{"tests/UserTest.php"}, []string{"tests/Unit/UserTest.php", "tests/UserTest.phps"}},
+		{"**/*.twig", []string{"base.twig", "templates/user/show.twig"}, []string{"templates/user/show.twig.bak"}},
+		{"config/?.yaml", []string{"config/a.yaml"}, []string{"config/ab.yaml", "config/a/b.yaml"}},
+		{"docs/v1.0/**", []string{"docs/v1.0/index.md"}, []string{"docs/v1x0/index.md"}},
+	}
+	for _, tt := range tests {
+		re := regexp.MustCompile(globToRegex(tt.glob))
+		for _, path := range tt.match {
+			if !re.MatchString(path) {
+				t.Errorf("%s does not match %s (regex %s)", tt.glob, path, re)
+			}
+		}
+		for _, path := range tt.noMatch {
+			if re.MatchString(path) {
+				t.Errorf("%s matches %s (regex %s)", tt.glob, path, re)
+			}
+		}
+	}
+}
+
+func TestFiltersClause(t *testing.T) {
+	if clause, args := (Filters{}).clause(1); clause != "" || args != nil {
+		t.Errorf("empty filters clause = %q %v, want nothing", clause, args)
+	}
+
+	f := Filters{
+		Types:            []string{"test"},
+		Languages:        []string{"PHP"},
+		ExcludeLanguages: []string{"Twig"},
+		Paths:            []string{"src/", "./tests/**/*Test.php"},
+		Exclude:          []string{"src/my_legacy"},
+	}
+	clause, args := f.clause(3)
+
+	wantClause := ` AND type = ANY($3) AND LOWER(language) = ANY($4) AND (language IS NULL OR NOT LOWER(language) = ANY($5))` +
+		` AND (path LIKE $6 ESCAPE '\' OR path ~ $7) AND NOT (path = $8 OR path LIKE $9 ESCAPE '\')`
+	if clause != wantClause {
+		t.Errorf("clause =\n%s\nwant\n%s", clause, wantClause)
+	}
+	wantArgs := []interface{}{
+		pq.Array([]string{"test"}), pq.Array([]string{"php"}), pq.Array([]string{"twig"}),
+		"src/%", `^tests/(.*/)?[^/]*Test\.php$`, "src/my_legacy", `src/my\_legacy/%`,
+	}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestPathMatches(t *testing.T) {
+	tests := []struct {
+		path    string
+		pattern string
+		want    bool
+	}{
+		{"src/Controller/UserController.php", "src/Controller", true},
+		{"src/Controller", "src/Controller", true},
+		{"src/ControllerX/A.php", "src/Controller", false},
+		{"src/ControllerX/A.php", "src/Contr", false},
+		{"src/ControllerX/A.php", "src/Controller/", false},
+		{"src/Controller/A.php", "./src/Controller/", true},
+		{"src/Controller/A.php", "src/**/*.php", true},
+		{"src/Controller/A.php", "src/*.php", false},
+	}
+	for _, tt := range tests {
+		if got := pathMatches(tt.path, tt.pattern); got != tt.want {
+			t.Errorf("pathMatches(%q, %q) = %v, want %v", tt.path, tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Directions of a related file, seen from the file asked about
+const (
+	DependsOn = "depends-on" // the file uses it (use, import, extends, render...)
+	UsedBy    = "used-by"    // it uses the file
+)
+
+// Depth bounds of a related files lookup
+const (
+	DefaultRelatedDepth = 1
+	MaxRelatedDepth     = 3
+)
+
+// RelatedFile is a file reached from another through the dependency graph
+type RelatedFile struct {
+	Path      string
+	Direction string // DependsOn or UsedBy
+	Depth     int    // 1 for direct collaborators
+	Kind      string // edge kind: use, import, require, extends, include, render, service
+	Via       string // file it was reached from (the file asked about at depth 1)
+	Line      int    // line of the statement in the using file (0 if unknown)
+}
+
+// ExternalDependency is a direct dependency outside the project (vendor class, npm package)
+type ExternalDependency struct {
+	Target string
+	Kind   string
+	Line   int
+}
+
+// Dependencies are the collaborators of a file
+type Dependencies struct {
+	Path     string
+	Related  []RelatedFile
+	External []ExternalDependency
+}
+
+// graphEdge is a row of the edges table
+type graphEdge struct {
+	from, to, target, kind string
+	line                   int
+}
+
+// RelatedFiles walks the dependency graph built at index time from a file, up to depth
+// hops in each direction: the files it depends on and the files that depend on it
+func (e *Engine) RelatedFiles(path string, depth int) (*Dependencies, error) {
+	path = strings.TrimPrefix(path, "./")
+	if path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	if depth <= 0 {
+		depth = DefaultRelatedDepth
+	}
+	if depth > MaxRelatedDepth {
+		depth = MaxRelatedDepth
+	}
+
+	deps := &Dependencies{Path: path}
+	for _, direction := range []string{DependsOn, UsedBy} {
+		visited := map[string]bool{path: true}
+		frontier := []string{path}
+		for level := 1; level <= depth && len(frontier) > 0; level++ {
+			edges, err := e.edges(direction, frontier)
+			if err != nil {
+				return nil, err
+			}
+
+			var next []string
+			for _, edge := range edges {
+				file, via := edge.to, edge.from
+				if direction == UsedBy {
+					file, via = edge.from, edge.to
+				}
+				if file == "" {
+					if level == 1 {
+						deps.External = append(deps.External, ExternalDependency{Target: edge.target, Kind: edge.kind, Line: edge.line})
+					}
+					continue
+				}
+				if visited[file] {
+					continue
+				}
+				visited[file] = true
+				next = append(next, file)
+				deps.Related = append(deps.Related, RelatedFile{
+					Path: file, Direction: direction, Depth: level, Kind: edge.kind, Via: via, Line: edge.line,
+				})
+			}
+			frontier = next
+		}
+	}
+
+	sort.SliceStable(deps.Related, func(i, j int) bool {
+		a, b := deps.Related[i], deps.Related[j]
+		if a.Direction != b.Direction {
+			return a.Direction == DependsOn
+		}
+		if a.Depth != b.Depth {
+			return a.Depth < b.Depth
+		}
+		return a.Path < b.Path
+	})
+	return deps, nil
+}
+
+// edges loads the edges leaving (DependsOn) or reaching (UsedBy) a set of files
+func (e *Engine) edges(direction string, paths []string) ([]graphEdge, error) {
+	column := "from_path"
+	if direction == UsedBy {
+		column = "to_path"
+	}
+
+	rows, err := e.db.Query(`
+		SELECT from_path, COALESCE(to_path, ''), target, kind, COALESCE(line, 0)
+		FROM edges
+		WHERE project_id = $1 AND `+column+` = ANY($2)
+		ORDER BY from_path, line
+	`, e.projectID, pq.Array(paths))
+	if err != nil {
+		return nil, fmt.Errorf("dependency query failed (run 'oview up' and 'oview index' to build the dependency graph): %w", err)
+	}
+	defer rows.Close()
+
+	var edges []graphEdge
+	for rows.Next() {
+		var edge graphEdge
+		if err := rows.Scan(&edge.from, &edge.to, &edge.target, &edge.kind, &edge.line); err != nil {
+			return nil, fmt.Errorf("failed to scan edge: %w", err)
+		}
+		edges = append(edges, edge)
+	}
+	return edges, rows.Err()
+}
@@ -0,0 +1,253 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Policies for projects whose embeddings model differs from the reference project
+const (
+	IncompatibleEmbed   = "embed"   // embed the query again with the project's own model
+	IncompatibleLexical = "lexical" // search the project lexically only
+	IncompatibleSkip    = "skip"    // leave the project out
+)
+
+// Target is a project taking part in a cross-project search
+type Target struct {
+	Name   string  // project slug, used as the result label
+	Root   string  // project directory
+	Engine *Engine // nil when the project could not be opened
+	Err    error   // why the project could not be opened
+}
+
+// ProjectStatus reports how a project took part in a cross-project search
+type ProjectStatus struct {
+	Name    string
+	Root    string
+	Model   string // embeddings provider/model/dimension
+	Mode    string // mode used for this project
+	Results int    // results contributed to the merged list
+	Skipped string // reason the project was left out, empty when searched
+}
+
+// ValidIncompatible reports whether policy is a supported incompatible-model policy
+func ValidIncompatible(policy string) bool {
+	return policy == IncompatibleEmbed || policy == IncompatibleLexical || policy == IncompatibleSkip
+}
+
+// SearchProjects runs the same search on several projects and merges the results.
+// The first target is the reference: projects using another embeddings model are
+// handled according to incompatible. Results are merged on a scale shared by
+// every project (see mergeScores); ties are broken by cosine similarity.
+func SearchProjects(targets []Target, opts Options, incompatible string) (*Response, error) {
+	if incompatible == "" {
+		incompatible = IncompatibleEmbed
+	}
+	if !ValidIncompatible(incompatible) {
+		return nil, fmt.Errorf("invalid incompatible model policy: %s (use embed, lexical or skip)", incompatible)
+	}
+
+	parsed, err := ParseQuery(opts.Query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	start := time.Now()
+	statuses := make([]ProjectStatus, len(targets))
+	reference := ""
+	for i, t := range targets {
+		statuses[i] = ProjectStatus{Name: t.Name, Root: t.Root, Mode: opts.Mode}
+		if t.Engine == nil {
+			statuses[i].Skipped = fmt.Sprintf("not available: %v", t.Err)
+			continue
+		}
+		statuses[i].Model = t.Engine.EmbeddingModel()
+		if statuses[i].Mode == "" {
+			statuses[i].Mode = t.Engine.config.Mode
+		}
+		if reference == "" {
+			reference = statuses[i].Model
+		}
+		if statuses[i].Model != reference && statuses[i].Mode != ModeLexical {
+			switch incompatible {
+			case IncompatibleSkip:
+				statuses[i].Skipped = fmt.Sprintf("embeddings model %s differs from %s", statuses[i].Model, reference)
+			case IncompatibleLexical:
+				statuses[i].Mode = ModeLexical
+			}
+		}
+	}
+
+	// Embed the query once per model
+	embeddings := make(map[string][]float32)
+	for i, t := range targets {
+		status := &statuses[i]
+		if status.Skipped != "" || status.Mode == ModeLexical {
+			continue
+		}
+		if _, ok := embeddings[status.Model]; ok {
+			continue
+		}
+		embedding, err := t.Engine.Embed(parsed.EmbeddingText())
+		if err != nil {
+			status.Skipped = fmt.Sprintf("failed to embed the query with %s: %v", status.Model, err)
+			continue
+		}
+		embeddings[status.Model] = embedding
+	}
+
+	// Fan out
+	responses := make([]*Response, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		status := &statuses[i]
+		if status.Skipped != "" {
+			continue
+		}
+		embedding, ok := embeddings[status.Model]
+		if !ok && status.Mode != ModeLexical {
+			continue // embedding failed, reason already recorded
+		}
+
+		wg.Add(1)
+		go func(i int, engine *Engine, mode string, embedding []float32) {
+			defer wg.Done()
+			projectOpts := opts
+			projectOpts.Mode = mode
+			projectOpts.Embedding = embedding
+			resp, err := engine.Search(projectOpts)
+			if err != nil {
+				statuses[i].Skipped = fmt.Sprintf("search failed: %v", err)
+				return
+			}
+			responses[i] = resp
+		}(i, t.Engine, status.Mode, embedding)
+	}
+	wg.Wait()
+
+	merged := &Response{Query: parsed, Mode: opts.Mode}
+	var lists [][]Result
+	var modes, models []string
+	for i, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		if merged.Mode == "" {
+			merged.Mode = resp.Mode
+		}
+		merged.Limit = resp.Limit
+		merged.Filters = resp.Filters
+		for _, w := range resp.Warnings {
+			merged.Warnings = append(merged.Warnings, targets[i].Name+": "+w)
+		}
+
+		list := make([]Result, len(resp.Results))
+		for j, r := range resp.Results {
+			r.Project = targets[i].Name
+			list[j] = r
+		}
+		lists = append(lists, list)
+		modes = append(modes, resp.Mode)
+		models = append(models, statuses[i].Model)
+	}
+	for _, t := range targets {
+		if t.Engine != nil {
+			cfg := t.Engine.config // the reference project's fusion settings
+			merged.Results = mergeScores(lists, modes, models, cfg.VectorWeight, cfg.LexicalWeight, cfg.RRFK)
+			break
+		}
+	}
+
+	sort.SliceStable(merged.Results, func(i, j int) bool {
+		a, b := merged.Results[i], merged.Results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Similarity > b.Similarity
+	})
+	if merged.Limit > 0 && len(merged.Results) > merged.Limit {
+		merged.Results = merged.Results[:merged.Limit]
+	}
+
+	for _, r := range merged.Results {
+		for i := range statuses {
+			if statuses[i].Name == r.Project {
+				statuses[i].Results++
+			}
+		}
+	}
+	merged.Projects = statuses
+	merged.track("fan-out", start)
+	return merged, nil
+}
+
+// mergeScores sets the scores of the results of several projects on a shared
+// scale and returns them as one list. Per-project scores can't be compared
+// directly: hybrid scores are rank-based, and dividing by each project's best
+// score would put every project's top hit level whatever its relevance.
+//   - vector, one model: the raw cosine similarity
+//   - lexical: the raw lexical score (same formula in every project)
+//   - hybrid, one model: cosine similarities and lexical scores are ranked over
+//     the union and fused again with RRF
+//   - mixed modes or models: nothing is comparable, so each project's ranking
+//     is fused with RRF
+func mergeScores(lists [][]Result, modes, models []string, vectorWeight, lexicalWeight float64, k int) []Result {
+	var all []Result
+	for _, list := range lists {
+		all = append(all, list...)
+	}
+
+	mode, model := "", ""
+	for i := range lists {
+		if i == 0 {
+			mode, model = modes[i], models[i]
+		}
+		if modes[i] != mode || (mode != ModeLexical && models[i] != model) {
+			mode = ""
+			break
+		}
+	}
+
+	switch mode {
+	case ModeVector:
+		for i := range all {
+			all[i].Score = all[i].Similarity
+		}
+	case ModeLexical:
+		for i := range all {
+			all[i].Score = all[i].LexicalScore
+		}
+	case ModeHybrid:
+		for i := range all {
+			all[i].Score = 0
+		}
+		unionRRF(all, vectorWeight, k, func(r Result) float64 { return r.Similarity })
+		unionRRF(all, lexicalWeight, k, func(r Result) float64 { return r.LexicalScore })
+	default:
+		all = all[:0]
+		for _, list := range lists {
+			for rank, r := range list {
+				r.Score = 1 / float64(k+rank+1)
+				all = append(all, r)
+			}
+		}
+	}
+	return all
+}
+
+// unionRRF adds weight / (k + rank) to the score of each result, ranked over
+// all projects by value; results without a value are not ranked
+func unionRRF(results []Result, weight float64, k int, value func(Result) float64) {
+	order := make([]int, 0, len(results))
+	for i, r := range results {
+		if value(r) > 0 {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool { return value(results[order[a]]) > value(results[order[b]]) })
+	for rank, i := range order {
+		results[i].Score += weight / float64(k+rank+1)
+	}
+}
@@ -0,0 +1,255 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Query is a search query parsed from the inline syntax, e.g.
+//
+//	lang:php path:src/Controller type:-test symbol:login "password reset"
+//
+// Qualifiers become filters, quoted phrases must appear in the content and
+// the remaining words are the free text to embed.
+type Query struct {
+	Raw     string
+	Text    string   // free text, without qualifiers or quotes
+	Phrases []string // quoted phrases
+	Filters Filters
+}
+
+// queryQualifiers maps each qualifier to the filters it fills.
+// A leading "-" on the value excludes instead of includes, where supported.
+var queryQualifiers = map[string]func(f *Filters, value string, negate bool) error{
+	"type": func(f *Filters, v string, negate bool) error {
+		if negate {
+			f.ExcludeTypes = append(f.ExcludeTypes, v)
+		} else {
+			f.Types = append(f.Types, v)
+		}
+		return nil
+	},
+	"lang": func(f *Filters, v string, negate bool) error {
+		if negate {
+			f.ExcludeLanguages = append(f.ExcludeLanguages, v)
+		} else {
+			f.Languages = append(f.Languages, v)
+		}
+		return nil
+	},
+	"path": func(f *Filters, v string, negate bool) error {
+		if negate {
+			f.Exclude = append(f.Exclude, v)
+		} else {
+			f.Paths = append(f.Paths, v)
+		}
+		return nil
+	},
+	"component": func(f *Filters, v string, negate bool) error {
+		if negate {
+			return fmt.Errorf("component: does not support exclusion")
+		}
+		f.Components = append(f.Components, v)
+		return nil
+	},
+	"source": func(f *Filters, v string, negate bool) error {
+		if negate {
+			return fmt.Errorf("source: does not support exclusion")
+		}
+		f.Sources = append(f.Sources, v)
+		return nil
+	},
+	"symbol": func(f *Filters, v string, negate bool) error {
+		if negate {
+			return fmt.Errorf("symbol: does not support exclusion")
+		}
+		f.Symbols = append(f.Symbols, v)
+		return nil
+	},
+}
+
+// queryAliases maps alternative qualifier names to their canonical name
+var queryAliases = map[string]string{
+	"language": "lang",
+	"file":     "path",
+	"in":       "path",
+	"exclude":  "-path",
+	"sym":      "symbol",
+}
+
+// ParseQuery splits a raw query into qualifiers, quoted phrases and free text.
+// Unknown qualifiers (e.g. "App::login" or "http://...") are kept as free text.
+func ParseQuery(raw string) (Query, error) {
+	q := Query{Raw: raw}
+	var words []string
+
+	for _, tok := range tokenizeQuery(raw) {
+		if tok.quoted {
+			if tok.value != "" {
+				q.Phrases = append(q.Phrases, tok.value)
+			}
+			continue
+		}
+
+		key, value, ok := splitQualifier(tok)
+		if !ok {
+			words = append(words, tok.value)
+			continue
+		}
+
+		negate := false
+		if alias, ok := queryAliases[key]; ok {
+			key = alias
+			if strings.HasPrefix(key, "-") {
+				key = key[1:]
+				negate = true
+			}
+		}
+		apply, known := queryQualifiers[key]
+		if !known {
+			words = append(words, tok.value)
+			continue
+		}
+
+		if strings.HasPrefix(value, "-") {
+			value = value[1:]
+			negate = !negate
+		}
+		if value == "" {
+			return q, fmt.Errorf("empty value for %s: qualifier", key)
+		}
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v == "" {
+				continue
+			}
+			if err := apply(&q.Filters, v, negate); err != nil {
+				return q, err
+			}
+		}
+	}
+
+	q.Text = strings.Join(words, " ")
+	q.Filters.Phrases = q.Phrases
+	return q, nil
+}
+
+// EmbeddingText returns the text to embed: free text and phrases, or the
+// symbol filters when the query only has qualifiers
+func (q Query) EmbeddingText() string {
+	parts := []string{}
+	if q.Text != "" {
+		parts = append(parts, q.Text)
+	}
+	parts = append(parts, q.Phrases...)
+	if len(parts) == 0 {
+		parts = append(parts, q.Filters.Symbols...)
+	}
+	return strings.Join(parts, " ")
+}
+
+// LexicalText returns the text for full-text matching, with phrases kept quoted
+// so websearch_to_tsquery matches them as phrases
+func (q Query) LexicalText() string {
+	parts := []string{}
+	if q.Text != "" {
+		parts = append(parts, q.Text)
+	}
+	parts = append(parts, quoteAll(q.Phrases)...)
+	if len(parts) == 0 {
+		parts = append(parts, q.Filters.Symbols...)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Interpretation describes how the query was understood, for echoing back to the caller
+func (q Query) Interpretation() map[string]interface{} {
+	interp := map[string]interface{}{
+		"text": q.Text,
+	}
+	if len(q.Phrases) > 0 {
+		interp["phrases"] = q.Phrases
+	}
+	if filters := q.Filters.String(); filters != "" {
+		interp["filters"] = filters
+	}
+	return interp
+}
+
+// queryToken is a whitespace-separated token; quoted is set for "..." phrases
+type queryToken struct {
+	value  string
+	quoted bool
+	// quotedValue is set for qualifiers with a quoted value, e.g. path:"My Dir"
+	quotedValue bool
+}
+
+// tokenizeQuery splits on whitespace, keeping quoted phrases and quoted qualifier values together
+func tokenizeQuery(raw string) []queryToken {
+	var tokens []queryToken
+	runes := []rune(raw)
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		// "quoted phrase"
+		if runes[i] == '"' {
+			end := indexRune(runes, '"', i+1)
+			tokens = append(tokens, queryToken{value: strings.TrimSpace(string(runes[i+1 : end])), quoted: true})
+			i = end + 1
+			continue
+		}
+
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) {
+			// key:"quoted value"
+			if runes[i] == '"' && i > start && runes[i-1] == ':' {
+				end := indexRune(runes, '"', i+1)
+				value := string(runes[start:i]) + string(runes[i+1:end])
+				tokens = append(tokens, queryToken{value: value, quotedValue: true})
+				i = end + 1
+				start = -1
+				break
+			}
+			i++
+		}
+		if start >= 0 {
+			tokens = append(tokens, queryToken{value: string(runes[start:i])})
+		}
+	}
+
+	return tokens
+}
+
+// splitQualifier splits "key:value" tokens; keys are ASCII letters only
+func splitQualifier(tok queryToken) (string, string, bool) {
+	idx := strings.Index(tok.value, ":")
+	if idx <= 0 {
+		return "", "", false
+	}
+	key := strings.ToLower(tok.value[:idx])
+	for _, r := range key {
+		if r < 'a' || r > 'z' {
+			return "", "", false
+		}
+	}
+	value := tok.value[idx+1:]
+	if !tok.quotedValue && strings.HasPrefix(value, ":") {
+		// "App::login" is a PHP symbol, not a qualifier
+		return "", "", false
+	}
+	return key, value, true
+}
+
+// indexRune returns the index of r at or after from, or len(runes) if missing
+func indexRune(runes []rune, r rune, from int) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return len(runes)
+}
@@ -0,0 +1,91 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		raw     string
+		text    string
+		phrases []string
+		filters Filters
+	}{
+		{
+			raw:     `lang:php path:src/Controller type:-test symbol:login "password reset" forgot`,
+			text:    "forgot",
+			phrases: []string{"password reset"},
+			filters: Filters{
+				Languages:    []string{"php"},
+				Paths:        []string{"src/Controller"},
+				ExcludeTypes: []string{"test"},
+				Symbols:      []string{"login"},
+				Phrases:      []string{"password reset"},
+			},
+		},
+		{
+			raw:     "in:src exclude:vendor language:Go,PHP, sym:User File:-var/cache",
+			filters: Filters{Paths: []string{"src"}, Exclude: []string{"vendor", "var/cache"}, Languages: []string{"Go", "PHP"}, Symbols: []string{"User"}},
+		},
+		{
+			raw:     `path:"My Documents/notes" lang:-twig source:docs component:api`,
+			filters: Filters{Paths: []string{"My Documents/notes"}, ExcludeLanguages: []string{"twig"}, Sources: []string{"docs"}, Components: []string{"api"}},
+		},
+		{
+			raw:  "where is App::login called see http://localhost:8000 foo:bar",
+			text: "where is App::login called see http://localhost:8000 foo:bar",
+		},
+		{
+			raw:     `"unterminated phrase`,
+			phrases: []string{"unterminated phrase"},
+			filters: Filters{Phrases: []string{"unterminated phrase"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			q, err := ParseQuery(tt.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q.Raw != tt.raw || q.Text != tt.text || !reflect.DeepEqual(q.Phrases, tt.phrases) {
+				t.Errorf("text %q phrases %q, want %q %q", q.Text, q.Phrases, tt.text, tt.phrases)
+			}
+			if !reflect.DeepEqual(q.Filters, tt.filters) {
+				t.Errorf("filters = %+v, want %+v", q.Filters, tt.filters)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, raw := range []string{"lang:", "type:-", "component:-api", "source:-docs", "symbol:-User"} {
+		if _, err := ParseQuery(raw); err == nil {
+			t.Errorf("ParseQuery(%q) succeeded, want an error", raw)
+		}
+	}
+}
+
+func TestQueryTexts(t *testing.T) {
+	tests := []struct {
+		raw       string
+		embedding string
+		lexical   string
+	}{
+		{`reset "password token" lang:php`, "reset password token", `reset "password token"`},
+		{"symbol:login symbol:logout", "login logout", "login logout"},
+		{"type:test", "", ""},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := q.EmbeddingText(); got != tt.embedding {
+			t.Errorf("EmbeddingText(%q) = %q, want %q", tt.raw, got, tt.embedding)
+		}
+		if got := q.LexicalText(); got != tt.lexical {
+			t.Errorf("LexicalText(%q) = %q, want %q", tt.raw, got, tt.lexical)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reference lookup defaults
+const (
+	DefaultReferenceLimit   = 50
+	DefaultReferenceContext = 2 // lines shown on each side of a reference
+)
+
+// ReferenceOptions configures a reference lookup
+type ReferenceOptions struct {
+	Limit       int  // number of references (DefaultReferenceLimit when 0)
+	Context     int  // surrounding lines on each side, within the chunk (negative for none)
+	IgnoreCase  bool // match the identifier case-insensitively
+	Definitions bool // also return the lines declaring the identifier
+	Filters     Filters
+}
+
+// Reference is a line of the index that uses an identifier
+type Reference struct {
+	Path     string
+	Language string
+	Symbol   string // enclosing symbol (chunk), e.g. UserController::login
+	Kind     string // reference or definition
+	Line     int    // line in the file (0 if unknown)
+	Text     string // the referencing line, trimmed
+
+	Context      string // surrounding lines with line numbers, the reference marked with ">"
+	ContextStart int
+	ContextEnd   int
+}
+
+// ReferenceName returns the identifier looked up for a name: the member of a
+// qualified name (login for UserController::login), without a leading $
+func ReferenceName(name string) string {
+	name = NormalizeSymbol(name)
+	if i := strings.LastIndex(name, "::"); i >= 0 {
+		name = name[i+2:]
+	}
+	return strings.TrimPrefix(name, "$")
+}
+
+// FindReferences returns the lines that use an identifier, from the identifier index
+// built at index time, ordered by path and line. It also returns the total number of
+// matching lines, which can exceed the limit.
+func (e *Engine) FindReferences(name string, opts ReferenceOptions) ([]Reference, int, error) {
+	name = ReferenceName(name)
+	if name == "" {
+		return nil, 0, fmt.Errorf("symbol name is required")
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultReferenceLimit
+	}
+
+	match := "i.identifier = $2"
+	if opts.IgnoreCase {
+		match = "LOWER(i.identifier) = LOWER($2)"
+	}
+	where, filterArgs := opts.Filters.clause(5)
+	query := `
+		SELECT
+			path, COALESCE(language, ''), COALESCE(symbol, ''), i.kind, i.line,
+			COALESCE(start_line, 0), content, COUNT(*) OVER ()
+		FROM identifiers i
+		JOIN chunks c ON c.id = i.chunk_id
+		WHERE i.project_id = $1 AND ` + match + `
+		  AND (i.kind = 'reference' OR $4)` + where + `
+		ORDER BY path, COALESCE(start_line, 0), i.line
+		LIMIT $3
+	`
+
+	args := append([]interface{}{e.projectID, name, limit, opts.Definitions}, filterArgs...)
+	rows, err := e.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reference query failed (run 'oview up' and 'oview index' to build the identifier index): %w", err)
+	}
+	defer rows.Close()
+
+	var refs []Reference
+	total := 0
+	for rows.Next() {
+		var r Reference
+		var offset, startLine int
+		var content string
+		if err := rows.Scan(&r.Path, &r.Language, &r.Symbol, &r.Kind, &offset, &startLine, &content, &total); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan reference: %w", err)
+		}
+		lines := strings.Split(strings.TrimSpace(content), "\n")
+		if offset >= len(lines) {
+			continue // chunk changed shape since it was indexed
+		}
+		if startLine > 0 {
+			r.Line = startLine + offset
+		}
+		r.Text = strings.TrimSpace(lines[offset])
+		r.Context, r.ContextStart, r.ContextEnd = referenceContext(lines, offset, startLine, opts.Context)
+		refs = append(refs, r)
+	}
+	return refs, total, rows.Err()
+}
+
+// referenceContext formats the lines around a reference, numbered like expanded
+// excerpts. Lines are numbered from 1 within the chunk when its position is unknown.
+func referenceContext(lines []string, offset, startLine, context int) (string, int, int) {
+	if context < 0 {
+		return "", 0, 0
+	}
+	if startLine == 0 {
+		startLine = 1
+	}
+	from, to := offset-context, offset+context
+	if from < 0 {
+		from = 0
+	}
+	if to > len(lines)-1 {
+		to = len(lines) - 1
+	}
+
+	var b strings.Builder
+	for i := from; i <= to; i++ {
+		marker := "|"
+		if i == offset {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%5d %s %s\n", startLine+i, marker, lines[i])
+	}
+	return strings.TrimRight(b.String(), "\n"), startLine + from, startLine + to
+}
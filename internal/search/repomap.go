@@ -0,0 +1,378 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Repository map defaults
+const (
+	DefaultRepoMapTokens  = 2000
+	DefaultRepoMapSymbols = 8 // key symbols shown per file
+
+	// Identifiers defined in more files than this (index, __construct, render...)
+	// say nothing about which file a reference points to and are not linked
+	maxDefinitionFiles = 3
+
+	pageRankDamping    = 0.85
+	pageRankIterations = 30
+)
+
+// RepoMap is a token-bounded overview of the repository: the most central files
+// with their key symbols and signatures
+type RepoMap struct {
+	Text        string // rendered map
+	Files       []MapFile
+	TotalFiles  int // files in scope, including those left out for the budget
+	MaxTokens   int
+	UsedTokens  int
+	GeneratedAt time.Time
+}
+
+// MapFile is a file of the repository map
+type MapFile struct {
+	Path      string
+	Language  string
+	Rank      float64 // PageRank over the import and reference graph
+	UsedBy    int     // files depending on it
+	DependsOn int     // files it depends on
+	Symbols   []MapSymbol
+	More      int // symbols left out
+}
+
+// MapSymbol is a key symbol of a file
+type MapSymbol struct {
+	Name      string
+	Signature string
+	Line      int
+}
+
+// RepoMapOptions configures a repository map
+type RepoMapOptions struct {
+	MaxTokens  int     // DefaultRepoMapTokens when 0
+	MaxSymbols int     // DefaultRepoMapSymbols when 0
+	Filters    Filters // files in scope; documentation is left out unless types are given
+}
+
+// RepoMap ranks the indexed files by PageRank over the dependency graph (imports,
+// includes, service references) and the reference graph (a file using an identifier
+// defined in another file), then lists the most central ones with their symbols
+// until the token budget is spent
+func (e *Engine) RepoMap(opts RepoMapOptions) (*RepoMap, error) {
+	if opts.MaxTokens <= 0 {
+		opts.MaxTokens = DefaultRepoMapTokens
+	}
+	if opts.MaxSymbols <= 0 {
+		opts.MaxSymbols = DefaultRepoMapSymbols
+	}
+	if len(opts.Filters.Types) == 0 {
+		opts.Filters.ExcludeTypes = append(opts.Filters.ExcludeTypes, "doc")
+	}
+
+	files, err := e.mapFiles(opts.Filters)
+	if err != nil {
+		return nil, err
+	}
+	links, err := e.fileLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	// Rank over every file that takes part in the graph, then keep the files in scope
+	nodes := make(map[string]bool)
+	for path := range files {
+		nodes[path] = true
+	}
+	for from, targets := range links {
+		nodes[from] = true
+		for to := range targets {
+			nodes[to] = true
+		}
+	}
+	ranks := pageRank(nodes, links)
+
+	usedBy := make(map[string]int)
+	for _, targets := range links {
+		for to := range targets {
+			usedBy[to]++
+		}
+	}
+
+	ordered := make([]*MapFile, 0, len(files))
+	for _, f := range files {
+		f.Rank = ranks[f.Path]
+		f.UsedBy = usedBy[f.Path]
+		f.DependsOn = len(links[f.Path])
+		ordered = append(ordered, f)
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if a.Rank != b.Rank {
+			return a.Rank > b.Rank
+		}
+		if a.UsedBy != b.UsedBy {
+			return a.UsedBy > b.UsedBy
+		}
+		return a.Path < b.Path
+	})
+
+	m := &RepoMap{TotalFiles: len(ordered), MaxTokens: opts.MaxTokens, GeneratedAt: time.Now()}
+	var b strings.Builder
+	header := fmt.Sprintf("# Repository map (%d files, most central first)\n\n", len(ordered))
+	b.WriteString(header)
+	used := EstimateTokens(header)
+
+	// Room for the footer listing the files left out, at its longest
+	budget := opts.MaxTokens - EstimateTokens(mapFooter(len(ordered)))
+
+	for _, f := range ordered {
+		symbols, err := e.mapSymbols(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		if len(symbols) > opts.MaxSymbols {
+			f.More = len(symbols) - opts.MaxSymbols
+			symbols = symbols[:opts.MaxSymbols]
+		}
+		f.Symbols = symbols
+
+		section := mapSection(f)
+		tokens := EstimateTokens(section)
+		if used+tokens > budget {
+			// Fall back to the path alone before giving up on the file
+			f.Symbols, f.More = nil, f.More+len(symbols)
+			section = mapSection(f)
+			tokens = EstimateTokens(section)
+			if used+tokens > budget {
+				break
+			}
+		}
+		b.WriteString(section)
+		used += tokens
+		m.Files = append(m.Files, *f)
+	}
+
+	if left := len(ordered) - len(m.Files); left > 0 {
+		b.WriteString(mapFooter(left))
+	}
+	m.Text = strings.TrimRight(b.String(), "\n") + "\n"
+	m.UsedTokens = EstimateTokens(m.Text)
+	return m, nil
+}
+
+// mapFooter tells how many files were left out of the map
+func mapFooter(left int) string {
+	return fmt.Sprintf("... %d more files (raise the token budget to see them)\n", left)
+}
+
+// mapSection renders a file of the map: its path with its connectivity, then one
+// line per key symbol with its signature
+func mapSection(f *MapFile) string {
+	var b strings.Builder
+	b.WriteString(f.Path)
+	if f.UsedBy > 0 || f.DependsOn > 0 {
+		fmt.Fprintf(&b, "  (used by %d, uses %d)", f.UsedBy, f.DependsOn)
+	}
+	b.WriteString("\n")
+	for _, s := range f.Symbols {
+		// The signature usually names the symbol already: "public function login(...)"
+		line := "  " + s.Name
+		if s.Signature != "" && strings.Contains(s.Signature, lastSegment(s.Name)) {
+			line = "  " + s.Signature
+		} else if s.Signature != "" {
+			line += "  " + s.Signature
+		}
+		if s.Line > 0 {
+			line += fmt.Sprintf("  :%d", s.Line)
+		}
+		b.WriteString(line + "\n")
+	}
+	if f.More > 0 && len(f.Symbols) > 0 {
+		fmt.Fprintf(&b, "  ... %d more\n", f.More)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// lastSegment returns the member part of a qualified symbol (login for User::login)
+func lastSegment(symbol string) string {
+	if i := strings.LastIndex(symbol, "::"); i >= 0 {
+		return symbol[i+2:]
+	}
+	return symbol
+}
+
+// mapFiles lists the indexed files in scope
+func (e *Engine) mapFiles(filters Filters) (map[string]*MapFile, error) {
+	where, filterArgs := filters.clause(2)
+	rows, err := e.db.Query(`
+		SELECT path, COALESCE(MAX(language), '')
+		FROM chunks
+		WHERE project_id = $1`+where+`
+		GROUP BY path
+	`, append([]interface{}{e.projectID}, filterArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	defer rows.Close()
+
+	files := make(map[string]*MapFile)
+	for rows.Next() {
+		f := &MapFile{}
+		if err := rows.Scan(&f.Path, &f.Language); err != nil {
+			return nil, fmt.Errorf("failed to scan file: %w", err)
+		}
+		files[f.Path] = f
+	}
+	return files, rows.Err()
+}
+
+// fileLinks builds the weighted file graph: dependency edges count 1 each,
+// references to identifiers defined in another file count the square root of
+// the number of referencing lines
+func (e *Engine) fileLinks() (map[string]map[string]float64, error) {
+	links := make(map[string]map[string]float64)
+	add := func(from, to string, weight float64) {
+		if from == to {
+			return
+		}
+		if links[from] == nil {
+			links[from] = make(map[string]float64)
+		}
+		links[from][to] += weight
+	}
+
+	rows, err := e.db.Query(`
+		SELECT from_path, to_path, COUNT(*)
+		FROM edges
+		WHERE project_id = $1 AND to_path IS NOT NULL
+		GROUP BY from_path, to_path
+	`, e.projectID)
+	if err != nil {
+		return nil, fmt.Errorf("dependency query failed (run 'oview up' and 'oview index' to build the dependency graph): %w", err)
+	}
+	for rows.Next() {
+		var from, to string
+		var count int
+		if err := rows.Scan(&from, &to, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan edge: %w", err)
+		}
+		add(from, to, float64(count))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = e.db.Query(`
+		WITH defined AS (
+			SELECT DISTINCT i.identifier, c.path
+			FROM identifiers i JOIN chunks c ON c.id = i.chunk_id
+			WHERE i.project_id = $1 AND i.kind = 'definition'
+		), definitions AS (
+			SELECT identifier, path FROM defined
+			WHERE identifier IN (SELECT identifier FROM defined GROUP BY identifier HAVING COUNT(*) <= $2)
+		)
+		SELECT c.path, d.path, COUNT(*)
+		FROM identifiers i
+		JOIN chunks c ON c.id = i.chunk_id
+		JOIN definitions d ON d.identifier = i.identifier
+		WHERE i.project_id = $1 AND i.kind = 'reference' AND c.path <> d.path
+		GROUP BY c.path, d.path
+	`, e.projectID, maxDefinitionFiles)
+	if err != nil {
+		return nil, fmt.Errorf("reference query failed (run 'oview up' and 'oview index' to build the identifier index): %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var from, to string
+		var count int
+		if err := rows.Scan(&from, &to, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan reference: %w", err)
+		}
+		add(from, to, math.Sqrt(float64(count)))
+	}
+	return links, rows.Err()
+}
+
+// mapSymbols lists the symbols of a file in line order, split pieces merged,
+// with the first line of their definition
+func (e *Engine) mapSymbols(path string) ([]MapSymbol, error) {
+	rows, err := e.db.Query(`
+		SELECT name, COALESCE(MIN(NULLIF(start_line, 0)), 0), (array_agg(content ORDER BY start_line, id))[1]
+		FROM (
+			SELECT id, content, COALESCE(start_line, 0) AS start_line,
+				regexp_replace(symbol, '#[0-9]+$', '') AS name
+			FROM chunks
+			WHERE project_id = $1 AND path = $2
+			  AND COALESCE(symbol, '') <> '' AND symbol !~ '^chunk-[0-9]+$' AND symbol !~ '(^|::)imports$'
+		) c
+		GROUP BY name
+		ORDER BY MIN(start_line), name
+	`, e.projectID, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load symbols: %w", err)
+	}
+	defer rows.Close()
+
+	var symbols []MapSymbol
+	for rows.Next() {
+		var s MapSymbol
+		var content string
+		if err := rows.Scan(&s.Name, &s.Line, &content); err != nil {
+			return nil, fmt.Errorf("failed to scan symbol: %w", err)
+		}
+		s.Signature = firstLine(content)
+		if runes := []rune(s.Signature); len(runes) > 120 {
+			s.Signature = string(runes[:117]) + "..."
+		}
+		symbols = append(symbols, s)
+	}
+	return symbols, rows.Err()
+}
+
+// pageRank computes the weighted PageRank of a graph. Rank of files without
+// outgoing links is spread over every file.
+func pageRank(nodes map[string]bool, links map[string]map[string]float64) map[string]float64 {
+	n := float64(len(nodes))
+	ranks := make(map[string]float64, len(nodes))
+	if n == 0 {
+		return ranks
+	}
+	for node := range nodes {
+		ranks[node] = 1 / n
+	}
+
+	totals := make(map[string]float64, len(links))
+	for from, targets := range links {
+		for _, w := range targets {
+			totals[from] += w
+		}
+	}
+
+	for iter := 0; iter < pageRankIterations; iter++ {
+		dangling := 0.0
+		for node := range nodes {
+			if totals[node] == 0 {
+				dangling += ranks[node]
+			}
+		}
+
+		next := make(map[string]float64, len(nodes))
+		base := (1-pageRankDamping)/n + pageRankDamping*dangling/n
+		for node := range nodes {
+			next[node] = base
+		}
+		for from, targets := range links {
+			for to, w := range targets {
+				next[to] += pageRankDamping * ranks[from] * w / totals[from]
+			}
+		}
+		ranks = next
+	}
+	return ranks
+}
@@ -0,0 +1,408 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/yourusername/oview/internal/config"
+)
+
+// Re-rank providers
+const (
+	RerankNone             = "none"
+	RerankLexical          = "lexical"
+	RerankLLM              = "llm"
+	RerankOpenAICompatible = "openai-compatible"
+)
+
+// DefaultRerankTopN is the number of hits re-scored when the configuration does not say
+const DefaultRerankTopN = 20
+
+// rerankContentChars is how much of each chunk is shown to an LLM re-ranker
+const rerankContentChars = 1000
+
+// Reranker re-scores search hits against the query
+type Reranker interface {
+	// Name returns the provider and model used
+	Name() string
+
+	// Score returns one relevance score per result, higher is better
+	Score(query string, results []Result) ([]float64, error)
+}
+
+// NewReranker creates the re-ranker for a provider: the built-in lexical scorer,
+// the project's LLM (claude-api, openai or ollama) or an OpenAI-compatible endpoint.
+// It returns nil when re-ranking is off.
+func NewReranker(provider string, cfg config.RerankConfig, llm config.LLMConfig) (Reranker, error) {
+	client := &http.Client{Timeout: 60 * time.Second}
+
+	switch provider {
+	case "", RerankNone:
+		return nil, nil
+
+	case RerankLexical:
+		return lexicalReranker{}, nil
+
+	case RerankLLM:
+		switch llm.Provider {
+		case "claude-api":
+			apiKey := llm.APIKey
+			if apiKey == "" {
+				apiKey = os.Getenv("ANTHROPIC_API_KEY")
+			}
+			if apiKey == "" {
+				return nil, fmt.Errorf("Anthropic API key required for re-ranking. Set llm.api_key or ANTHROPIC_API_KEY")
+			}
+			baseURL := llm.BaseURL
+			if baseURL == "" {
+				baseURL = "https://api.anthropic.com"
+			}
+			return &claudeReranker{baseURL: baseURL, apiKey: apiKey, model: llm.Model, client: client}, nil
+
+		case "openai":
+			apiKey := llm.APIKey
+			if apiKey == "" {
+				apiKey = os.Getenv("OPENAI_API_KEY")
+			}
+			if apiKey == "" {
+				return nil, fmt.Errorf("OpenAI API key required for re-ranking. Set llm.api_key or OPENAI_API_KEY")
+			}
+			baseURL := llm.BaseURL
+			if baseURL == "" {
+				baseURL = "https://api.openai.com/v1"
+			}
+			return &chatReranker{baseURL: baseURL, apiKey: apiKey, model: llm.Model, client: client}, nil
+
+		case "ollama":
+			baseURL := llm.BaseURL
+			if baseURL == "" {
+				baseURL = "http://localhost:11434"
+			}
+			return &chatReranker{baseURL: strings.TrimRight(baseURL, "/") + "/v1", model: llm.Model, client: client}, nil
+
+		default:
+			return nil, fmt.Errorf("llm provider %q cannot re-rank (use claude-api, openai or ollama, or rerank provider lexical)", llm.Provider)
+		}
+
+	case RerankOpenAICompatible:
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("search.rerank.base_url is required for the openai-compatible re-ranker")
+		}
+		apiKey := cfg.APIKey
+		if apiKey == "" {
+			apiKey = os.Getenv("RERANK_API_KEY")
+		}
+		return &chatReranker{baseURL: cfg.BaseURL, apiKey: apiKey, model: cfg.Model, client: client}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown rerank provider: %s (use none, lexical, llm or openai-compatible)", provider)
+	}
+}
+
+// rerank re-scores the results with the re-ranker and orders them by the new score
+func rerank(r Reranker, query string, results []Result) ([]Result, error) {
+	scores, err := r.Score(query, results)
+	if err != nil {
+		return results, err
+	}
+	if len(scores) != len(results) {
+		return results, fmt.Errorf("re-ranker %s returned %d scores for %d results", r.Name(), len(scores), len(results))
+	}
+
+	reranked := append([]Result{}, results...)
+	for i := range reranked {
+		reranked[i].RerankScore = scores[i]
+		reranked[i].Score = scores[i]
+	}
+	sort.SliceStable(reranked, func(i, j int) bool {
+		return reranked[i].RerankScore > reranked[j].RerankScore
+	})
+	return reranked, nil
+}
+
+// lexicalReranker scores hits with BM25 over the candidate set, splitting
+// identifiers into words, with a bonus when query terms appear in the symbol
+type lexicalReranker struct{}
+
+// Name returns the name of the scorer
+func (lexicalReranker) Name() string {
+	return RerankLexical
+}
+
+// Score computes a BM25 score per result
+func (lexicalReranker) Score(query string, results []Result) ([]float64, error) {
+	const k1, b, symbolBonus = 1.2, 0.75, 0.5
+
+	terms := uniqueTerms(tokenize(query))
+	docs := make([][]string, len(results))
+	docFreq := make(map[string]int)
+	totalLen := 0
+	for i, r := range results {
+		docs[i] = tokenize(r.Symbol + " " + r.Path + " " + r.Content)
+		totalLen += len(docs[i])
+		for _, t := range uniqueTerms(docs[i]) {
+			docFreq[t]++
+		}
+	}
+
+	scores := make([]float64, len(results))
+	if len(terms) == 0 || totalLen == 0 {
+		return scores, nil
+	}
+	avgLen := float64(totalLen) / float64(len(results))
+	n := float64(len(results))
+
+	for i, doc := range docs {
+		freq := make(map[string]int)
+		for _, t := range doc {
+			freq[t]++
+		}
+		symbol := make(map[string]bool)
+		for _, t := range tokenize(results[i].Symbol) {
+			symbol[t] = true
+		}
+
+		for _, t := range terms {
+			tf := float64(freq[t])
+			if tf == 0 {
+				continue
+			}
+			df := float64(docFreq[t])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			scores[i] += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*float64(len(doc))/avgLen))
+			if symbol[t] {
+				scores[i] += symbolBonus * idf
+			}
+		}
+	}
+	return scores, nil
+}
+
+// tokenize lowercases text and splits it into words, breaking identifiers on
+// camelCase, snake_case and digits ("getUserById" -> get, user, by, id)
+func tokenize(text string) []string {
+	var tokens []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 1 {
+			tokens = append(tokens, strings.ToLower(string(current)))
+		}
+		current = current[:0]
+	}
+
+	runes := []rune(text)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if len(current) > 0 {
+			prev := runes[i-1]
+			switch {
+			case unicode.IsLower(prev) && unicode.IsUpper(r),
+				unicode.IsDigit(prev) != unicode.IsDigit(r),
+				unicode.IsUpper(prev) && unicode.IsUpper(r) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+
+	return tokens
+}
+
+// uniqueTerms returns the distinct tokens in order of first appearance
+func uniqueTerms(tokens []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, t := range tokens {
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
+
+// rerankPrompt asks an LLM to grade each hit from 0 to 10
+func rerankPrompt(query string, results []Result) string {
+	var b strings.Builder
+	b.WriteString("You are ranking code search results. Rate how well each snippet answers the query ")
+	b.WriteString("on a scale from 0 (irrelevant) to 10 (exactly what is asked for).\n\n")
+	fmt.Fprintf(&b, "Query: %s\n\n", query)
+
+	for i, r := range results {
+		content := r.Content
+		if len(content) > rerankContentChars {
+			content = content[:rerankContentChars] + "..."
+		}
+		fmt.Fprintf(&b, "[%d] %s", i, r.Path)
+		if r.Symbol != "" {
+			fmt.Fprintf(&b, " (%s)", r.Symbol)
+		}
+		fmt.Fprintf(&b, "\n%s\n\n", content)
+	}
+
+	fmt.Fprintf(&b, "Answer with a JSON array of exactly %d numbers, one per snippet in order, and nothing else.", len(results))
+	return b.String()
+}
+
+// parseScores extracts the JSON array of scores from an LLM answer
+func parseScores(answer string, count int) ([]float64, error) {
+	start := strings.Index(answer, "[")
+	end := strings.LastIndex(answer, "]")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("re-ranker answer has no score array: %q", truncateAnswer(answer))
+	}
+
+	var scores []float64
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &scores); err != nil {
+		return nil, fmt.Errorf("failed to parse re-ranker scores: %w", err)
+	}
+	if len(scores) != count {
+		return nil, fmt.Errorf("re-ranker returned %d scores for %d results", len(scores), count)
+	}
+	return scores, nil
+}
+
+// truncateAnswer shortens an LLM answer for error messages
+func truncateAnswer(s string) string {
+	if len(s) > 200 {
+		return s[:200] + "..."
+	}
+	return s
+}
+
+// postJSON sends a JSON request and decodes the JSON response
+func postJSON(client *http.Client, url string, headers map[string]string, body interface{}, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("re-rank request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("re-rank API error (status %d): %s", resp.StatusCode, string(msg))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode re-rank response: %w", err)
+	}
+	return nil
+}
+
+// claudeReranker grades hits with the Anthropic Messages API
+type claudeReranker struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// Name returns the provider and model used
+func (c *claudeReranker) Name() string {
+	return "claude-api/" + c.model
+}
+
+// Score asks the model for one score per result
+func (c *claudeReranker) Score(query string, results []Result) ([]float64, error) {
+	body := map[string]interface{}{
+		"model":      c.model,
+		"max_tokens": 16 + 8*len(results),
+		"messages": []map[string]string{
+			{"role": "user", "content": rerankPrompt(query, results)},
+		},
+	}
+	headers := map[string]string{
+		"x-api-key":         c.apiKey,
+		"anthropic-version": "2023-06-01",
+	}
+
+	var resp struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := postJSON(c.client, strings.TrimRight(c.baseURL, "/")+"/v1/messages", headers, body, &resp); err != nil {
+		return nil, err
+	}
+
+	var answer strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			answer.WriteString(block.Text)
+		}
+	}
+	return parseScores(answer.String(), len(results))
+}
+
+// chatReranker grades hits with an OpenAI-compatible chat completions endpoint
+// (OpenAI, Ollama, vLLM, LM Studio...)
+type chatReranker struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// Name returns the endpoint and model used
+func (c *chatReranker) Name() string {
+	return c.baseURL + "/" + c.model
+}
+
+// Score asks the model for one score per result
+func (c *chatReranker) Score(query string, results []Result) ([]float64, error) {
+	body := map[string]interface{}{
+		"model":       c.model,
+		"temperature": 0,
+		"messages": []map[string]string{
+			{"role": "user", "content": rerankPrompt(query, results)},
+		},
+	}
+	headers := map[string]string{}
+	if c.apiKey != "" {
+		headers["Authorization"] = "Bearer " + c.apiKey
+	}
+
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := postJSON(c.client, strings.TrimRight(c.baseURL, "/")+"/chat/completions", headers, body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("re-ranker returned no answer")
+	}
+	return parseScores(resp.Choices[0].Message.Content, len(results))
+}
@@ -0,0 +1,392 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Match kinds of a route lookup, most exact first
+const (
+	RouteMatchName       = "name"       // route name
+	RouteMatchPath       = "path"       // route path as declared, e.g. /api/users/{id}
+	RouteMatchURL        = "url"        // a URL the route path matches, e.g. /api/users/42
+	RouteMatchController = "controller" // controller class or Class::action
+	RouteMatchPartial    = "partial"    // name, path or controller contains the query
+)
+
+// routeMatchKinds orders the match kinds
+var routeMatchKinds = []string{RouteMatchName, RouteMatchPath, RouteMatchURL, RouteMatchController, RouteMatchPartial}
+
+// DefaultRouteLimit is the number of routes returned by a lookup when none is given
+const DefaultRouteLimit = 10
+
+// httpMethods are the methods accepted in front of a URL: "GET /api/users/42"
+var httpMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "OPTIONS": true,
+}
+
+// Route is a Symfony route from the route table
+type Route struct {
+	Name           string
+	Path           string
+	Methods        []string // empty for any method
+	Controller     string   // App\Controller\UserController::show
+	ControllerPath string   // file of the controller, empty when unresolved
+	File           string   // file declaring the route
+	Line           int
+	Source         string // attribute, annotation, yaml or xml
+
+	Match   string            // how the route matched the query, see RouteMatch*
+	Params  map[string]string // placeholder values when a URL matched
+	Handler *RouteHandler     // controller action chunk, nil when not indexed
+}
+
+// RouteHandler is the indexed code of the controller action handling a route.
+// Pieces of an action split at index time are joined.
+type RouteHandler struct {
+	Symbol    string
+	Path      string
+	StartLine int
+	EndLine   int
+	Signature string // first line of the action
+	Content   string
+}
+
+// RouteOptions configures a route lookup
+type RouteOptions struct {
+	Method string // only routes accepting this method
+	Limit  int    // DefaultRouteLimit when 0
+}
+
+// Routes lists the routes of the project ordered by path, optionally only those
+// accepting method
+func (e *Engine) Routes(method string) ([]Route, error) {
+	rows, err := e.db.Query(`
+		SELECT name, path, methods, controller, COALESCE(controller_path, ''), file, COALESCE(line, 0), source
+		FROM routes
+		WHERE project_id = $1
+		ORDER BY path, name
+	`, e.projectID)
+	if err != nil {
+		return nil, fmt.Errorf("route query failed (run 'oview up' and 'oview index' on a Symfony project to extract routes): %w", err)
+	}
+	defer rows.Close()
+
+	method = strings.ToUpper(method)
+	var routes []Route
+	for rows.Next() {
+		var r Route
+		var methods string
+		if err := rows.Scan(&r.Name, &r.Path, &methods, &r.Controller, &r.ControllerPath, &r.File, &r.Line, &r.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan route: %w", err)
+		}
+		if methods != "" {
+			r.Methods = strings.Split(methods, "|")
+		}
+		if r.Accepts(method) {
+			routes = append(routes, r)
+		}
+	}
+	return routes, rows.Err()
+}
+
+// Accepts reports whether the route accepts an HTTP method (any route accepts "")
+func (r Route) Accepts(method string) bool {
+	if method == "" || len(r.Methods) == 0 {
+		return true
+	}
+	for _, m := range r.Methods {
+		if m == method || (method == "HEAD" && m == "GET") {
+			return true
+		}
+	}
+	return false
+}
+
+// FindRoute looks up routes by name, declared path, URL (/api/users/42, a full URL
+// or "GET /api/users/42"), controller or part of them, most exact first, and loads
+// the controller action handling each one
+func (e *Engine) FindRoute(query string, opts RouteOptions) ([]Route, error) {
+	method, target := ParseRouteQuery(query)
+	if target == "" {
+		return nil, fmt.Errorf("route name or URL is required")
+	}
+	if opts.Method != "" {
+		method = strings.ToUpper(opts.Method)
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultRouteLimit
+	}
+
+	routes, err := e.Routes(method)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Route
+	for _, r := range routes {
+		if r.Match, r.Params = matchRoute(r, target); r.Match != "" {
+			matches = append(matches, r)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Match != b.Match {
+			return routeMatchRank(a.Match) < routeMatchRank(b.Match)
+		}
+		// Among URL matches, routes with more literal text are more specific
+		return literalLength(a.Path) > literalLength(b.Path)
+	})
+	if len(matches) > opts.Limit {
+		matches = matches[:opts.Limit]
+	}
+
+	for i := range matches {
+		if matches[i].Handler, err = e.routeHandler(matches[i]); err != nil {
+			return nil, err
+		}
+	}
+	return matches, nil
+}
+
+// ParseRouteQuery splits "GET /api/users/42?x=1" into its method and path, and
+// reduces full URLs to their path. Other queries (route names) are returned as is.
+func ParseRouteQuery(query string) (method, target string) {
+	target = strings.TrimSpace(query)
+	if word, rest, ok := strings.Cut(target, " "); ok && httpMethods[strings.ToUpper(word)] {
+		method, target = strings.ToUpper(word), strings.TrimSpace(rest)
+	}
+	if i := strings.Index(target, "://"); i >= 0 {
+		target = target[i+3:]
+		if j := strings.Index(target, "/"); j >= 0 {
+			target = target[j:]
+		} else {
+			target = "/"
+		}
+	}
+	if strings.HasPrefix(target, "/") && !strings.Contains(target, "{") {
+		if i := strings.IndexAny(target, "?#"); i >= 0 {
+			target = target[:i]
+		}
+	}
+	return method, target
+}
+
+// matchRoute returns how a route matches a query, with the placeholder values
+// when the query is a URL matching the route path
+func matchRoute(r Route, target string) (string, map[string]string) {
+	switch {
+	case r.Name == target:
+		return RouteMatchName, nil
+	case trimSlash(r.Path) == trimSlash(target):
+		return RouteMatchPath, nil
+	}
+	if strings.HasPrefix(target, "/") {
+		if params, ok := matchURL(r.Path, target); ok {
+			return RouteMatchURL, params
+		}
+	}
+
+	controller := NormalizeSymbol(r.Controller)
+	name := NormalizeSymbol(target)
+	class, _, _ := strings.Cut(controller, "::")
+	if strings.EqualFold(controller, name) || strings.EqualFold(class, name) {
+		return RouteMatchController, nil
+	}
+
+	lower := strings.ToLower(target)
+	for _, s := range []string{r.Name, r.Path, r.Controller} {
+		if strings.Contains(strings.ToLower(s), lower) {
+			return RouteMatchPartial, nil
+		}
+	}
+	return "", nil
+}
+
+// routeMatchRank is the position of a match kind, most exact first
+func routeMatchRank(match string) int {
+	for i, m := range routeMatchKinds {
+		if m == match {
+			return i
+		}
+	}
+	return len(routeMatchKinds)
+}
+
+// trimSlash drops the trailing slash of a path other than /
+func trimSlash(path string) string {
+	if len(path) > 1 {
+		return strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// matchURL matches a URL against a route path and returns the placeholder values.
+// Placeholders follow Symfony: {id}, {id<\d+>} with an inline requirement,
+// {page?} or {page?1} optional, {page<\d+>?1} both.
+func matchURL(routePath, url string) (map[string]string, bool) {
+	pattern := routeRegexp(trimSlash(routePath))
+	if pattern == nil {
+		return nil, false
+	}
+	m := pattern.FindStringSubmatch(trimSlash(url))
+	if m == nil {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, name := range pattern.SubexpNames() {
+		if name != "" && m[i] != "" {
+			params[name] = m[i]
+		}
+	}
+	return params, true
+}
+
+// routeRegexp compiles a route path to a regular expression, nil if it cannot be compiled
+func routeRegexp(path string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for path != "" {
+		open := strings.Index(path, "{")
+		end := placeholderEnd(path, open)
+		if open < 0 || end < 0 {
+			b.WriteString(regexp.QuoteMeta(path))
+			break
+		}
+
+		literal := path[:open]
+		name, requirement, optional := parsePlaceholder(path[open+1 : end])
+		if requirement == "" {
+			requirement = `[^/]+`
+		}
+		group := "(?P<" + name + ">" + requirement + ")"
+		switch {
+		case optional && strings.HasSuffix(literal, "/"):
+			b.WriteString(regexp.QuoteMeta(strings.TrimSuffix(literal, "/")) + "(?:/" + group + ")?")
+		case optional:
+			b.WriteString(regexp.QuoteMeta(literal) + group + "?")
+		default:
+			b.WriteString(regexp.QuoteMeta(literal) + group)
+		}
+		path = path[end+1:]
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// placeholderEnd returns the index of the brace closing the placeholder opened at
+// open, skipping braces of an inline requirement ({year<\d{4}>})
+func placeholderEnd(path string, open int) int {
+	if open < 0 {
+		return -1
+	}
+	inRequirement := false
+	for i := open + 1; i < len(path); i++ {
+		switch path[i] {
+		case '<':
+			inRequirement = true
+		case '>':
+			inRequirement = false
+		case '}':
+			if !inRequirement {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parsePlaceholder splits "page<\d+>?1" into its name, requirement and optional flag
+func parsePlaceholder(s string) (name, requirement string, optional bool) {
+	name = s
+	if i := strings.IndexAny(s, "<?"); i >= 0 {
+		name, s = s[:i], s[i:]
+		if strings.HasPrefix(s, "<") {
+			if j := strings.LastIndex(s, ">"); j > 0 {
+				requirement, s = s[1:j], s[j+1:]
+			}
+		}
+		optional = strings.HasPrefix(s, "?")
+	}
+	// Group names must be identifiers
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return -1
+	}, name)
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "p" + name
+	}
+	return name, requirement, optional
+}
+
+// literalLength is the length of a route path without its placeholders
+func literalLength(path string) int {
+	n := 0
+	for path != "" {
+		open := strings.Index(path, "{")
+		end := placeholderEnd(path, open)
+		if open < 0 || end < 0 {
+			return n + len(path)
+		}
+		n += open
+		path = path[end+1:]
+	}
+	return n
+}
+
+// routeHandler loads the chunk of the controller action handling a route: the
+// Class::action chunk (its pieces joined), or the class chunk for small controllers
+// kept whole
+func (e *Engine) routeHandler(r Route) (*RouteHandler, error) {
+	class, action, _ := strings.Cut(r.Controller, "::")
+	if i := strings.LastIndex(class, `\`); i >= 0 {
+		class = class[i+1:]
+	}
+	if class == "" {
+		return nil, nil
+	}
+	member := class + "::" + action
+
+	rows, err := e.db.Query(`
+		SELECT regexp_replace(symbol, '#[0-9]+$', ''), path, COALESCE(start_line, 0), COALESCE(end_line, 0), content
+		FROM chunks
+		WHERE project_id = $1 AND ($2 = '' OR path = $2)
+		  AND (symbol = $3 OR symbol LIKE $4 ESCAPE '\' OR symbol = $5)
+		ORDER BY symbol = $5, path, start_line, id
+	`, e.projectID, r.ControllerPath, member, escapeLike(member)+"#%", class)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load controller: %w", err)
+	}
+	defer rows.Close()
+
+	var handler *RouteHandler
+	var parts []string
+	for rows.Next() {
+		var h RouteHandler
+		if err := rows.Scan(&h.Symbol, &h.Path, &h.StartLine, &h.EndLine, &h.Content); err != nil {
+			return nil, fmt.Errorf("failed to scan controller: %w", err)
+		}
+		if handler == nil {
+			handler = &h
+		} else if h.Symbol != handler.Symbol || h.Path != handler.Path {
+			break
+		} else if h.EndLine > handler.EndLine {
+			handler.EndLine = h.EndLine
+		}
+		parts = append(parts, h.Content)
+	}
+	if handler != nil {
+		handler.Content = strings.Join(parts, "\n")
+		handler.Signature = firstLine(handler.Content)
+	}
+	return handler, rows.Err()
+}
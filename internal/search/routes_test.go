@@ -0,0 +1,92 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRouteQuery(t *testing.T) {
+	tests := []struct {
+		query, method, target string
+	}{
+		{"GET /api/users/42", "GET", "/api/users/42"},
+		{"delete /api/users/42", "DELETE", "/api/users/42"},
+		{"/api/users/42?expand=1#top", "", "/api/users/42"},
+		{"POST https://shop.example.com/api/orders?x=1", "POST", "/api/orders"},
+		{"https://shop.example.com", "", "/"},
+		{"/blog/{slug}", "", "/blog/{slug}"},
+		{"app_user_show", "", "app_user_show"},
+		{"  UserController::show  ", "", "UserController::show"},
+		{"FETCH /api", "", "FETCH /api"},
+	}
+	for _, tt := range tests {
+		method, target := ParseRouteQuery(tt.query)
+		if method != tt.method || target != tt.target {
+			t.Errorf("ParseRouteQuery(%q) = %q, %q, want %q, %q", tt.query, method, target, tt.method, tt.target)
+		}
+	}
+}
+
+func TestRouteRegexp(t *testing.T) {
+	tests := []struct {
+		path    string
+		pattern string
+	}{
+		{"/api/users", `^/api/users$`},
+		{"/api/users/{id}", `^/api/users/(?P<id>[^/]+)$`},
+		{`/api/users/{id<\d+>}`, `^/api/users/(?P<id>\d+)$`},
+		{"/blog/{page?}", `^/blog(?:/(?P<page>[^/]+))?$`},
+		{`/blog/{page<\d+>?1}`, `^/blog(?:/(?P<page>\d+))?$`},
+		{`/archive/{year<\d{4}>}`, `^/archive/(?P<year>\d{4})$`},
+		{"/files/v{version?}", `^/files/v(?P<version>[^/]+)?$`},
+		{"/{_locale}/about", `^/(?P<_locale>[^/]+)/about$`},
+		{"/unclosed/{id", `^/unclosed/\{id$`},
+	}
+	for _, tt := range tests {
+		re := routeRegexp(tt.path)
+		if re == nil {
+			t.Errorf("routeRegexp(%q) = nil, want %s", tt.path, tt.pattern)
+			continue
+		}
+		if re.String() != tt.pattern {
+			t.Errorf("routeRegexp(%q) = %s, want %s", tt.path, re, tt.pattern)
+		}
+	}
+
+	if re := routeRegexp(`/bad/{id<(>}`); re != nil {
+		t.Errorf("routeRegexp with an invalid requirement = %s, want nil", re)
+	}
+}
+
+func TestMatchURL(t *testing.T) {
+	tests := []struct {
+		route  string
+		url    string
+		ok     bool
+		params map[string]string
+	}{
+		{"/api/users", "/api/users", true, map[string]string{}},
+		{"/api/users", "/api/users/", true, map[string]string{}},
+		{"/api/users/{id}", "/api/users/42", true, map[string]string{"id": "42"}},
+		{"/api/users/{id}", "/api/users", false, nil},
+		{"/api/users/{id}", "/api/users/42/edit", false, nil},
+		{`/api/users/{id<\d+>}`, "/api/users/abc", false, nil},
+		{`/api/users/{id<\d+>}`, "/api/users/7", true, map[string]string{"id": "7"}},
+		{"/blog/{page?1}", "/blog", true, map[string]string{}},
+		{"/blog/{page?1}", "/blog/3", true, map[string]string{"page": "3"}},
+		{`/blog/{page<\d+>?}`, "/blog/latest", false, nil},
+		{"/{_locale}/posts/{slug}", "/fr/posts/hello-world", true, map[string]string{"_locale": "fr", "slug": "hello-world"}},
+		{`/archive/{year<\d{4}>}`, "/archive/2024", true, map[string]string{"year": "2024"}},
+		{`/archive/{year<\d{4}>}`, "/archive/24", false, nil},
+	}
+	for _, tt := range tests {
+		params, ok := matchURL(tt.route, tt.url)
+		if ok != tt.ok {
+			t.Errorf("matchURL(%q, %q) ok = %v, want %v", tt.route, tt.url, ok, tt.ok)
+			continue
+		}
+		if ok && !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("matchURL(%q, %q) = %v, want %v", tt.route, tt.url, params, tt.params)
+		}
+	}
+}
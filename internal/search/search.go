@@ -0,0 +1,201 @@
+package search
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Search modes
+const (
+	ModeVector  = "vector"
+	ModeLexical = "lexical"
+	ModeHybrid  = "hybrid"
+)
+
+// Result represents a search result
+type Result struct {
+	ID           int
+	Path         string
+	Type         string
+	Language     string
+	Symbol       string
+	Content      string
+	Similarity   float64 // cosine similarity (vector ranking)
+	LexicalScore float64 // full-text + trigram score (lexical ranking)
+	RerankScore  float64 // score given by the re-rank stage, if any
+	Score        float64 // final score used for ordering
+	StartLine    int     // line range of the chunk in its file (0 if unknown)
+	EndLine      int
+	Project      string // project slug, set by cross-project searches
+
+	// Set when neighbour expansion is requested
+	Excerpt      string // contiguous excerpt around the hit, with line numbers
+	ExcerptStart int
+	ExcerptEnd   int
+
+	// Set when an explanation is requested
+	Explain *Explanation
+}
+
+// ValidMode reports whether mode is a supported search mode
+func ValidMode(mode string) bool {
+	return mode == ModeVector || mode == ModeLexical || mode == ModeHybrid
+}
+
+// Bounds of hnsw.ef_search for filtered vector searches (pgvector accepts 1 to 1000)
+const (
+	minFilteredEFSearch = 100
+	maxFilteredEFSearch = 1000
+)
+
+// vectorSearch ranks chunks by pgvector cosine distance to the query embedding
+func vectorSearch(db *sql.DB, projectID string, queryEmbedding []float32, limit int, filters Filters) ([]Result, error) {
+	where, filterArgs := filters.clause(4)
+	query := `
+		SELECT
+			id, path, type, COALESCE(language, ''), COALESCE(symbol, ''), content,
+			COALESCE(start_line, 0), COALESCE(end_line, 0),
+			1 - (embedding <=> $1::vector) as similarity
+		FROM chunks
+		WHERE project_id = $2` + where + `
+		ORDER BY embedding <=> $1::vector
+		LIMIT $3
+	`
+
+	args := append([]interface{}{embeddingToString(queryEmbedding), projectID, limit}, filterArgs...)
+
+	// The HNSW index yields ef_search candidates before the filters apply, so a
+	// selective filter would leave fewer than limit hits: widen the scan
+	var q interface {
+		Query(query string, args ...interface{}) (*sql.Rows, error)
+	} = db
+	if where != "" {
+		tx, err := db.Begin()
+		if err != nil {
+			return nil, fmt.Errorf("failed to start transaction: %w", err)
+		}
+		defer tx.Rollback()
+		ef := min(max(limit*10, minFilteredEFSearch), maxFilteredEFSearch)
+		if _, err := tx.Exec(fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", ef)); err != nil {
+			return nil, fmt.Errorf("failed to set hnsw.ef_search: %w", err)
+		}
+		q = tx
+	}
+
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var results []Result
+	for rows.Next() {
+		var r Result
+		err := rows.Scan(&r.ID, &r.Path, &r.Type, &r.Language, &r.Symbol, &r.Content, &r.StartLine, &r.EndLine, &r.Similarity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		r.Score = r.Similarity
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
+
+// lexicalSearch ranks chunks by Postgres full-text rank, trigram similarity of the symbol
+// and exact substring matches, so identifiers and error codes rank first
+func lexicalSearch(db *sql.DB, projectID string, queryText string, limit int, filters Filters) ([]Result, error) {
+	where, filterArgs := filters.clause(5)
+	query := `
+		SELECT
+			id, path, type, COALESCE(language, ''), COALESCE(symbol, ''), content,
+			COALESCE(start_line, 0), COALESCE(end_line, 0),
+			ts_rank_cd(content_tsv, q)
+				+ similarity(COALESCE(symbol, ''), $2)
+				+ CASE WHEN content ILIKE $3 ESCAPE '\' THEN 1 ELSE 0 END AS score
+		FROM chunks, websearch_to_tsquery('simple', $2) q
+		WHERE project_id = $1
+		  AND (content_tsv @@ q OR symbol % $2 OR content ILIKE $3 ESCAPE '\')` + where + `
+		ORDER BY score DESC
+		LIMIT $4
+	`
+
+	args := append([]interface{}{projectID, queryText, "%" + escapeLike(queryText) + "%", limit}, filterArgs...)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("lexical query failed (run 'oview up' to add the full-text indexes): %w", err)
+	}
+	defer rows.Close()
+
+	var results []Result
+	for rows.Next() {
+		var r Result
+		err := rows.Scan(&r.ID, &r.Path, &r.Type, &r.Language, &r.Symbol, &r.Content, &r.StartLine, &r.EndLine, &r.LexicalScore)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		r.Score = r.LexicalScore
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
+
+// fuseRRF merges two rankings with weighted reciprocal rank fusion:
+// score(d) = Σ weight / (k + rank(d)), ranks starting at 1
+func fuseRRF(vector, lexical []Result, vectorWeight, lexicalWeight float64, k int, limit int) []Result {
+	fused := make(map[int]*Result)
+	var order []int
+
+	add := func(results []Result, weight float64, isVector bool) {
+		for rank, r := range results {
+			existing, ok := fused[r.ID]
+			if !ok {
+				copied := r
+				copied.Score = 0
+				existing = &copied
+				fused[r.ID] = existing
+				order = append(order, r.ID)
+			}
+			if isVector {
+				existing.Similarity = r.Similarity
+			} else {
+				existing.LexicalScore = r.LexicalScore
+			}
+			existing.Score += weight / float64(k+rank+1)
+		}
+	}
+
+	add(vector, vectorWeight, true)
+	add(lexical, lexicalWeight, false)
+
+	results := make([]Result, 0, len(order))
+	for _, id := range order {
+		results = append(results, *fused[id])
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// embeddingToString converts a float32 slice to PostgreSQL vector string format
+func embeddingToString(embedding []float32) string {
+	parts := make([]string, len(embedding))
+	for i, v := range embedding {
+		parts[i] = fmt.Sprintf("%f", v)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// escapeLike escapes LIKE wildcards so the query is matched literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
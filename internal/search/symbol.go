@@ -0,0 +1,131 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Match kinds of a symbol lookup, most exact first
+const (
+	MatchExact     = "exact"
+	MatchExactFold = "exact-ignore-case"
+	MatchMember    = "member"    // last segment of a qualified symbol, e.g. login in UserController::login
+	MatchPrefix    = "prefix"    // symbol or one of its segments starts with the name
+	MatchSubstring = "substring" // symbol contains the name
+	MatchFuzzy     = "fuzzy"     // trigram similarity only
+)
+
+// matchKinds maps the rank computed in SQL to its match kind
+var matchKinds = []string{MatchExact, MatchExactFold, MatchMember, MatchPrefix, MatchSubstring, MatchFuzzy}
+
+// DefaultSymbolLimit is the number of definitions returned when none is given
+const DefaultSymbolLimit = 10
+
+// Definition is where a symbol is defined. Pieces of a symbol split at index
+// time (X#1, X#2...) are merged into one definition covering all their lines.
+type Definition struct {
+	Symbol     string
+	Path       string
+	Type       string
+	Language   string
+	StartLine  int // 0 if unknown
+	EndLine    int
+	Parts      int     // chunks the definition spans
+	Match      string  // how the symbol matched the name, see Match*
+	Similarity float64 // trigram similarity of the symbol to the name
+	Signature  string  // first line of the definition that is not blank or a comment
+}
+
+// NormalizeSymbol turns the usual spellings of a qualified name into the
+// Class::member form used by the index: App\Controller\UserController::login,
+// UserController->login and UserController@login all become UserController::login
+func NormalizeSymbol(name string) string {
+	name = strings.TrimSpace(name)
+	name = strings.NewReplacer("->", "::", "@", "::").Replace(name)
+	name = strings.TrimSuffix(name, "()")
+	if i := strings.LastIndex(name, `\`); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// FindSymbol looks up definitions by name or partial name, ranked by exactness
+// (exact, case-insensitive, member, prefix, substring, then fuzzy) and trigram
+// similarity of the symbol column
+func (e *Engine) FindSymbol(name string, limit int, filters Filters) ([]Definition, error) {
+	name = NormalizeSymbol(name)
+	if name == "" {
+		return nil, fmt.Errorf("symbol name is required")
+	}
+	if limit <= 0 {
+		limit = DefaultSymbolLimit
+	}
+
+	lower := escapeLike(strings.ToLower(name))
+	where, filterArgs := filters.clause(8)
+	query := `
+		SELECT
+			path, name, type, language,
+			COALESCE(MIN(NULLIF(start_line, 0)), 0), MAX(end_line), COUNT(*),
+			(array_agg(content ORDER BY start_line, id))[1],
+			similarity(name, $2) AS sim,
+			CASE
+				WHEN name = $2 THEN 0
+				WHEN LOWER(name) = LOWER($2) THEN 1
+				WHEN LOWER(name) LIKE $5 ESCAPE '\' THEN 2
+				WHEN LOWER(name) LIKE $6 ESCAPE '\' OR LOWER(name) LIKE $7 ESCAPE '\' THEN 3
+				WHEN name ILIKE $3 ESCAPE '\' THEN 4
+				ELSE 5
+			END AS tier
+		FROM (
+			SELECT
+				id, path, type, COALESCE(language, '') AS language, content,
+				COALESCE(start_line, 0) AS start_line, COALESCE(end_line, 0) AS end_line,
+				regexp_replace(symbol, '#[0-9]+$', '') AS name
+			FROM chunks
+			WHERE project_id = $1
+			  AND COALESCE(symbol, '') <> '' AND symbol !~ '^chunk-[0-9]+$'
+			  AND (symbol ILIKE $3 ESCAPE '\' OR symbol % $2)` + where + `
+		) c
+		GROUP BY path, name, type, language
+		ORDER BY tier, sim DESC, LENGTH(name), path
+		LIMIT $4
+	`
+
+	args := append([]interface{}{
+		e.projectID, name, "%" + escapeLike(name) + "%", limit,
+		"%::" + lower, lower + "%", "%::" + lower + "%",
+	}, filterArgs...)
+	rows, err := e.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("symbol query failed (run 'oview up' to add the trigram indexes): %w", err)
+	}
+	defer rows.Close()
+
+	var defs []Definition
+	for rows.Next() {
+		var d Definition
+		var content string
+		var tier int
+		if err := rows.Scan(&d.Path, &d.Symbol, &d.Type, &d.Language, &d.StartLine, &d.EndLine, &d.Parts,
+			&content, &d.Similarity, &tier); err != nil {
+			return nil, fmt.Errorf("failed to scan definition: %w", err)
+		}
+		d.Match = matchKinds[tier]
+		d.Signature = firstLine(content)
+		defs = append(defs, d)
+	}
+	return defs, rows.Err()
+}
+
+// firstLine returns the first line of s that is not blank or a comment, trimmed
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "/*") || strings.HasPrefix(line, "*") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		return line
+	}
+	return ""
+}